@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	featureflag "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
@@ -16,7 +17,8 @@ func main() {
 
 	ff := featureflag.New(provider)
 
-	fmt.Println("=== Feature Flag Examples ===\n")
+	fmt.Println("=== Feature Flag Examples ===")
+	fmt.Println()
 
 	// Example 1: Simple flag check
 	example1_SimpleCheck(ff)
@@ -26,6 +28,9 @@ func main() {
 
 	// Example 3: Conditional execution
 	example3_ConditionalExecution(ff)
+
+	// Example 4: Targeting and percentage rollouts
+	example4_Targeting()
 }
 
 // Example 1: Simple flag check
@@ -77,6 +82,48 @@ func example3_ConditionalExecution(ff *featureflag.Manager) {
 	fmt.Println()
 }
 
+// Example 4: Targeting rules and percentage rollouts
+func example4_Targeting() {
+	fmt.Println("4. Targeting and Rollouts")
+
+	provider, err := featureflag.NewStaticProviderFromFlags(
+		featureflag.Flag{
+			Name:    "tenant-beta",
+			Enabled: true,
+			Rules: []featureflag.Rule{
+				{Attribute: "tenant", Operator: featureflag.OpIn, Values: []string{"acme"}},
+			},
+		},
+		featureflag.Flag{
+			Name:    "new-checkout",
+			Enabled: true,
+			Rollout: &featureflag.Rollout{Percentage: 25},
+		},
+	)
+	if err != nil {
+		fmt.Printf("   invalid flags: %v\n", err)
+		return
+	}
+	ff := featureflag.New(provider)
+	ctx := context.Background()
+
+	for _, tenant := range []string{"acme", "globex"} {
+		evalCtx := featureflag.EvalContext{Attributes: map[string]string{"tenant": tenant}}
+		fmt.Printf("   tenant-beta for %s: %v\n", tenant, ff.IsEnabledFor(ctx, "tenant-beta", evalCtx))
+	}
+
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		evalCtx := featureflag.EvalContext{UserID: fmt.Sprintf("user-%d", i)}
+		if ff.IsEnabledFor(ctx, "new-checkout", evalCtx) {
+			enabled++
+		}
+	}
+	fmt.Printf("   new-checkout (25%% rollout): %d of 1000 users\n", enabled)
+
+	fmt.Println()
+}
+
 // Processor interface for demonstration
 type Processor interface {
 	Process(data string) string
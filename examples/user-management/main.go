@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/JWindy92/obelisk-platform/libs/store"
+	"github.com/JWindy92/obelisk-platform/libs/store/migrate"
 	"github.com/JWindy92/obelisk-platform/libs/store/sqlite"
 	usermgmt "github.com/JWindy92/obelisk-platform/libs/user-management"
 )
@@ -19,9 +22,10 @@ func main() {
 	dbStore := initStore(ctx)
 	defer dbStore.Close()
 
-	// Step 2: Create the user repository
+	// Step 2: Create the users table and the user repository
 	// The repository accepts the store.Store interface
 	config := usermgmt.DefaultConfig()
+	migrateUsers(ctx, dbStore, config)
 	repo := usermgmt.NewRepository(dbStore, config)
 
 	// Step 3: Create auth provider and password hasher
@@ -46,6 +50,9 @@ func initStore(ctx context.Context) store.Store {
 		ConnMaxLifetime: int64(time.Hour),
 	}
 
+	// An in-memory SQLite database lives on a single connection.
+	storeConfig.MaxOpenConns = 1
+
 	st := sqlite.New(":memory:", storeConfig)
 	if err := st.Connect(ctx); err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
@@ -55,44 +62,66 @@ func initStore(ctx context.Context) store.Store {
 	return st
 }
 
+// migrateUsers creates the users table using the library's migration set.
+func migrateUsers(ctx context.Context, st store.Store, config usermgmt.Config) {
+	m := migrate.New(st, migrate.Config{})
+	if err := m.Register(usermgmt.Migrations(st.Dialect(), config)); err != nil {
+		log.Fatalf("Failed to register migrations: %v", err)
+	}
+	if err := m.Up(ctx); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+	fmt.Println("✓ Migrations applied")
+}
+
 // demonstrateUsage shows basic operations with the user service.
 func demonstrateUsage(ctx context.Context, svc usermgmt.Service) {
-	fmt.Println("\n=== User Management Example ===\n")
+	fmt.Println("\n=== User Management Example ===")
+	fmt.Println()
 
 	// Example: Signup
 	fmt.Println("1. Creating a new user...")
-	// user, err := svc.Signup(ctx, usermgmt.CreateUserRequest{
-	// 	Email:    "user@example.com",
-	// 	Password: "securepassword123",
-	// })
-	// if err != nil {
-	// 	log.Printf("Signup failed: %v", err)
-	// } else {
-	// 	fmt.Printf("✓ User created: %s\n", user.Email)
-	// }
+	user, err := svc.Signup(ctx, usermgmt.CreateUserRequest{
+		Email:    "user@example.com",
+		Password: "securepassword123",
+	})
+	if err != nil {
+		log.Fatalf("Signup failed: %v", err)
+	}
+	fmt.Printf("✓ User created: %s\n", user.Email)
+
+	// Example: Validation errors are reported per field
+	fmt.Println("\n2. Signing up with invalid input...")
+	_, err = svc.Signup(ctx, usermgmt.CreateUserRequest{
+		Email:    "not-an-email",
+		Password: "short",
+	})
+	var verr *usermgmt.ValidationError
+	if errors.As(err, &verr) {
+		for _, f := range verr.Fields {
+			fmt.Printf("✗ %s %s\n", f.Field, f.Message)
+		}
+	}
 
 	// Example: Login
-	fmt.Println("\n2. Logging in...")
-	// loginResp, err := svc.Login(ctx, usermgmt.LoginRequest{
-	// 	Email:    "user@example.com",
-	// 	Password: "securepassword123",
-	// })
-	// if err != nil {
-	// 	log.Printf("Login failed: %v", err)
-	// } else {
-	// 	fmt.Printf("✓ Login successful. Token: %s\n", loginResp.Token)
-	// }
+	fmt.Println("\n3. Logging in...")
+	loginResp, err := svc.Login(ctx, usermgmt.LoginRequest{
+		Email:    "user@example.com",
+		Password: "securepassword123",
+	})
+	if err != nil {
+		log.Fatalf("Login failed: %v", err)
+	}
+	fmt.Printf("✓ Login successful. Token: %s\n", loginResp.Token)
 
 	// Example: Get user
-	fmt.Println("\n3. Getting user by ID...")
-	// user, err = svc.GetUser(ctx, user.ID)
-	// if err != nil {
-	// 	log.Printf("Get user failed: %v", err)
-	// } else {
-	// 	fmt.Printf("✓ Found user: %s\n", user.Email)
-	// }
-
-	fmt.Println("\n(Methods not yet implemented - coming soon!)")
+	fmt.Println("\n4. Getting user by ID...")
+	user, err = svc.GetUser(ctx, user.ID)
+	if err != nil {
+		log.Fatalf("Get user failed: %v", err)
+	}
+	fmt.Printf("✓ Found user: %s\n", user.Email)
+
 	fmt.Println("\n=== Key Points ===")
 	fmt.Println("• Service accepts Repository, AuthProvider, and PasswordHasher interfaces")
 	fmt.Println("• Repository accepts store.Store interface")
@@ -109,7 +138,11 @@ func (m *mockAuthProvider) GenerateToken(ctx context.Context, user *usermgmt.Use
 }
 
 func (m *mockAuthProvider) ValidateToken(ctx context.Context, token string) (*usermgmt.User, error) {
-	return &usermgmt.User{ID: "123", Email: "user@example.com"}, nil
+	id, ok := strings.CutPrefix(token, "mock-token-")
+	if !ok {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return &usermgmt.User{ID: id}, nil
 }
 
 func (m *mockAuthProvider) RevokeToken(ctx context.Context, token string) error {
@@ -3,6 +3,17 @@ module github.com/JWindy92/obelisk-platform
 go 1.23.2
 
 require (
+	github.com/BurntSushi/toml v1.5.0
+	github.com/golang-jwt/jwt/v5 v5.2.2
 	github.com/lib/pq v1.10.9
 	github.com/mattn/go-sqlite3 v1.14.33
+	github.com/open-feature/go-sdk v1.15.1
+	golang.org/x/crypto v0.31.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/go-logr/logr v1.4.3 // indirect
+	go.uber.org/mock v0.5.2 // indirect
+	golang.org/x/sys v0.28.0 // indirect
 )
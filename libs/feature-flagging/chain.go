@@ -0,0 +1,118 @@
+package featureflag
+
+import (
+	"reflect"
+	"sync"
+)
+
+// ChainProvider layers providers: each flag is served by the first provider
+// that defines it, so earlier providers override later ones. A flag that an
+// override layer defines as off is off, even if a later layer enables it;
+// only flags a layer does not define fall through to the next.
+//
+//	flags := featureflag.NewChainProvider(envOverrides, dbFlags, fileDefaults)
+type ChainProvider struct {
+	providers []Provider
+
+	watchOnce sync.Once
+	changes   Broadcaster
+}
+
+var (
+	_ Provider  = (*ChainProvider)(nil)
+	_ Watchable = (*ChainProvider)(nil)
+	_ Lister    = (*ChainProvider)(nil)
+)
+
+// NewChainProvider creates a provider that consults providers in order.
+func NewChainProvider(providers ...Provider) *ChainProvider {
+	return &ChainProvider{providers: providers}
+}
+
+// IsEnabled checks if a feature flag is enabled in the first provider that
+// defines it. Returns false if no provider does (fail-safe default).
+func (c *ChainProvider) IsEnabled(flagName string) bool {
+	enabled, _ := c.Lookup(flagName)
+	return enabled
+}
+
+// Lookup reports the state of a feature flag in the first provider that
+// defines it, and whether any provider does.
+func (c *ChainProvider) Lookup(flagName string) (enabled, exists bool) {
+	for _, p := range c.providers {
+		if enabled, exists := p.Lookup(flagName); exists {
+			return enabled, true
+		}
+	}
+	return false, false
+}
+
+// Flag returns the definition from the first provider that defines the flag.
+func (c *ChainProvider) Flag(flagName string) (Flag, bool) {
+	return c.firstDefined(flagName, 0, len(c.providers))
+}
+
+// Flags returns every flag defined by a layer implementing Lister, as
+// served by the chain, sorted by name. Layers that cannot list their flags
+// are skipped.
+func (c *ChainProvider) Flags() []Flag {
+	names := make(map[string]bool)
+	for _, p := range c.providers {
+		if lister, ok := p.(Lister); ok {
+			for _, flag := range lister.Flags() {
+				names[flag.Name] = true
+			}
+		}
+	}
+
+	flags := make(map[string]Flag, len(names))
+	for name := range names {
+		flags[name], _ = c.Flag(name)
+	}
+	return sortedFlags(flags)
+}
+
+// Watch registers fn to be called when the flag served by the chain
+// changes. Changes in a layer that is shadowed by an earlier one are not
+// reported. Only layers implementing Watchable are observed.
+func (c *ChainProvider) Watch(fn func(FlagChange)) (cancel func()) {
+	c.watchOnce.Do(func() {
+		for i, p := range c.providers {
+			if w, ok := p.(Watchable); ok {
+				w.Watch(func(change FlagChange) { c.layerChanged(i, change) })
+			}
+		}
+	})
+	return c.changes.Watch(fn)
+}
+
+// layerChanged translates a change in layer i into a change of the flag
+// the chain serves, if any.
+func (c *ChainProvider) layerChanged(i int, change FlagChange) {
+	// Shadowed by an earlier layer: what the chain serves is unchanged.
+	if _, shadowed := c.firstDefined(change.Name, 0, i); shadowed {
+		return
+	}
+
+	// Before the change the chain served layer i's old definition, or the
+	// next layer's if layer i did not define the flag.
+	old := change.Old
+	if old.Name == "" {
+		old, _ = c.firstDefined(change.Name, i+1, len(c.providers))
+	}
+	current, _ := c.firstDefined(change.Name, i, len(c.providers))
+	if !reflect.DeepEqual(old, current) {
+		c.changes.Publish(FlagChange{Name: change.Name, Old: old, New: current})
+	}
+}
+
+// firstDefined returns the flag from the first of layers [from, to) that
+// defines it.
+func (c *ChainProvider) firstDefined(flagName string, from, to int) (Flag, bool) {
+	for _, p := range c.providers[from:to] {
+		if flag, exists := p.Flag(flagName); exists {
+			return flag, true
+		}
+	}
+	return Flag{}, false
+}
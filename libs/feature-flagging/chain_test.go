@@ -0,0 +1,86 @@
+package featureflag
+
+import (
+	"context"
+	"testing"
+)
+
+func TestChainProvider_Lookup(t *testing.T) {
+	overrides := NewStaticProvider(map[string]bool{
+		"forced-off": false,
+		"forced-on":  true,
+	})
+	defaults := NewStaticProvider(map[string]bool{
+		"forced-off":   true,
+		"forced-on":    false,
+		"default-only": true,
+	})
+	chain := NewChainProvider(overrides, defaults)
+
+	tests := []struct {
+		flag        string
+		wantEnabled bool
+		wantExists  bool
+	}{
+		{flag: "forced-off", wantEnabled: false, wantExists: true},
+		{flag: "forced-on", wantEnabled: true, wantExists: true},
+		{flag: "default-only", wantEnabled: true, wantExists: true},
+		{flag: "missing", wantEnabled: false, wantExists: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flag, func(t *testing.T) {
+			enabled, exists := chain.Lookup(tt.flag)
+			if enabled != tt.wantEnabled || exists != tt.wantExists {
+				t.Errorf("Lookup() = (%v, %v), want (%v, %v)", enabled, exists, tt.wantEnabled, tt.wantExists)
+			}
+			if chain.IsEnabled(tt.flag) != tt.wantEnabled {
+				t.Errorf("IsEnabled() = %v, want %v", !tt.wantEnabled, tt.wantEnabled)
+			}
+		})
+	}
+}
+
+func TestChainProvider_FlagDefinitions(t *testing.T) {
+	rule := Rule{Attribute: "tenant", Operator: OpEquals, Values: []string{"acme"}}
+	overrides := NewStaticProvider(nil)
+	defaults := newStaticProvider(t, Flag{Name: "beta", Enabled: true, Rules: []Rule{rule}})
+	m := New(NewChainProvider(overrides, defaults))
+	ctx := context.Background()
+	acme := EvalContext{Attributes: map[string]string{"tenant": "acme"}}
+
+	if !m.IsEnabledFor(ctx, "beta", acme) {
+		t.Error("IsEnabledFor() = false, want default layer's targeting to apply")
+	}
+
+	// An override replaces the whole definition, targeting included.
+	overrides.Set("beta", false)
+	if m.IsEnabledFor(ctx, "beta", acme) {
+		t.Error("IsEnabledFor() = true after override forced the flag off")
+	}
+}
+
+func TestChainProvider_Watch(t *testing.T) {
+	overrides := NewStaticProvider(nil)
+	defaults := NewStaticProvider(map[string]bool{"a": false})
+	chain := NewChainProvider(overrides, defaults)
+
+	var changes []FlagChange
+	chain.Watch(func(c FlagChange) { changes = append(changes, c) })
+
+	defaults.Set("a", true)   // served by defaults: reported
+	overrides.Set("a", true)  // override matches current: no effective change
+	defaults.Set("a", false)  // shadowed by override: not reported
+	overrides.Set("a", false) // override flips: reported
+	overrides.Replace(nil)    // override removed, falls through to defaults (off): not reported
+
+	if len(changes) != 2 {
+		t.Fatalf("Watch() saw %d changes, want 2: %+v", len(changes), changes)
+	}
+	if changes[0].Old.Enabled || !changes[0].New.Enabled {
+		t.Errorf("changes[0] = %+v, want off -> on", changes[0])
+	}
+	if !changes[1].Old.Enabled || changes[1].New.Enabled {
+		t.Errorf("changes[1] = %+v, want on -> off", changes[1])
+	}
+}
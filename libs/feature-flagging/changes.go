@@ -0,0 +1,162 @@
+package featureflag
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// FlagChange describes a change to one flag's definition. Old is the zero
+// Flag when the flag was added, and New is the zero Flag when it was
+// removed; check Name on each to tell.
+type FlagChange struct {
+	Name string
+	Old  Flag
+	New  Flag
+}
+
+// Watchable is implemented by providers that can report flag changes, such
+// as StaticProvider, FileProvider and refreshing providers.
+type Watchable interface {
+	// Watch registers fn to be called after each change and returns a
+	// function that unregisters it. fn is called synchronously from the
+	// goroutine that made the change, so it must not block.
+	Watch(fn func(FlagChange)) (cancel func())
+}
+
+// Broadcaster fans flag changes out to watchers. Providers embed it to
+// implement Watchable. The zero value is ready to use.
+type Broadcaster struct {
+	mu       sync.Mutex
+	nextID   int
+	watchers map[int]func(FlagChange)
+}
+
+// Watch registers fn to be called for every published change.
+func (b *Broadcaster) Watch(fn func(FlagChange)) (cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.watchers == nil {
+		b.watchers = make(map[int]func(FlagChange))
+	}
+	id := b.nextID
+	b.nextID++
+	b.watchers[id] = fn
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.watchers, id)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// Publish calls every watcher with each change, in order. Call it after
+// releasing any lock guarding the flags, so watchers can read them.
+func (b *Broadcaster) Publish(changes ...FlagChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	watchers := make([]func(FlagChange), 0, len(b.watchers))
+	for _, fn := range b.watchers {
+		watchers = append(watchers, fn)
+	}
+	b.mu.Unlock()
+
+	for _, change := range changes {
+		for _, fn := range watchers {
+			fn(change)
+		}
+	}
+}
+
+// Diff returns the changes between two flag sets keyed by name, sorted by
+// flag name. Flags whose definitions are equal are omitted.
+func Diff(old, new map[string]Flag) []FlagChange {
+	var changes []FlagChange
+	for name, oldFlag := range old {
+		newFlag, exists := new[name]
+		if !exists {
+			changes = append(changes, FlagChange{Name: name, Old: oldFlag})
+		} else if !reflect.DeepEqual(oldFlag, newFlag) {
+			changes = append(changes, FlagChange{Name: name, Old: oldFlag, New: newFlag})
+		}
+	}
+	for name, newFlag := range new {
+		if _, exists := old[name]; !exists {
+			changes = append(changes, FlagChange{Name: name, New: newFlag})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// Subscribe calls fn whenever the named flag's definition changes. It
+// returns a function that stops the subscription. If the provider does not
+// implement Watchable, fn is never called.
+//
+// fn runs on the goroutine that changed the flag, so it must not block; use
+// it to rebuild components chosen with Select, for example.
+func (m *Manager) Subscribe(flagName string, fn func(old, new Flag)) (unsubscribe func()) {
+	w, ok := m.provider.(Watchable)
+	if !ok {
+		return func() {}
+	}
+	return w.Watch(func(change FlagChange) {
+		if change.Name == flagName {
+			fn(change.Old, change.New)
+		}
+	})
+}
+
+// changesBuffer is the capacity of channels returned by Manager.Changes.
+const changesBuffer = 64
+
+// Changes returns a channel that receives every flag change until ctx is
+// cancelled, after which it is closed. The channel is buffered; changes
+// that arrive while the buffer is full are dropped rather than blocking the
+// provider, so consumers that must not miss a change should re-read the
+// flags they care about on every event. If the provider does not implement
+// Watchable, the channel only closes.
+func (m *Manager) Changes(ctx context.Context) <-chan FlagChange {
+	ch := make(chan FlagChange, changesBuffer)
+
+	w, ok := m.provider.(Watchable)
+	if !ok {
+		go func() {
+			<-ctx.Done()
+			close(ch)
+		}()
+		return ch
+	}
+
+	// mu guards ch against being closed while a change is being sent.
+	var mu sync.Mutex
+	closed := false
+	cancel := w.Watch(func(change FlagChange) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		select {
+		case ch <- change:
+		default:
+		}
+	})
+
+	go func() {
+		<-ctx.Done()
+		cancel()
+		mu.Lock()
+		closed = true
+		close(ch)
+		mu.Unlock()
+	}()
+	return ch
+}
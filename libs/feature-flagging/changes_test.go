@@ -0,0 +1,174 @@
+package featureflag
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	old := map[string]Flag{
+		"same":    {Name: "same", Enabled: true},
+		"toggled": {Name: "toggled", Enabled: false},
+		"removed": {Name: "removed", Enabled: true},
+	}
+	new := map[string]Flag{
+		"same":    {Name: "same", Enabled: true},
+		"toggled": {Name: "toggled", Enabled: true},
+		"added":   {Name: "added", Enabled: true},
+	}
+
+	changes := Diff(old, new)
+	if len(changes) != 3 {
+		t.Fatalf("Diff() returned %d changes, want 3: %+v", len(changes), changes)
+	}
+
+	tests := []struct {
+		name    string
+		oldName string
+		newName string
+	}{
+		{name: "added", oldName: "", newName: "added"},
+		{name: "removed", oldName: "removed", newName: ""},
+		{name: "toggled", oldName: "toggled", newName: "toggled"},
+	}
+	for i, tt := range tests {
+		c := changes[i]
+		if c.Name != tt.name || c.Old.Name != tt.oldName || c.New.Name != tt.newName {
+			t.Errorf("changes[%d] = %+v, want %s", i, c, tt.name)
+		}
+	}
+}
+
+func TestManager_Subscribe(t *testing.T) {
+	p := NewStaticProvider(map[string]bool{"v2": false, "other": false})
+	m := New(p)
+
+	var got []bool
+	unsubscribe := m.Subscribe("v2", func(old, new Flag) {
+		if old.Enabled == new.Enabled {
+			t.Errorf("Subscribe() called without a change: %+v -> %+v", old, new)
+		}
+		got = append(got, new.Enabled)
+	})
+
+	p.Set("v2", true)
+	p.Set("v2", true) // no change, no event
+	p.Set("other", true)
+	p.Replace(map[string]bool{"v2": false, "other": true})
+
+	if len(got) != 2 || !got[0] || got[1] {
+		t.Errorf("Subscribe() saw %v, want [true false]", got)
+	}
+
+	unsubscribe()
+	p.Set("v2", true)
+	if len(got) != 2 {
+		t.Error("Subscribe() callback ran after unsubscribe")
+	}
+}
+
+func TestManager_SubscribeAddAndRemove(t *testing.T) {
+	p := NewStaticProvider(nil)
+	m := New(p)
+
+	var changes []FlagChange
+	m.Subscribe("new", func(old, new Flag) {
+		changes = append(changes, FlagChange{Name: "new", Old: old, New: new})
+	})
+
+	p.SetFlag(Flag{Name: "new", Enabled: true})
+	p.Replace(nil)
+
+	if len(changes) != 2 {
+		t.Fatalf("Subscribe() saw %d changes, want 2", len(changes))
+	}
+	if changes[0].Old.Name != "" || changes[0].New.Name != "new" {
+		t.Errorf("add change = %+v", changes[0])
+	}
+	if changes[1].Old.Name != "new" || changes[1].New.Name != "" {
+		t.Errorf("remove change = %+v", changes[1])
+	}
+}
+
+func TestManager_Changes(t *testing.T) {
+	p := NewStaticProvider(map[string]bool{"a": false})
+	m := New(p)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := m.Changes(ctx)
+
+	p.Set("a", true)
+	p.SetFlag(Flag{Name: "b", Enabled: true})
+
+	for _, want := range []string{"a", "b"} {
+		select {
+		case c := <-ch:
+			if c.Name != want {
+				t.Errorf("Changes() got %s, want %s", c.Name, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Changes() did not deliver %s", want)
+		}
+	}
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("Changes() delivered a change after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Changes() channel not closed after cancel")
+	}
+
+	// Changes after the channel closed must not panic.
+	p.Set("a", false)
+}
+
+func TestManager_ChangesDropsWhenFull(t *testing.T) {
+	p := NewStaticProvider(nil)
+	m := New(p)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := m.Changes(ctx)
+	for i := 0; i < changesBuffer*2; i++ {
+		p.Set("a", i%2 == 0) // must not block
+	}
+	if len(ch) != changesBuffer {
+		t.Errorf("buffered %d changes, want %d", len(ch), changesBuffer)
+	}
+}
+
+func TestFileProvider_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.json")
+	base := time.Now()
+	writeFlagFile(t, path, `{"flags": {"a": {"enabled": false}}}`, base)
+
+	p, err := NewFileProvider(FileConfig{Path: path})
+	if err != nil {
+		t.Fatalf("NewFileProvider() unexpected error: %v", err)
+	}
+
+	var mu sync.Mutex
+	var changes []FlagChange
+	p.Watch(func(c FlagChange) {
+		mu.Lock()
+		changes = append(changes, c)
+		mu.Unlock()
+	})
+
+	writeFlagFile(t, path, `{"flags": {"a": {"enabled": true}}}`, base.Add(time.Second))
+	if err := p.Reload(); err != nil {
+		t.Fatalf("Reload() unexpected error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) != 1 || changes[0].Name != "a" || !changes[0].New.Enabled {
+		t.Errorf("Watch() saw %+v, want a enabled", changes)
+	}
+}
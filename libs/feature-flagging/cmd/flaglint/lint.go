@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+)
+
+// findingKind classifies a finding.
+type findingKind string
+
+const (
+	// kindUndefined is a flag referenced in code but not defined.
+	kindUndefined findingKind = "undefined"
+
+	// kindUnused is a flag defined but never referenced.
+	kindUnused findingKind = "unused"
+)
+
+// finding is one problem reported by the linter.
+type finding struct {
+	Kind findingKind
+	Flag string
+	Pos  token.Position
+
+	// Suggestion is the closest known flag name for an undefined flag, if
+	// one is near enough to be a likely typo.
+	Suggestion string
+}
+
+func (f finding) String() string {
+	switch f.Kind {
+	case kindUndefined:
+		if f.Suggestion != "" {
+			return fmt.Sprintf("%s: undefined flag %q (did you mean %q?)", f.Pos, f.Flag, f.Suggestion)
+		}
+		return fmt.Sprintf("%s: undefined flag %q", f.Pos, f.Flag)
+	default:
+		return fmt.Sprintf("%s: flag %q is defined but never referenced", f.Pos, f.Flag)
+	}
+}
+
+// lint cross-checks references against definitions. Flags that other
+// definitions depend on count as referenced. Findings are sorted by
+// position.
+func lint(refs []reference, defs []definition) []finding {
+	defined := make(map[string]bool, len(defs))
+	for _, def := range defs {
+		defined[def.Name] = true
+	}
+	names := make([]string, 0, len(defined))
+	for name := range defined {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	used := make(map[string]bool, len(refs))
+	var findings []finding
+	for _, ref := range refs {
+		used[ref.Name] = true
+		if defined[ref.Name] {
+			continue
+		}
+		findings = append(findings, finding{
+			Kind:       kindUndefined,
+			Flag:       ref.Name,
+			Pos:        ref.Pos,
+			Suggestion: suggest(ref.Name, names),
+		})
+	}
+	for _, def := range defs {
+		for _, dep := range def.DependsOn {
+			used[dep] = true
+		}
+	}
+
+	reported := make(map[string]bool)
+	for _, def := range defs {
+		if used[def.Name] || reported[def.Name] {
+			continue
+		}
+		reported[def.Name] = true
+		findings = append(findings, finding{
+			Kind: kindUnused,
+			Flag: def.Name,
+			Pos:  def.Pos,
+		})
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i].Pos, findings[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return findings
+}
+
+// suggest returns the candidate closest to name by edit distance, if it is
+// within a third of name's length (and at most 3 edits), or "".
+func suggest(name string, candidates []string) string {
+	limit := min(len(name)/3, 3)
+	best, bestDist := "", limit+1
+	for _, c := range candidates {
+		if d := levenshtein(name, c); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
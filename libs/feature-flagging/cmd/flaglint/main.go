@@ -0,0 +1,137 @@
+// Command flaglint finds feature flags that are referenced in Go code but
+// not defined, and flags that are defined but no longer referenced.
+//
+// It parses Go source and collects the flag names passed to Manager
+// methods such as IsEnabled, IsDisabled, Select and When, and to the
+// generic helpers, as string literals or string constants. They are checked
+// against the flags in definition files and in NewStaticProvider literals
+// found in the scanned code:
+//
+//	flaglint -defs flags.yaml ./...
+//
+// The code is type-checked, with dependencies read from the compiler's
+// export data, so that methods of unrelated types that share a
+// name with a Manager method are not mistaken for flag checks.
+//
+// Undefined flags that are a few edits away from a defined one come with a
+// suggestion, to catch typos. flaglint exits with status 1 if it reports
+// anything and 2 if it fails.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"strings"
+
+	featureflag "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// defsFlag collects repeated -defs values.
+type defsFlag []string
+
+func (d *defsFlag) String() string { return strings.Join(*d, ",") }
+
+func (d *defsFlag) Set(value string) error {
+	*d = append(*d, value)
+	return nil
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("flaglint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var defs defsFlag
+	fs.Var(&defs, "defs", "flag definition file (JSON, YAML or TOML); may be repeated")
+	includeTests := fs.Bool("tests", false, "also scan _test.go files")
+	noStatic := fs.Bool("no-static", false, "ignore NewStaticProvider definitions found in code")
+	unused := fs.Bool("unused", true, "report defined flags that are never referenced")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: flaglint [flags] [path ...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"./..."}
+	}
+
+	findings, err := check(paths, defs, *includeTests, !*noStatic, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "flaglint: %v\n", err)
+		return 2
+	}
+
+	reported := 0
+	for _, f := range findings {
+		if f.Kind == kindUnused && !*unused {
+			continue
+		}
+		fmt.Fprintln(stdout, f)
+		reported++
+	}
+	if reported > 0 {
+		return 1
+	}
+	return 0
+}
+
+// check scans paths and lints the references found against the flags in
+// defFiles and, if static is set, the StaticProvider literals in the code.
+// Warnings that don't stop the check are written to stderr.
+func check(paths, defFiles []string, includeTests, static bool, stderr io.Writer) ([]finding, error) {
+	s := newScanner(includeTests, stderr)
+	for _, path := range paths {
+		if err := s.addPath(path); err != nil {
+			return nil, err
+		}
+	}
+	result := s.scan()
+
+	var defs []definition
+	for _, path := range defFiles {
+		fileDefs, err := loadDefinitions(path)
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, fileDefs...)
+	}
+	if static {
+		defs = append(defs, result.Definitions...)
+	}
+	if len(defs) == 0 {
+		return nil, fmt.Errorf("no flag definitions found; pass -defs or scan code that builds a StaticProvider")
+	}
+
+	return lint(result.References, defs), nil
+}
+
+// loadDefinitions reads a flag definition file.
+func loadDefinitions(path string) ([]definition, error) {
+	format, err := featureflag.FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	flags, err := featureflag.ParseFlags(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	defs := make([]definition, 0, len(flags))
+	for _, flag := range flags {
+		defs = append(defs, definition{Name: flag.Name, Pos: token.Position{Filename: path}, DependsOn: flag.Dependencies()})
+	}
+	return defs, nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	app := filepath.Join("testdata", "app")
+	defs := filepath.Join("testdata", "flags.yaml")
+
+	tests := []struct {
+		name         string
+		defs         []string
+		includeTests bool
+		static       bool
+		want         []string
+	}{
+		{
+			name:   "static definitions only",
+			static: true,
+			want: []string{
+				`main.go:15:17: flag "legacy-export" is defined but never referenced`,
+				`main.go:26:9: undefined flag "new-chekout" (did you mean "new-checkout"?)`,
+				`main.go:27:27: undefined flag "beta-reports"`,
+				`main.go:28:27: undefined flag "checkout-algorithm"`,
+				`qualified.go:11:18: undefined flag "legacy-checkout"`,
+			},
+		},
+		{
+			name:   "definition file and static definitions",
+			defs:   []string{defs},
+			static: true,
+			want: []string{
+				`main.go:15:17: flag "legacy-export" is defined but never referenced`,
+				`main.go:26:9: undefined flag "new-chekout" (did you mean "new-checkout"?)`,
+				`qualified.go:11:18: undefined flag "legacy-checkout"`,
+				`flags.yaml: flag "old-banner" is defined but never referenced`,
+			},
+		},
+		{
+			name:         "including tests",
+			defs:         []string{defs},
+			includeTests: true,
+			want: []string{
+				`main.go:23:17: undefined flag "new-checkout"`,
+				`main.go:26:9: undefined flag "new-chekout"`,
+				`main_test.go:7:18: undefined flag "test-only"`,
+				`qualified.go:10:18: undefined flag "new-checkout"`,
+				`qualified.go:11:18: undefined flag "legacy-checkout"`,
+				`flags.yaml: flag "old-banner" is defined but never referenced`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			findings, err := check([]string{app + "/..."}, tt.defs, tt.includeTests, tt.static, &stderr)
+			if err != nil {
+				t.Fatalf("check() unexpected error: %v", err)
+			}
+			if stderr.Len() > 0 {
+				t.Errorf("check() warned: %s", &stderr)
+			}
+
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.String())
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("check() = %d findings, want %d:\n%s", len(got), len(tt.want), strings.Join(got, "\n"))
+			}
+			for i, want := range tt.want {
+				if !strings.HasSuffix(got[i], want) {
+					t.Errorf("finding %d = %s, want suffix %s", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-defs", filepath.Join("testdata", "flags.yaml"), "-unused=false", filepath.Join("testdata", "app")}, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("run() = %d, want 1 (stderr %s)", code, &stderr)
+	}
+	if strings.Contains(stdout.String(), "never referenced") {
+		t.Errorf("run() with -unused=false reported unused flags:\n%s", &stdout)
+	}
+
+	stdout.Reset()
+	if code := run([]string{"-defs", "missing.yaml", filepath.Join("testdata", "app")}, &stdout, &stderr); code != 2 {
+		t.Errorf("run() with missing definitions = %d, want 2", code)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	names := []string{"new-checkout", "beta-reports", "dark-mode"}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "new-chekout", want: "new-checkout"},
+		{name: "beta-report", want: "beta-reports"},
+		{name: "dark_mode", want: "dark-mode"},
+		{name: "checkout", want: ""},
+		{name: "ab", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggest(tt.name, names); got != tt.want {
+				t.Errorf("suggest(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck_WorkingDirectory(t *testing.T) {
+	app, err := filepath.Abs(filepath.Join("testdata", "app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defs, err := filepath.Abs(filepath.Join("testdata", "flags.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	// Imports resolve in the scanned module, not the working directory, so
+	// flag names from constants in other packages are still found.
+	var stderr bytes.Buffer
+	findings, err := check([]string{app}, []string{defs}, false, false, &stderr)
+	if err != nil {
+		t.Fatalf("check() unexpected error: %v", err)
+	}
+	if stderr.Len() > 0 {
+		t.Errorf("check() warned: %s", &stderr)
+	}
+	var qualified int
+	for _, f := range findings {
+		if strings.Contains(f.String(), "qualified.go") {
+			qualified++
+		}
+	}
+	if qualified != 2 {
+		t.Errorf("check() found %d references through qualified constants, want 2", qualified)
+	}
+}
+
+func TestCheck_GoListFailure(t *testing.T) {
+	dir := t.TempDir()
+	src := `package app
+
+import featureflag "` + featureflagPath + `"
+
+func handler(ff *featureflag.Manager) bool {
+	return ff.IsEnabled("new-checkout")
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "app.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defs := filepath.Join(dir, "flags.yaml")
+	if err := os.WriteFile(defs, []byte("flags:\n  new-checkout:\n    enabled: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Outside a module go list fails; the failure is reported and calls
+	// are still matched by name.
+	var stderr bytes.Buffer
+	findings, err := check([]string{dir}, []string{defs}, false, false, &stderr)
+	if err != nil {
+		t.Fatalf("check() unexpected error: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "go list") {
+		t.Errorf("check() stderr = %q, want the go list failure reported", &stderr)
+	}
+	if len(findings) != 0 {
+		t.Errorf("check() = %v, want the reference matched by name", findings)
+	}
+}
@@ -0,0 +1,535 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// featureflagPath is the import path of the feature flag package.
+const featureflagPath = "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+
+// call describes where a flag name appears among a call's arguments.
+type call struct {
+	args      int // number of arguments
+	nameIndex int // index of the flag name argument
+}
+
+// managerMethods are the featureflag.Manager methods that take a flag name.
+var managerMethods = map[string]call{
+	"IsEnabled":     {args: 1, nameIndex: 0},
+	"IsDisabled":    {args: 1, nameIndex: 0},
+	"Select":        {args: 3, nameIndex: 0},
+	"When":          {args: 3, nameIndex: 0},
+	"IsEnabledFor":  {args: 3, nameIndex: 1},
+	"IsEnabledCtx":  {args: 2, nameIndex: 1},
+	"IsDisabledCtx": {args: 2, nameIndex: 1},
+	"WhenCtx":       {args: 4, nameIndex: 1},
+	"Variant":       {args: 3, nameIndex: 1},
+	"Evaluate":      {args: 3, nameIndex: 1},
+	"EvaluateCtx":   {args: 2, nameIndex: 1},
+	"StringVariant": {args: 4, nameIndex: 1},
+	"IntVariant":    {args: 4, nameIndex: 1},
+	"FloatVariant":  {args: 4, nameIndex: 1},
+	"JSONVariant":   {args: 4, nameIndex: 1},
+
+	"VariantCtx":       {args: 2, nameIndex: 1},
+	"StringVariantCtx": {args: 3, nameIndex: 1},
+	"IntVariantCtx":    {args: 3, nameIndex: 1},
+	"FloatVariantCtx":  {args: 3, nameIndex: 1},
+	"JSONVariantCtx":   {args: 3, nameIndex: 1},
+}
+
+// packageFuncs are the featureflag package functions that take a flag name.
+var packageFuncs = map[string]call{
+	"Select":        {args: 4, nameIndex: 1},
+	"Switch":        {args: 4, nameIndex: 1},
+	"NewLazy":       {args: 4, nameIndex: 1},
+	"NewLazySwitch": {args: 4, nameIndex: 1},
+	"SelectCtx":     {args: 5, nameIndex: 2},
+	"SwitchCtx":     {args: 5, nameIndex: 2},
+}
+
+// reference is a flag name used in code.
+type reference struct {
+	Name string
+	Pos  token.Position
+}
+
+// definition is a flag defined in a definition file or StaticProvider
+// literal.
+type definition struct {
+	Name string
+
+	// Pos is where the flag is defined; only the filename is set for
+	// definition files.
+	Pos token.Position
+
+	// DependsOn lists the flag's prerequisites and kill switch, which
+	// count as references.
+	DependsOn []string
+}
+
+// scanResult holds what was found in Go source.
+type scanResult struct {
+	References  []reference
+	Definitions []definition
+}
+
+// scanner extracts flag references and StaticProvider definitions from Go
+// files. It matches calls by method name and argument count, and resolves
+// flag names given as string literals or as string constants.
+//
+// The scanned packages are type-checked so that only methods of the
+// featureflag package's types, or of interfaces a *featureflag.Manager
+// satisfies, count as references. Where types can't be resolved, e.g.
+// because a dependency is missing, it falls back to matching method calls
+// in files that import the featureflag package.
+type scanner struct {
+	stderr       io.Writer // receives warnings about degraded type checking
+	fset         *token.FileSet
+	includeTests bool
+	files        []*ast.File
+	packages     map[string][]*ast.File // directory and package name -> files
+
+	info    *types.Info
+	manager types.Type // *featureflag.Manager; nil if it couldn't be loaded
+}
+
+func newScanner(includeTests bool, stderr io.Writer) *scanner {
+	return &scanner{
+		stderr:       stderr,
+		fset:         token.NewFileSet(),
+		includeTests: includeTests,
+		packages:     make(map[string][]*ast.File),
+		info: &types.Info{
+			Selections: make(map[*ast.SelectorExpr]*types.Selection),
+			Uses:       make(map[*ast.Ident]types.Object),
+		},
+	}
+}
+
+// addPath parses the Go files under path. A trailing "/..." is accepted
+// for symmetry with the go tool; directories are always walked
+// recursively, skipping testdata, vendor and hidden directories.
+func (s *scanner) addPath(path string) error {
+	root := strings.TrimSuffix(path, "/...")
+	if root == "" {
+		root = "."
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", root, err)
+	}
+	if !info.IsDir() {
+		return s.addFile(root)
+	}
+
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != root && (name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") || (!s.includeTests && strings.HasSuffix(p, "_test.go")) {
+			return nil
+		}
+		return s.addFile(p)
+	})
+}
+
+func (s *scanner) addFile(path string) error {
+	file, err := parser.ParseFile(s.fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	s.files = append(s.files, file)
+	key := filepath.Dir(path) + ":" + file.Name.Name
+	s.packages[key] = append(s.packages[key], file)
+	return nil
+}
+
+// typeCheck type-checks the scanned packages, importing dependencies from
+// the compiler's export data. Errors are ignored: whatever could be resolved
+// is recorded in s.info, and calls without type information fall back to
+// name matching.
+func (s *scanner) typeCheck() {
+	keys := make([]string, 0, len(s.packages))
+	for key := range s.packages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	exports := s.exportFiles()
+	importers := make(map[string]types.Importer)
+	for _, key := range keys {
+		root := moduleRoot(s.packageDir(key))
+		imp, ok := importers[root]
+		if !ok {
+			files := exports[root]
+			imp = importer.ForCompiler(s.fset, "gc", func(path string) (io.ReadCloser, error) {
+				file, ok := files[path]
+				if !ok {
+					return nil, fmt.Errorf("no export data for %s", path)
+				}
+				return os.Open(file)
+			})
+			importers[root] = imp
+		}
+
+		conf := types.Config{Importer: imp, Error: func(error) {}}
+		pkg, _ := conf.Check(key, s.fset, s.packages[key], s.info)
+		if s.manager != nil || pkg == nil {
+			continue
+		}
+		for _, dep := range pkg.Imports() {
+			if dep.Path() != featureflagPath {
+				continue
+			}
+			if obj, ok := dep.Scope().Lookup("Manager").(*types.TypeName); ok {
+				s.manager = types.NewPointer(obj.Type())
+			}
+		}
+	}
+}
+
+// packageDir returns the directory of the package stored under key.
+func (s *scanner) packageDir(key string) string {
+	return filepath.Dir(s.fset.Position(s.packages[key][0].Package).Filename)
+}
+
+// exportFiles asks the go tool for the export data of every package the
+// scanned files import, building it if needed. Imports are resolved in the
+// module of the files importing them, so the result maps module root
+// directories to files by import path. Packages that fail to build are
+// left out, and a module where the go tool can't be run gets no export
+// data at all; that is reported on s.stderr.
+func (s *scanner) exportFiles() map[string]map[string]string {
+	imports := make(map[string]map[string]bool)
+	for key, files := range s.packages {
+		root := moduleRoot(s.packageDir(key))
+		if imports[root] == nil {
+			imports[root] = make(map[string]bool)
+		}
+		for _, file := range files {
+			for _, imp := range file.Imports {
+				path, err := strconv.Unquote(imp.Path.Value)
+				if err == nil && path != "C" && path != "unsafe" {
+					imports[root][path] = true
+				}
+			}
+		}
+	}
+
+	exports := make(map[string]map[string]string, len(imports))
+	for root, paths := range imports {
+		exports[root] = s.goListExports(root, paths)
+	}
+	return exports
+}
+
+// goListExports runs go list in dir for the export data of paths.
+// Packages go list can't load are reported on s.stderr.
+func (s *scanner) goListExports(dir string, paths map[string]bool) map[string]string {
+	exports := make(map[string]string, len(paths))
+	if len(paths) == 0 {
+		return exports
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+	args := append([]string{"list", "-e", "-export", "-json=ImportPath,Export,Error", "--"}, sorted...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		msg := err.Error()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			msg = strings.TrimSpace(string(exitErr.Stderr))
+		}
+		s.warnf("go list in %s failed: %s", dir, msg)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var pkg struct {
+			ImportPath string
+			Export     string
+			Error      *struct{ Err string }
+		}
+		if err := dec.Decode(&pkg); err != nil {
+			if err != io.EOF {
+				s.warnf("go list in %s: %v", dir, err)
+			}
+			break
+		}
+		switch {
+		case pkg.Error != nil:
+			s.warnf("go list in %s: %s", dir, pkg.Error.Err)
+		case pkg.Export != "":
+			exports[pkg.ImportPath] = pkg.Export
+		}
+	}
+	return exports
+}
+
+// warnf reports a problem that degrades type checking. Calls whose types
+// can't be resolved are still matched by name.
+func (s *scanner) warnf(format string, args ...any) {
+	fmt.Fprintf(s.stderr, "flaglint: "+format+"; matching flag calls by name\n", args...)
+}
+
+// moduleRoot returns the nearest directory at or above dir that holds a
+// go.mod file, or dir itself if there is none.
+func moduleRoot(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	for d := abs; ; {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return d
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return abs
+		}
+		d = parent
+	}
+}
+
+// isFlagMethod reports whether a call through sel can be a flag check.
+// With type information, the method must be declared in the featureflag
+// package or on an interface *featureflag.Manager implements. Without it,
+// the call only counts in files that import the package.
+func (s *scanner) isFlagMethod(sel *ast.SelectorExpr, importsFeatureflag bool) bool {
+	selection, ok := s.info.Selections[sel]
+	if !ok {
+		if x, isIdent := sel.X.(*ast.Ident); isIdent {
+			if _, isPkg := s.info.Uses[x].(*types.PkgName); isPkg {
+				return false // a function of some other package
+			}
+		}
+		return importsFeatureflag
+	}
+	if selection.Kind() != types.MethodVal {
+		return false
+	}
+
+	method := selection.Obj()
+	if method.Pkg() != nil && method.Pkg().Path() == featureflagPath {
+		return true
+	}
+	iface, ok := selection.Recv().Underlying().(*types.Interface)
+	if !ok {
+		return false
+	}
+	if s.manager == nil {
+		return importsFeatureflag
+	}
+	return types.Implements(s.manager, iface)
+}
+
+// scan type-checks and walks the parsed files. It runs after every file is
+// added so each package is checked as a whole.
+func (s *scanner) scan() scanResult {
+	s.typeCheck()
+
+	var result scanResult
+	for _, file := range s.files {
+		pkgName := featureflagImportName(file)
+		ast.Inspect(file, func(n ast.Node) bool {
+			callExpr, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := unindex(callExpr.Fun).(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+
+			switch sel.Sel.Name {
+			case "NewStaticProvider":
+				result.Definitions = append(result.Definitions, s.staticDefinitions(callExpr)...)
+				return true
+			case "NewStaticProviderFromFlags":
+				result.Definitions = append(result.Definitions, s.flagDefinitions(callExpr)...)
+				return true
+			}
+
+			var c call
+			if x, isIdent := sel.X.(*ast.Ident); isIdent && pkgName != "" && x.Name == pkgName {
+				c, ok = packageFuncs[sel.Sel.Name]
+			} else {
+				c, ok = managerMethods[sel.Sel.Name]
+				ok = ok && s.isFlagMethod(sel, pkgName != "")
+			}
+			if !ok || len(callExpr.Args) != c.args {
+				return true
+			}
+			if name, ok := s.resolve(callExpr.Args[c.nameIndex]); ok {
+				result.References = append(result.References, reference{
+					Name: name,
+					Pos:  s.fset.Position(callExpr.Args[c.nameIndex].Pos()),
+				})
+			}
+			return true
+		})
+	}
+	return result
+}
+
+// staticDefinitions reads the keys of a NewStaticProvider(map[string]bool{...}) literal.
+func (s *scanner) staticDefinitions(callExpr *ast.CallExpr) []definition {
+	if len(callExpr.Args) != 1 {
+		return nil
+	}
+	lit, ok := callExpr.Args[0].(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+
+	var defs []definition
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if name, ok := s.resolve(kv.Key); ok {
+			defs = append(defs, definition{Name: name, Pos: s.fset.Position(kv.Key.Pos())})
+		}
+	}
+	return defs
+}
+
+// flagDefinitions reads the Flag literals passed to NewStaticProviderFromFlags.
+func (s *scanner) flagDefinitions(callExpr *ast.CallExpr) []definition {
+	var defs []definition
+	for _, arg := range callExpr.Args {
+		lit, ok := arg.(*ast.CompositeLit)
+		if !ok {
+			continue
+		}
+
+		var def definition
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			key, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			switch key.Name {
+			case "Name":
+				if name, ok := s.resolve(kv.Value); ok {
+					def.Name = name
+					def.Pos = s.fset.Position(kv.Value.Pos())
+				}
+			case "KillSwitch":
+				if name, ok := s.resolve(kv.Value); ok {
+					def.DependsOn = append(def.DependsOn, name)
+				}
+			case "Prerequisites":
+				if list, ok := kv.Value.(*ast.CompositeLit); ok {
+					for _, e := range list.Elts {
+						if name, ok := s.resolve(e); ok {
+							def.DependsOn = append(def.DependsOn, name)
+						}
+					}
+				}
+			}
+		}
+		if def.Name != "" {
+			defs = append(defs, def)
+		}
+	}
+	return defs
+}
+
+// resolve returns the string value of a literal or of a string constant,
+// referenced directly or through a package selector. Constants are looked up
+// in the type information, so a qualified name resolves in the package its
+// selector names; constants that couldn't be type-checked are not resolved.
+func (s *scanner) resolve(expr ast.Expr) (string, bool) {
+	if value, ok := stringLit(expr); ok {
+		return value, true
+	}
+
+	var ident *ast.Ident
+	switch e := expr.(type) {
+	case *ast.Ident:
+		ident = e
+	case *ast.SelectorExpr:
+		ident = e.Sel
+	default:
+		return "", false
+	}
+	c, ok := s.info.Uses[ident].(*types.Const)
+	if !ok || c.Val().Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(c.Val()), true
+}
+
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+// unindex strips explicit type arguments, as in featureflag.Select[T](...).
+func unindex(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.IndexExpr:
+		return e.X
+	case *ast.IndexListExpr:
+		return e.X
+	}
+	return expr
+}
+
+// featureflagImportName returns the name the file imports the feature flag
+// package under, or "" if it doesn't import it.
+func featureflagImportName(file *ast.File) string {
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		if path != featureflagPath {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+		return "featureflag"
+	}
+	return ""
+}
@@ -0,0 +1,4 @@
+package checkout
+
+// Flag shares its name with legacy.Flag but not its value.
+const Flag = "new-checkout"
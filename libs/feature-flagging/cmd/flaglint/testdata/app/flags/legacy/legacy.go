@@ -0,0 +1,3 @@
+package legacy
+
+const Flag = "legacy-checkout"
@@ -0,0 +1,11 @@
+package internal
+
+type selector struct{}
+
+// Select has the same name as Manager.Select but a different shape, so it
+// is not mistaken for a flag check.
+func (selector) Select(query string) {}
+
+func use(s selector) {
+	s.Select("not-a-flag")
+}
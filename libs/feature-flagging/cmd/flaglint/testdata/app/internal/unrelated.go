@@ -0,0 +1,24 @@
+package internal
+
+import ff "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+
+// levels and db have methods with the same names and shapes as Manager's.
+// The file imports the feature flag package, so only type information
+// tells them apart from flag checks.
+type levels struct{}
+
+func (levels) IsEnabled(level string) bool { return level == "debug" }
+
+func (levels) When(event string, then, otherwise func()) {}
+
+type db struct{}
+
+func (db) Select(query string, args ...any) {}
+
+func logAndQuery(log levels, conn db, m *ff.Manager) {
+	if log.IsEnabled("debug") {
+		log.When("ready", func() {}, func() {})
+	}
+	conn.Select("SELECT name FROM flags WHERE id = ?", 1, 2)
+	_ = m
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+
+	ff "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+)
+
+const flagNewCheckout = "new-checkout"
+
+func main() {
+	provider, err := ff.NewStaticProviderFromFlags(
+		ff.Flag{Name: "payments-kill"},
+		ff.Flag{Name: flagNewCheckout, Enabled: true, KillSwitch: "payments-kill"},
+		ff.Flag{Name: "legacy-export"},
+	)
+	if err != nil {
+		panic(err)
+	}
+	m := ff.New(provider)
+	ctx := context.Background()
+
+	if m.IsEnabled(flagNewCheckout) {
+		return
+	}
+	m.When("new-chekout", func() {}, func() {})
+	_ = ff.Select[string](m, "beta-reports", func() string { return "" }, func() string { return "" })
+	_ = m.StringVariant(ctx, "checkout-algorithm", ff.EvalContext{}, "v1")
+
+	name := "dynamic"
+	_ = m.IsEnabled(name) // not a constant; ignored
+}
@@ -0,0 +1,8 @@
+package main
+
+import "testing"
+
+func TestFlags(t *testing.T) {
+	var m interface{ IsEnabled(string) bool }
+	_ = m.IsEnabled("test-only")
+}
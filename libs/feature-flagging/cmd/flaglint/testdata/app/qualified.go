@@ -0,0 +1,12 @@
+package main
+
+import (
+	ff "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+	"github.com/JWindy92/obelisk-platform/libs/feature-flagging/cmd/flaglint/testdata/app/flags/checkout"
+	"github.com/JWindy92/obelisk-platform/libs/feature-flagging/cmd/flaglint/testdata/app/flags/legacy"
+)
+
+func qualified(m *ff.Manager) {
+	_ = m.IsEnabled(checkout.Flag)
+	_ = m.IsEnabled(legacy.Flag)
+}
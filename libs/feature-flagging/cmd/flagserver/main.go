@@ -0,0 +1,200 @@
+// Command flagserver serves feature flags over HTTP for remote.Provider
+// clients to poll, from a definition file or a database table:
+//
+//	flagserver -file flags.yaml
+//	flagserver -store sqlite:/var/lib/flags.db -migrate
+//	flagserver -store "postgres://flags:secret@db:5432/flags?sslmode=disable"
+//
+// Flags are served at /flags. Files are watched and stores refreshed, so
+// changes reach clients on their next poll. With -admin, the httpapi admin
+// API is mounted under /admin for flags backed by a store; it has no
+// authentication, so only enable it on a trusted network.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	featureflag "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+	"github.com/JWindy92/obelisk-platform/libs/feature-flagging/httpapi"
+	"github.com/JWindy92/obelisk-platform/libs/feature-flagging/remote"
+	"github.com/JWindy92/obelisk-platform/libs/feature-flagging/storeprovider"
+	"github.com/JWindy92/obelisk-platform/libs/store"
+	"github.com/JWindy92/obelisk-platform/libs/store/migrate"
+	"github.com/JWindy92/obelisk-platform/libs/store/postgres"
+	"github.com/JWindy92/obelisk-platform/libs/store/sqlite"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stderr); err != nil {
+		log.Fatalf("flagserver: %v", err)
+	}
+}
+
+// options are the command-line settings.
+type options struct {
+	addr     string
+	file     string
+	store    string
+	table    string
+	interval time.Duration
+	migrate  bool
+	admin    bool
+}
+
+func parseOptions(args []string, stderr io.Writer) (options, error) {
+	var opts options
+	fs := flag.NewFlagSet("flagserver", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&opts.addr, "addr", ":8080", "address to listen on")
+	fs.StringVar(&opts.file, "file", "", "flag definition file (JSON, YAML or TOML)")
+	fs.StringVar(&opts.store, "store", "", `database to read flags from: "sqlite:<path>" or a postgres:// URL`)
+	fs.StringVar(&opts.table, "table", storeprovider.DefaultConfig().TableName, "flag table name")
+	fs.DurationVar(&opts.interval, "interval", 5*time.Second, "how often to reload the file or store")
+	fs.BoolVar(&opts.migrate, "migrate", false, "create the flag table if needed")
+	fs.BoolVar(&opts.admin, "admin", false, "serve the admin API under /admin (store only)")
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+
+	if (opts.file == "") == (opts.store == "") {
+		return options{}, fmt.Errorf("exactly one of -file or -store is required")
+	}
+	if opts.admin && opts.store == "" {
+		return options{}, fmt.Errorf("-admin requires -store")
+	}
+	return opts, nil
+}
+
+func run(ctx context.Context, args []string, stderr io.Writer) error {
+	opts, err := parseOptions(args, stderr)
+	if err != nil {
+		return err
+	}
+	logger := log.New(stderr, "flagserver: ", log.LstdFlags)
+
+	handler, err := newHandler(ctx, opts, func(err error) { logger.Print(err) })
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:              opts.addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	logger.Printf("serving flags on %s", opts.addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// newHandler opens the flag source, starts reloading it in the background
+// until ctx is cancelled, and returns the server's routes.
+func newHandler(ctx context.Context, opts options, onError func(error)) (http.Handler, error) {
+	mux := http.NewServeMux()
+
+	if opts.file != "" {
+		provider, err := featureflag.NewFileProvider(featureflag.FileConfig{
+			Path:          opts.file,
+			PollInterval:  opts.interval,
+			OnReloadError: onError,
+		})
+		if err != nil {
+			return nil, err
+		}
+		go provider.RunWatcher(ctx)
+		mux.Handle("/flags", remote.NewHandler(provider))
+		return mux, nil
+	}
+
+	st, err := openStore(opts.store)
+	if err != nil {
+		return nil, err
+	}
+	if err := st.Connect(ctx); err != nil {
+		return nil, err
+	}
+	go func() {
+		<-ctx.Done()
+		st.Close()
+	}()
+
+	config := storeprovider.Config{TableName: opts.table, RefreshInterval: opts.interval}
+	if opts.migrate {
+		m := migrate.New(st, migrate.Config{})
+		if err := m.Register(storeprovider.Migrations(st.Dialect(), config)); err != nil {
+			return nil, err
+		}
+		if err := m.Up(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	provider := storeprovider.NewProvider(st, config)
+	if err := provider.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	go provider.RunRefresher(ctx, onError)
+
+	mux.Handle("/flags", remote.NewHandler(provider))
+	if opts.admin {
+		mux.Handle("/admin/", http.StripPrefix("/admin", httpapi.NewHandler(provider)))
+	}
+	return mux, nil
+}
+
+// openStore creates a store from "sqlite:<path>" or a postgres:// URL.
+func openStore(spec string) (store.Store, error) {
+	if path, ok := strings.CutPrefix(spec, "sqlite:"); ok {
+		return sqlite.New(path, store.Config{}), nil
+	}
+
+	u, err := url.Parse(spec)
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		return nil, fmt.Errorf(`invalid -store %q: want "sqlite:<path>" or a postgres:// URL`, spec)
+	}
+	port := 5432
+	if p := u.Port(); p != "" {
+		if port, err = strconv.Atoi(p); err != nil {
+			return nil, fmt.Errorf("invalid postgres port %q", p)
+		}
+	}
+	password, _ := u.User.Password()
+	sslMode := u.Query().Get("sslmode")
+	if sslMode == "" {
+		sslMode = "require"
+	}
+
+	return postgres.New(postgres.Config{
+		Host:     u.Hostname(),
+		Port:     port,
+		User:     u.User.Username(),
+		Password: password,
+		DBName:   strings.TrimPrefix(u.Path, "/"),
+		SSLMode:  sslMode,
+	}, store.Config{}), nil
+}
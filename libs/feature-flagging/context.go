@@ -0,0 +1,134 @@
+package featureflag
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// overridesKey is the context key for flag overrides.
+type overridesKey struct{}
+
+// WithOverrides returns a context that forces the given flags on or off for
+// every evaluation made with it, regardless of the provider, targeting and
+// rollouts. It is meant for QA and debugging, e.g. from a verified request
+// header. Overrides merge with any already on ctx, the new ones winning.
+func WithOverrides(ctx context.Context, overrides map[string]bool) context.Context {
+	merged := make(map[string]bool, len(overrides))
+	for name, enabled := range overridesFrom(ctx) {
+		merged[name] = enabled
+	}
+	for name, enabled := range overrides {
+		merged[name] = enabled
+	}
+	return context.WithValue(ctx, overridesKey{}, merged)
+}
+
+// OverridesFrom returns a copy of the overrides carried by ctx.
+func OverridesFrom(ctx context.Context) map[string]bool {
+	overrides := overridesFrom(ctx)
+	if overrides == nil {
+		return nil
+	}
+	copied := make(map[string]bool, len(overrides))
+	for name, enabled := range overrides {
+		copied[name] = enabled
+	}
+	return copied
+}
+
+func overridesFrom(ctx context.Context) map[string]bool {
+	overrides, _ := ctx.Value(overridesKey{}).(map[string]bool)
+	return overrides
+}
+
+// evalContextKey is the context key for the EvalContext.
+type evalContextKey struct{}
+
+// WithEvalContext returns a context carrying evalCtx, typically the current
+// user and tenant, so code deeper in the call stack can evaluate flags for
+// them with the Manager's context-aware methods such as IsEnabledCtx.
+func WithEvalContext(ctx context.Context, evalCtx EvalContext) context.Context {
+	return context.WithValue(ctx, evalContextKey{}, evalCtx)
+}
+
+// FromContext returns the EvalContext attached to ctx with WithEvalContext.
+func FromContext(ctx context.Context) (EvalContext, bool) {
+	evalCtx, ok := ctx.Value(evalContextKey{}).(EvalContext)
+	return evalCtx, ok
+}
+
+// requestCacheKey is the context key for the evaluation cache.
+type requestCacheKey struct{}
+
+// requestCache remembers evaluation results for the lifetime of a context,
+// separately for each Manager, since Managers sharing a request may serve
+// different flags.
+type requestCache struct {
+	mu      sync.Mutex
+	results map[*Manager]map[string]evaluation
+}
+
+// evaluation is the outcome of evaluating one flag: the definition it was
+// evaluated against, and whether an override decided it.
+type evaluation struct {
+	flag       Flag
+	exists     bool
+	enabled    bool
+	overridden bool
+}
+
+// WithRequestCache returns a context that remembers every flag evaluation
+// made with it, so a flag evaluates the same way for the rest of the
+// request even if its definition changes mid-flight. Results are cached
+// per Manager, flag and evaluation context, including those of the
+// prerequisites and kill switches reached while evaluating. Attach
+// overrides before the cache; the cache is safe for concurrent use by the
+// request's goroutines.
+func WithRequestCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestCacheKey{}, &requestCache{
+		results: make(map[*Manager]map[string]evaluation),
+	})
+}
+
+func requestCacheFrom(ctx context.Context) *requestCache {
+	cache, _ := ctx.Value(requestCacheKey{}).(*requestCache)
+	return cache
+}
+
+func (c *requestCache) get(m *Manager, key string) (evaluation, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result, ok := c.results[m][key]
+	return result, ok
+}
+
+func (c *requestCache) put(m *Manager, key string, result evaluation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	results, ok := c.results[m]
+	if !ok {
+		results = make(map[string]evaluation)
+		c.results[m] = results
+	}
+	results[key] = result
+}
+
+// cacheKey identifies the evaluation of flagName for evalCtx.
+func cacheKey(flagName string, evalCtx EvalContext) string {
+	var b strings.Builder
+	b.WriteString(strconv.Quote(flagName))
+	b.WriteString(strconv.Quote(evalCtx.UserID))
+	names := make([]string, 0, len(evalCtx.Attributes))
+	for name := range evalCtx.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString(strconv.Quote(name))
+		b.WriteString(strconv.Quote(evalCtx.Attributes[name]))
+	}
+	return b.String()
+}
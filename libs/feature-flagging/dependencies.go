@@ -0,0 +1,102 @@
+package featureflag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrDependencyCycle is returned when flags depend on each other, through
+// prerequisites or kill switches, in a cycle.
+var ErrDependencyCycle = errors.New("feature flag dependency cycle")
+
+// ValidateFlags validates every flag and checks the set as a whole: names
+// must be unique and prerequisites and kill switches must not form a cycle.
+// Dependencies on flags outside the set are allowed, since they may be
+// defined by another provider in a chain. Errors wrap ErrInvalidFlag or,
+// for cycles, ErrDependencyCycle.
+func ValidateFlags(flags []Flag) error {
+	byName := make(map[string]Flag, len(flags))
+	for _, flag := range flags {
+		if err := flag.Validate(); err != nil {
+			return err
+		}
+		if _, ok := byName[flag.Name]; ok {
+			return fmt.Errorf("%w: duplicate flag %q", ErrInvalidFlag, flag.Name)
+		}
+		byName[flag.Name] = flag
+	}
+
+	// Depth-first search, visiting flags in a fixed order so the reported
+	// cycle is deterministic.
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(byName))
+	var path []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			start := slices.Index(path, name)
+			cycle := append(slices.Clone(path[start:]), name)
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(cycle, " -> "))
+		case done:
+			return nil
+		}
+
+		flag, ok := byName[name]
+		if !ok {
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range flag.Dependencies() {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+		return nil
+	}
+
+	for _, flag := range sortedFlags(byName) {
+		if err := visit(flag.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dependenciesMet reports whether flag's kill switch is off and all its
+// prerequisites are on for evalCtx. path holds the flags being evaluated
+// above this one; a dependency back onto the path is a cycle that slipped
+// past validation, e.g. across chained providers, and fails safe as off.
+func (m *Manager) dependenciesMet(ctx context.Context, flag Flag, evalCtx EvalContext, path []string) bool {
+	path = append(path, flag.Name)
+
+	if flag.KillSwitch != "" {
+		if slices.Contains(path, flag.KillSwitch) {
+			return false
+		}
+		if m.evaluateDependency(ctx, flag.KillSwitch, evalCtx, path).enabled {
+			return false
+		}
+	}
+
+	for _, name := range flag.Prerequisites {
+		if slices.Contains(path, name) {
+			return false
+		}
+		if !m.evaluateDependency(ctx, name, evalCtx, path).enabled {
+			return false
+		}
+	}
+	return true
+}
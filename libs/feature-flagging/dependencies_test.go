@@ -0,0 +1,181 @@
+package featureflag
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		flags     []Flag
+		wantErr   string
+		wantCycle bool
+	}{
+		{
+			name: "valid chain",
+			flags: []Flag{
+				{Name: "a", Prerequisites: []string{"b"}, KillSwitch: "kill"},
+				{Name: "b", Prerequisites: []string{"c"}},
+				{Name: "c"},
+				{Name: "kill"},
+			},
+		},
+		{
+			name:  "undefined dependency",
+			flags: []Flag{{Name: "a", Prerequisites: []string{"elsewhere"}}},
+		},
+		{
+			name:    "duplicate name",
+			flags:   []Flag{{Name: "a"}, {Name: "a"}},
+			wantErr: "duplicate flag",
+		},
+		{
+			name:      "self dependency",
+			flags:     []Flag{{Name: "a", KillSwitch: "a"}},
+			wantErr:   "depends on itself",
+			wantCycle: true,
+		},
+		{
+			name: "prerequisite cycle",
+			flags: []Flag{
+				{Name: "a", Prerequisites: []string{"b"}},
+				{Name: "b", Prerequisites: []string{"c"}},
+				{Name: "c", Prerequisites: []string{"a"}},
+			},
+			wantErr:   "a -> b -> c -> a",
+			wantCycle: true,
+		},
+		{
+			name: "cycle through kill switch",
+			flags: []Flag{
+				{Name: "kill", Prerequisites: []string{"a"}},
+				{Name: "a", KillSwitch: "kill"},
+			},
+			wantErr:   "a -> kill -> a",
+			wantCycle: true,
+		},
+		{
+			name:    "NaN rollout",
+			flags:   []Flag{{Name: "a", Rollout: &Rollout{Percentage: math.NaN()}}},
+			wantErr: "between 0 and 100",
+		},
+		{
+			name:    "infinite rollout",
+			flags:   []Flag{{Name: "a", Rollout: &Rollout{Percentage: math.Inf(1)}}},
+			wantErr: "between 0 and 100",
+		},
+		{
+			name:    "empty prerequisite",
+			flags:   []Flag{{Name: "a", Prerequisites: []string{""}}},
+			wantErr: "prerequisite name is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFlags(tt.flags)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateFlags() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateFlags() error = %v, want containing %q", err, tt.wantErr)
+			}
+			if got := errors.Is(err, ErrDependencyCycle); got != tt.wantCycle {
+				t.Errorf("errors.Is(err, ErrDependencyCycle) = %v, want %v", got, tt.wantCycle)
+			}
+			if !errors.Is(err, ErrInvalidFlag) && !errors.Is(err, ErrDependencyCycle) {
+				t.Errorf("ValidateFlags() error = %v, want ErrInvalidFlag or ErrDependencyCycle", err)
+			}
+		})
+	}
+}
+
+func TestFlag_Dependencies(t *testing.T) {
+	flag := Flag{Name: "new-checkout", Prerequisites: []string{"new-cart", "payments-v2"}, KillSwitch: "payments-kill"}
+
+	deps := flag.Dependencies()
+	if want := []string{"new-cart", "payments-v2", "payments-kill"}; !slices.Equal(deps, want) {
+		t.Errorf("Dependencies() = %v, want %v", deps, want)
+	}
+
+	deps[0] = "changed"
+	if flag.Prerequisites[0] != "new-cart" {
+		t.Error("modifying Dependencies() changed the flag's prerequisites")
+	}
+	if deps := (Flag{Name: "plain"}).Dependencies(); len(deps) != 0 {
+		t.Errorf("Dependencies() of flag without dependencies = %v, want none", deps)
+	}
+}
+
+func TestManager_Dependencies(t *testing.T) {
+	provider := newStaticProvider(t,
+		Flag{Name: "payments-kill", Enabled: false},
+		Flag{Name: "new-checkout", Enabled: true, KillSwitch: "payments-kill"},
+		Flag{Name: "one-click", Enabled: true, Prerequisites: []string{"new-checkout"}, KillSwitch: "payments-kill"},
+		Flag{Name: "tenant-beta", Enabled: true, Rules: []Rule{{Attribute: "tenant", Operator: OpEquals, Values: []string{"acme"}}}},
+		Flag{Name: "beta-reports", Enabled: true, Prerequisites: []string{"tenant-beta"}},
+		Flag{Name: "orphan", Enabled: true, Prerequisites: []string{"missing"}},
+		Flag{
+			Name: "theme", Enabled: true, Prerequisites: []string{"tenant-beta"},
+			DefaultVariant: "light",
+			Variants:       []Variant{{Name: "light", Value: "light"}, {Name: "dark", Value: "dark", Weight: 1}},
+		},
+	)
+	m := New(provider)
+	ctx := context.Background()
+	acme := EvalContext{UserID: "u1", Attributes: map[string]string{"tenant": "acme"}}
+	globex := EvalContext{UserID: "u1", Attributes: map[string]string{"tenant": "globex"}}
+
+	if !m.IsEnabled("one-click") {
+		t.Error("IsEnabled(one-click) = false with prerequisite on")
+	}
+	if m.IsEnabled("orphan") {
+		t.Error("IsEnabled(orphan) = true with missing prerequisite")
+	}
+	if !m.IsEnabledFor(ctx, "beta-reports", acme) {
+		t.Error("IsEnabledFor(beta-reports) = false when prerequisite matches")
+	}
+	if m.IsEnabledFor(ctx, "beta-reports", globex) {
+		t.Error("IsEnabledFor(beta-reports) = true when prerequisite does not match")
+	}
+	if got := m.StringVariant(ctx, "theme", globex, "system"); got != "light" {
+		t.Errorf("StringVariant(theme) = %q, want default variant when prerequisite is off", got)
+	}
+	if got := m.StringVariant(ctx, "theme", acme, "system"); got != "dark" {
+		t.Errorf("StringVariant(theme) = %q, want dark", got)
+	}
+
+	provider.Set("payments-kill", true)
+	for _, name := range []string{"new-checkout", "one-click"} {
+		if m.IsEnabled(name) {
+			t.Errorf("IsEnabled(%s) = true with kill switch on", name)
+		}
+	}
+
+	// Overriding a kill switch in a request context revives its group there.
+	revived := WithOverrides(ctx, map[string]bool{"payments-kill": false})
+	if !m.IsEnabledFor(revived, "one-click", EvalContext{}) {
+		t.Error("IsEnabledFor(one-click) = false with kill switch overridden off")
+	}
+}
+
+func TestManager_DependencyCycleFailsSafe(t *testing.T) {
+	// Cycles can only be detected per provider; across a chain they are
+	// caught at evaluation time instead.
+	m := New(NewChainProvider(
+		newStaticProvider(t, Flag{Name: "a", Enabled: true, Prerequisites: []string{"b"}}),
+		newStaticProvider(t, Flag{Name: "b", Enabled: true, Prerequisites: []string{"a"}}),
+	))
+
+	if m.IsEnabled("a") || m.IsEnabled("b") {
+		t.Error("IsEnabled() = true for flags in a dependency cycle")
+	}
+}
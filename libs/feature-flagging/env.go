@@ -0,0 +1,130 @@
+package featureflag
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EnvConfig holds configuration options for an EnvProvider.
+type EnvConfig struct {
+	// Prefix is prepended to normalized flag names to form variable names.
+	// Defaults to "FEATURE_" if not specified.
+	Prefix string
+
+	// LookupEnv reads a variable. Defaults to os.LookupEnv; override it in
+	// tests or to read from another source.
+	LookupEnv func(key string) (string, bool)
+}
+
+// EnvProvider reads flags from environment variables, for overrides in
+// containers and during development. The flag "new-checkout" is read from
+// FEATURE_NEW_CHECKOUT: the name is upper-cased and every character other
+// than a letter or digit becomes an underscore.
+//
+// Values are interpreted as:
+//
+//   - true/false, on/off, yes/no, 1/0: a plain on/off flag
+//   - a percentage such as "25%": an enabled flag with that rollout
+//   - anything else: an enabled flag serving that value as its only
+//     variant, decoded as JSON if it is an object or array
+//
+// Unset or empty variables and percentages outside 0-100 leave the flag
+// undefined. Variables are read on every lookup, so changes made with
+// os.Setenv take effect immediately. To override other providers, put an
+// EnvProvider first in a ChainProvider.
+type EnvProvider struct {
+	prefix    string
+	lookupEnv func(string) (string, bool)
+}
+
+var _ Provider = (*EnvProvider)(nil)
+
+// NewEnvProvider creates a provider that reads flags from the environment.
+func NewEnvProvider(config EnvConfig) *EnvProvider {
+	if config.Prefix == "" {
+		config.Prefix = "FEATURE_"
+	}
+	if config.LookupEnv == nil {
+		config.LookupEnv = os.LookupEnv
+	}
+
+	return &EnvProvider{
+		prefix:    config.Prefix,
+		lookupEnv: config.LookupEnv,
+	}
+}
+
+// VarName returns the environment variable a flag is read from.
+func (e *EnvProvider) VarName(flagName string) string {
+	var b strings.Builder
+	b.WriteString(e.prefix)
+	for _, r := range strings.ToUpper(flagName) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// IsEnabled checks if a feature flag is enabled.
+// Returns false if the variable is unset (fail-safe default).
+func (e *EnvProvider) IsEnabled(flagName string) bool {
+	flag, _ := e.Flag(flagName)
+	return flag.Enabled
+}
+
+// Lookup reports whether a feature flag is enabled and whether its
+// variable is set.
+func (e *EnvProvider) Lookup(flagName string) (enabled, exists bool) {
+	flag, exists := e.Flag(flagName)
+	return flag.Enabled, exists
+}
+
+// Flag returns the flag defined by the variable and whether it is set.
+func (e *EnvProvider) Flag(flagName string) (Flag, bool) {
+	raw, ok := e.lookupEnv(e.VarName(flagName))
+	value := strings.TrimSpace(raw)
+	if !ok || value == "" {
+		return Flag{}, false
+	}
+
+	if enabled, ok := parseEnvBool(value); ok {
+		return Flag{Name: flagName, Enabled: enabled}, true
+	}
+
+	if pct, isPct := strings.CutSuffix(value, "%"); isPct {
+		percentage, err := strconv.ParseFloat(strings.TrimSpace(pct), 64)
+		if err != nil || math.IsNaN(percentage) || percentage < 0 || percentage > 100 {
+			return Flag{}, false
+		}
+		return Flag{Name: flagName, Enabled: true, Rollout: &Rollout{Percentage: percentage}}, true
+	}
+
+	var variantValue any = value
+	if strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[") {
+		var decoded any
+		if err := json.Unmarshal([]byte(value), &decoded); err == nil {
+			variantValue = decoded
+		}
+	}
+	return Flag{
+		Name:     flagName,
+		Enabled:  true,
+		Variants: []Variant{{Name: value, Value: variantValue}},
+	}, true
+}
+
+func parseEnvBool(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "true", "on", "yes", "1":
+		return true, true
+	case "false", "off", "no", "0":
+		return false, true
+	}
+	return false, false
+}
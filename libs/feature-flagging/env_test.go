@@ -0,0 +1,133 @@
+package featureflag
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func fakeEnv(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+func TestEnvProvider_VarName(t *testing.T) {
+	e := NewEnvProvider(EnvConfig{})
+
+	tests := map[string]string{
+		"new-checkout":      "FEATURE_NEW_CHECKOUT",
+		"beta.api":          "FEATURE_BETA_API",
+		"checkoutV2":        "FEATURE_CHECKOUTV2",
+		"user service/v2 x": "FEATURE_USER_SERVICE_V2_X",
+	}
+	for flag, want := range tests {
+		if got := e.VarName(flag); got != want {
+			t.Errorf("VarName(%q) = %q, want %q", flag, got, want)
+		}
+	}
+
+	custom := NewEnvProvider(EnvConfig{Prefix: "APP_FF_"})
+	if got := custom.VarName("new-checkout"); got != "APP_FF_NEW_CHECKOUT" {
+		t.Errorf("VarName() with prefix = %q", got)
+	}
+}
+
+func TestEnvProvider_Flag(t *testing.T) {
+	e := NewEnvProvider(EnvConfig{LookupEnv: fakeEnv(map[string]string{
+		"FEATURE_ON":        "true",
+		"FEATURE_OFF":       "off",
+		"FEATURE_NUMERIC":   "0",
+		"FEATURE_ROLLOUT":   "25%",
+		"FEATURE_BAD_PCT":   "150%",
+		"FEATURE_NAN_PCT":   "NaN%",
+		"FEATURE_ALGORITHM": "v2",
+		"FEATURE_MAX_BATCH": "500",
+		"FEATURE_LIMITS":    `{"requests": 10}`,
+		"FEATURE_EMPTY":     "  ",
+	})})
+
+	tests := []struct {
+		flag        string
+		wantExists  bool
+		wantEnabled bool
+		wantRollout float64
+		wantVariant string
+	}{
+		{flag: "on", wantExists: true, wantEnabled: true},
+		{flag: "off", wantExists: true, wantEnabled: false},
+		{flag: "numeric", wantExists: true, wantEnabled: false},
+		{flag: "rollout", wantExists: true, wantEnabled: true, wantRollout: 25},
+		{flag: "bad-pct", wantExists: false},
+		{flag: "nan-pct", wantExists: false},
+		{flag: "algorithm", wantExists: true, wantEnabled: true, wantVariant: "v2"},
+		{flag: "max-batch", wantExists: true, wantEnabled: true, wantVariant: "500"},
+		{flag: "empty", wantExists: false},
+		{flag: "unset", wantExists: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flag, func(t *testing.T) {
+			flag, exists := e.Flag(tt.flag)
+			if exists != tt.wantExists || flag.Enabled != tt.wantEnabled {
+				t.Fatalf("Flag() = %+v, %v; want enabled=%v exists=%v", flag, exists, tt.wantEnabled, tt.wantExists)
+			}
+			if enabled, exists := e.Lookup(tt.flag); enabled != tt.wantEnabled || exists != tt.wantExists {
+				t.Errorf("Lookup() = (%v, %v)", enabled, exists)
+			}
+			if tt.wantRollout != 0 && (flag.Rollout == nil || flag.Rollout.Percentage != tt.wantRollout) {
+				t.Errorf("Flag().Rollout = %+v, want %v%%", flag.Rollout, tt.wantRollout)
+			}
+			if tt.wantVariant != "" && (len(flag.Variants) != 1 || flag.Variants[0].Name != tt.wantVariant) {
+				t.Errorf("Flag().Variants = %+v, want %s", flag.Variants, tt.wantVariant)
+			}
+		})
+	}
+}
+
+func TestEnvProvider_WithManager(t *testing.T) {
+	ctx := context.Background()
+	m := New(NewEnvProvider(EnvConfig{LookupEnv: fakeEnv(map[string]string{
+		"FEATURE_ALGORITHM": "v2",
+		"FEATURE_MAX_BATCH": "500",
+		"FEATURE_LIMITS":    `{"requests": 10}`,
+		"FEATURE_ROLLOUT":   "30%",
+	})}))
+
+	if got := m.StringVariant(ctx, "algorithm", EvalContext{}, "v1"); got != "v2" {
+		t.Errorf("StringVariant() = %q, want v2", got)
+	}
+	if got := m.IntVariant(ctx, "max-batch", EvalContext{}, 100); got != 500 {
+		t.Errorf("IntVariant() = %d, want 500", got)
+	}
+
+	var limits struct{ Requests int }
+	if err := m.JSONVariant(ctx, "limits", EvalContext{}, &limits); err != nil || limits.Requests != 10 {
+		t.Errorf("JSONVariant() = %+v, %v; want 10 requests", limits, err)
+	}
+
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		if m.IsEnabledFor(ctx, "rollout", EvalContext{UserID: fmt.Sprintf("u%d", i)}) {
+			enabled++
+		}
+	}
+	if enabled < 250 || enabled > 350 {
+		t.Errorf("30%% rollout enabled %d of 1000 users", enabled)
+	}
+}
+
+func TestEnvProvider_LayeredOverStatic(t *testing.T) {
+	t.Setenv("FEATURE_NEW_CHECKOUT", "false")
+
+	defaults := NewStaticProvider(map[string]bool{"new-checkout": true, "beta-api": true})
+	m := New(NewChainProvider(NewEnvProvider(EnvConfig{}), defaults))
+
+	if m.IsEnabled("new-checkout") {
+		t.Error("IsEnabled(new-checkout) = true, want env override to force it off")
+	}
+	if !m.IsEnabled("beta-api") {
+		t.Error("IsEnabled(beta-api) = false, want static default")
+	}
+}
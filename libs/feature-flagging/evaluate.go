@@ -0,0 +1,174 @@
+package featureflag
+
+import (
+	"hash/fnv"
+	"regexp"
+	"sync"
+)
+
+// EvalContext describes who a flag is being evaluated for.
+type EvalContext struct {
+	// UserID identifies the user. It is the default key for percentage
+	// rollouts and can be targeted in rules as AttributeUserID.
+	UserID string
+
+	// Attributes holds arbitrary targeting data such as "tenant",
+	// "country" or "app_version".
+	Attributes map[string]string
+}
+
+// Attribute returns the named attribute, resolving AttributeUserID to UserID.
+func (c EvalContext) Attribute(name string) (string, bool) {
+	if name == AttributeUserID {
+		return c.UserID, c.UserID != ""
+	}
+	value, ok := c.Attributes[name]
+	return value, ok
+}
+
+// evaluate reports whether flag is on for evalCtx.
+func evaluate(flag Flag, evalCtx EvalContext) bool {
+	if !flag.Enabled {
+		return false
+	}
+	for _, rule := range flag.Rules {
+		if !rule.Matches(evalCtx) {
+			return false
+		}
+	}
+	if flag.Rollout != nil {
+		return flag.Rollout.includes(flag.Name, evalCtx)
+	}
+	return true
+}
+
+// resolveVariant picks the variant flag serves to evalCtx, given whether the
+// flag evaluated as enabled for it. It returns false when the flag has no
+// variant to serve, in which case callers fall back to their own default.
+func resolveVariant(flag Flag, enabled bool, evalCtx EvalContext) (Variant, bool) {
+	if len(flag.Variants) == 0 {
+		return Variant{}, false
+	}
+	if !enabled {
+		return flag.Variant(flag.DefaultVariant)
+	}
+
+	total := 0
+	for _, v := range flag.Variants {
+		if v.Weight > 0 {
+			total += v.Weight
+		}
+	}
+
+	key, ok := bucketKey(flag.Rollout, evalCtx)
+	if total == 0 || !ok {
+		if v, ok := flag.Variant(flag.DefaultVariant); ok {
+			return v, true
+		}
+		return flag.Variants[0], true
+	}
+
+	// Salt with a suffix so the variant split is independent of the rollout.
+	point := bucket(flag.Name+"#variant", key) / 100 * float64(total)
+	var picked Variant
+	cumulative := 0.0
+	for _, v := range flag.Variants {
+		if v.Weight <= 0 {
+			continue
+		}
+		picked = v
+		cumulative += float64(v.Weight)
+		if point < cumulative {
+			break
+		}
+	}
+	return picked, true
+}
+
+// Matches reports whether the rule holds for evalCtx.
+func (r Rule) Matches(evalCtx EvalContext) bool {
+	value, ok := evalCtx.Attribute(r.Attribute)
+	if !ok || len(r.Values) == 0 {
+		return false
+	}
+
+	switch r.Operator {
+	case OpEquals:
+		return value == r.Values[0]
+	case OpNotEquals:
+		return value != r.Values[0]
+	case OpIn:
+		return contains(r.Values, value)
+	case OpNotIn:
+		return !contains(r.Values, value)
+	case OpMatches:
+		re, err := compileCached(r.Values[0])
+		return err == nil && re.MatchString(value)
+	case OpSemverEq, OpSemverGt, OpSemverGte, OpSemverLt, OpSemverLte:
+		return matchSemver(r.Operator, value, r.Values[0])
+	}
+	return false
+}
+
+// includes reports whether evalCtx falls inside the rollout percentage.
+// Contexts without a bucketing key are only included at 100%.
+func (r Rollout) includes(flagName string, evalCtx EvalContext) bool {
+	if r.Percentage >= 100 {
+		return true
+	}
+	if r.Percentage <= 0 {
+		return false
+	}
+
+	key, ok := bucketKey(&r, evalCtx)
+	if !ok {
+		return false
+	}
+	return bucket(flagName, key) < r.Percentage
+}
+
+// bucketKey returns the value evalCtx is bucketed by: the rollout's BucketBy
+// attribute if set, otherwise the user ID.
+func bucketKey(rollout *Rollout, evalCtx EvalContext) (string, bool) {
+	attr := AttributeUserID
+	if rollout != nil && rollout.BucketBy != "" {
+		attr = rollout.BucketBy
+	}
+	key, ok := evalCtx.Attribute(attr)
+	return key, ok && key != ""
+}
+
+// bucket deterministically maps a flag and key to a value in [0, 100) with
+// a resolution of 0.01. Salting with the flag name keeps different flags
+// from enabling the same slice of users.
+func bucket(flagName, key string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(flagName))
+	h.Write([]byte{':'})
+	h.Write([]byte(key))
+	return float64(h.Sum32()%10000) / 100
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// regexCache avoids recompiling rule patterns on every evaluation.
+var regexCache sync.Map // map[string]*regexp.Regexp
+
+func compileCached(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(pattern, re)
+	return re, nil
+}
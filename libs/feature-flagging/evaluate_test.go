@@ -0,0 +1,144 @@
+package featureflag
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestRule_Matches(t *testing.T) {
+	evalCtx := EvalContext{
+		UserID: "user-42",
+		Attributes: map[string]string{
+			"tenant":      "acme",
+			"email":       "qa@example.com",
+			"app_version": "2.4.1",
+		},
+	}
+
+	tests := []struct {
+		name string
+		rule Rule
+		want bool
+	}{
+		{name: "equals match", rule: Rule{"tenant", OpEquals, []string{"acme"}}, want: true},
+		{name: "equals mismatch", rule: Rule{"tenant", OpEquals, []string{"globex"}}, want: false},
+		{name: "not equals", rule: Rule{"tenant", OpNotEquals, []string{"globex"}}, want: true},
+		{name: "in list", rule: Rule{"tenant", OpIn, []string{"globex", "acme"}}, want: true},
+		{name: "not in list", rule: Rule{"tenant", OpNotIn, []string{"globex", "acme"}}, want: false},
+		{name: "user id attribute", rule: Rule{AttributeUserID, OpIn, []string{"user-42"}}, want: true},
+		{name: "regex", rule: Rule{"email", OpMatches, []string{`@example\.com$`}}, want: true},
+		{name: "invalid regex never matches", rule: Rule{"email", OpMatches, []string{`(`}}, want: false},
+		{name: "semver gte", rule: Rule{"app_version", OpSemverGte, []string{"2.4.0"}}, want: true},
+		{name: "semver lt", rule: Rule{"app_version", OpSemverLt, []string{"2.4.1"}}, want: false},
+		{name: "semver eq with v prefix", rule: Rule{"app_version", OpSemverEq, []string{"v2.4.1"}}, want: true},
+		{name: "missing attribute", rule: Rule{"country", OpNotIn, []string{"US"}}, want: false},
+		{name: "no values", rule: Rule{"tenant", OpEquals, nil}, want: false},
+		{name: "unknown operator", rule: Rule{"tenant", "startswith", []string{"a"}}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(evalCtx); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.0", "1.0.1", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"1.2", "1.2.0", 0},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-rc.1", "1.0.0-beta.11", 1},
+		{"1.0.0+build.5", "1.0.0", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s vs %s", tt.a, tt.b), func(t *testing.T) {
+			a, ok := parseSemver(tt.a)
+			if !ok {
+				t.Fatalf("parseSemver(%q) failed", tt.a)
+			}
+			b, ok := parseSemver(tt.b)
+			if !ok {
+				t.Fatalf("parseSemver(%q) failed", tt.b)
+			}
+			if got := a.compare(b); got != tt.want {
+				t.Errorf("compare() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	for _, bad := range []string{"", "1.x", "1.2.3.4", "1.0.0-"} {
+		if _, ok := parseSemver(bad); ok {
+			t.Errorf("parseSemver(%q) succeeded, want failure", bad)
+		}
+	}
+}
+
+func TestRollout_Bucketing(t *testing.T) {
+	m := New(newStaticProvider(t, Flag{
+		Name:    "new-checkout",
+		Enabled: true,
+		Rollout: &Rollout{Percentage: 10},
+	}))
+	ctx := context.Background()
+
+	enabled := 0
+	const users = 10000
+	for i := 0; i < users; i++ {
+		evalCtx := EvalContext{UserID: fmt.Sprintf("user-%d", i)}
+		got := m.IsEnabledFor(ctx, "new-checkout", evalCtx)
+		if got != m.IsEnabledFor(ctx, "new-checkout", evalCtx) {
+			t.Fatalf("IsEnabledFor() is not deterministic for %s", evalCtx.UserID)
+		}
+		if got {
+			enabled++
+		}
+	}
+
+	if enabled < users*8/100 || enabled > users*12/100 {
+		t.Errorf("10%% rollout enabled %d of %d users", enabled, users)
+	}
+
+	if m.IsEnabledFor(ctx, "new-checkout", EvalContext{}) {
+		t.Error("IsEnabledFor() without user ID included in partial rollout")
+	}
+}
+
+func TestRollout_RaisingPercentageOnlyAddsUsers(t *testing.T) {
+	low := Rollout{Percentage: 20}
+	high := Rollout{Percentage: 50}
+
+	for i := 0; i < 1000; i++ {
+		evalCtx := EvalContext{UserID: fmt.Sprintf("user-%d", i)}
+		if low.includes("flag", evalCtx) && !high.includes("flag", evalCtx) {
+			t.Fatalf("user %s dropped out when raising rollout", evalCtx.UserID)
+		}
+	}
+}
+
+func TestRollout_BucketBy(t *testing.T) {
+	rollout := Rollout{Percentage: 50, BucketBy: "tenant"}
+
+	// Every user of a tenant lands in the same bucket.
+	for _, tenant := range []string{"acme", "globex", "initech"} {
+		first := rollout.includes("flag", EvalContext{UserID: "a", Attributes: map[string]string{"tenant": tenant}})
+		for i := 0; i < 20; i++ {
+			evalCtx := EvalContext{UserID: fmt.Sprintf("u%d", i), Attributes: map[string]string{"tenant": tenant}}
+			if rollout.includes("flag", evalCtx) != first {
+				t.Fatalf("tenant %s split across buckets", tenant)
+			}
+		}
+	}
+}
@@ -0,0 +1,291 @@
+// Package exposure records which users were exposed to which flag variants,
+// so experiment results can be joined to outcomes.
+//
+// A Logger is a featureflag.Hook: register it with featureflag.WithHook and
+// every evaluation becomes an exposure Event. Events are deduplicated per
+// user session, batched, and written to a Sink: MemorySink for tests,
+// JSONLSink for log files and pipelines, or StoreSink for a database table.
+//
+//	exposures := exposure.NewLogger(exposure.NewStoreSink(st, exposure.DefaultStoreConfig()), exposure.Config{})
+//	go exposures.Run(ctx)
+//	ff := featureflag.New(provider, featureflag.WithHook(exposures))
+package exposure
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	featureflag "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+)
+
+// Event records that a user was served a flag result.
+type Event struct {
+	Flag string `json:"flag"`
+
+	// Variant is the variant served, or empty for on/off flags.
+	Variant string `json:"variant,omitempty"`
+
+	Enabled   bool      `json:"enabled"`
+	UserID    string    `json:"user_id,omitempty"`
+	SessionID string    `json:"session_id,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Sink persists batches of exposure events.
+type Sink interface {
+	// Write stores a batch of events.
+	Write(ctx context.Context, events []Event) error
+}
+
+// Config holds configuration options for a Logger.
+type Config struct {
+	// SessionAttribute is the evaluation context attribute that identifies
+	// the user's session. Defaults to "session_id" if not specified.
+	SessionAttribute string
+
+	// BatchSize is how many events are written to the sink at once.
+	// Defaults to 100 if not specified.
+	BatchSize int
+
+	// FlushInterval is how often Run writes a partial batch.
+	// Defaults to 5 seconds if not specified.
+	FlushInterval time.Duration
+
+	// MaxPending caps how many events wait to be written. Further events
+	// are dropped and counted by Dropped, so a slow or failing sink never
+	// blocks flag evaluation. Defaults to 10000 if not specified.
+	MaxPending int
+
+	// MaxDedupeEntries caps how many recent exposures are remembered for
+	// deduplication. When full, the memory is cleared and exposures are
+	// logged again once. Defaults to 100000 if not specified.
+	MaxDedupeEntries int
+
+	// Filter selects the evaluations that count as exposures.
+	// Defaults to evaluations of existing flags for a user or session.
+	Filter func(featureflag.Evaluation) bool
+
+	// OnError is called by Run when a batch fails to write. The batch is
+	// kept for the next flush while there is room. May be nil.
+	OnError func(error)
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		SessionAttribute: "session_id",
+		BatchSize:        100,
+		FlushInterval:    5 * time.Second,
+		MaxPending:       10000,
+		MaxDedupeEntries: 100000,
+	}
+}
+
+// Logger turns flag evaluations into exposure events. Each distinct result
+// is logged once per user session: evaluating the same flag again for the
+// same user and session with the same result is not a new exposure, whether
+// it was read as on/off or as a variant, while a different variant is. It is
+// safe for concurrent use.
+type Logger struct {
+	sink   Sink
+	config Config
+	now    func() time.Time
+
+	mu      sync.Mutex
+	pending []Event
+	seen    map[dedupeKey]struct{}
+	dropped atomic.Int64
+
+	// writeMu serializes writes to the sink.
+	writeMu sync.Mutex
+	full    chan struct{}
+}
+
+var _ featureflag.Hook = (*Logger)(nil)
+
+// dedupeKey identifies an exposure within a user session: the flag, who
+// it was served to, and the result served.
+type dedupeKey struct {
+	flag, user, session string
+	enabled             bool
+	variant             string
+}
+
+func eventKey(e Event) dedupeKey {
+	return dedupeKey{
+		flag:    e.Flag,
+		user:    e.UserID,
+		session: e.SessionID,
+		enabled: e.Enabled,
+		variant: e.Variant,
+	}
+}
+
+// NewLogger creates a Logger writing to sink. Call Run to write batches in
+// the background.
+func NewLogger(sink Sink, config Config) *Logger {
+	defaults := DefaultConfig()
+	if config.SessionAttribute == "" {
+		config.SessionAttribute = defaults.SessionAttribute
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaults.FlushInterval
+	}
+	if config.MaxPending <= 0 {
+		config.MaxPending = defaults.MaxPending
+	}
+	if config.MaxDedupeEntries <= 0 {
+		config.MaxDedupeEntries = defaults.MaxDedupeEntries
+	}
+	if config.Filter == nil {
+		config.Filter = defaultFilter(config.SessionAttribute)
+	}
+
+	return &Logger{
+		sink:   sink,
+		config: config,
+		now:    time.Now,
+		seen:   make(map[dedupeKey]struct{}),
+		full:   make(chan struct{}, 1),
+	}
+}
+
+func defaultFilter(sessionAttribute string) func(featureflag.Evaluation) bool {
+	return func(eval featureflag.Evaluation) bool {
+		return eval.Exists && (eval.EvalContext.UserID != "" || eval.EvalContext.Attributes[sessionAttribute] != "")
+	}
+}
+
+// AfterEvaluation queues an exposure event for eval unless it was already
+// logged for the same user session.
+func (l *Logger) AfterEvaluation(ctx context.Context, eval featureflag.Evaluation) {
+	if !l.config.Filter(eval) {
+		return
+	}
+
+	key := dedupeKey{
+		flag:    eval.FlagName,
+		user:    eval.EvalContext.UserID,
+		session: eval.EvalContext.Attributes[l.config.SessionAttribute],
+		enabled: eval.Enabled,
+		variant: eval.Variant,
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.seen[key]; ok {
+		return
+	}
+	if len(l.pending) >= l.config.MaxPending {
+		l.dropped.Add(1)
+		return
+	}
+	if len(l.seen) >= l.config.MaxDedupeEntries {
+		clear(l.seen)
+	}
+	l.seen[key] = struct{}{}
+
+	l.pending = append(l.pending, Event{
+		Flag:      key.flag,
+		Variant:   key.variant,
+		Enabled:   key.enabled,
+		UserID:    key.user,
+		SessionID: key.session,
+		Timestamp: l.now().UTC(),
+	})
+	if len(l.pending) >= l.config.BatchSize {
+		select {
+		case l.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush writes every pending event to the sink in batches of BatchSize.
+// If a batch fails, it and the events after it are kept for the next flush
+// as far as MaxPending allows.
+func (l *Logger) Flush(ctx context.Context) error {
+	l.writeMu.Lock()
+	defer l.writeMu.Unlock()
+
+	l.mu.Lock()
+	events := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+
+	for len(events) > 0 {
+		n := min(len(events), l.config.BatchSize)
+		if err := l.sink.Write(ctx, events[:n]); err != nil {
+			l.requeue(events)
+			return fmt.Errorf("failed to write exposure events: %w", err)
+		}
+		events = events[n:]
+	}
+	return nil
+}
+
+// requeue puts unwritten events back in front of those queued since.
+// Events that no longer fit are forgotten by deduplication, so the next
+// evaluation for the same user session logs them again.
+func (l *Logger) requeue(events []Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	merged := append(events, l.pending...)
+	if excess := len(merged) - l.config.MaxPending; excess > 0 {
+		l.dropped.Add(int64(excess))
+		for _, e := range merged[l.config.MaxPending:] {
+			delete(l.seen, eventKey(e))
+		}
+		merged = merged[:l.config.MaxPending]
+	}
+	l.pending = merged
+}
+
+// Run flushes pending events every FlushInterval, and whenever a full batch
+// is waiting, until ctx is cancelled. It then makes a final flush before
+// returning. Run it in its own goroutine:
+//
+//	go exposures.Run(ctx)
+func (l *Logger) Run(ctx context.Context) {
+	ticker := time.NewTicker(l.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			l.flush(context.WithoutCancel(ctx))
+			return
+		case <-ticker.C:
+			l.flush(ctx)
+		case <-l.full:
+			l.flush(ctx)
+		}
+	}
+}
+
+func (l *Logger) flush(ctx context.Context) {
+	if err := l.Flush(ctx); err != nil && l.config.OnError != nil {
+		l.config.OnError(err)
+	}
+}
+
+// Pending returns the number of events waiting to be written.
+func (l *Logger) Pending() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.pending)
+}
+
+// Dropped returns the number of events discarded because MaxPending was
+// reached.
+func (l *Logger) Dropped() int64 {
+	return l.dropped.Load()
+}
@@ -0,0 +1,224 @@
+package exposure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	featureflag "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+)
+
+func newTestManager(t *testing.T, hook featureflag.Hook) *featureflag.Manager {
+	t.Helper()
+	provider, err := featureflag.NewStaticProviderFromFlags(
+		featureflag.Flag{Name: "new-checkout", Enabled: true},
+		featureflag.Flag{
+			Name:    "checkout-experiment",
+			Enabled: true,
+			Variants: []featureflag.Variant{
+				{Name: "control", Value: "v1", Weight: 50},
+				{Name: "treatment", Value: "v2", Weight: 50},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewStaticProviderFromFlags() failed: %v", err)
+	}
+	return featureflag.New(provider, featureflag.WithHook(hook))
+}
+
+func session(user, id string) featureflag.EvalContext {
+	return featureflag.EvalContext{UserID: user, Attributes: map[string]string{"session_id": id}}
+}
+
+func TestLogger_Dedupe(t *testing.T) {
+	sink := NewMemorySink()
+	logger := NewLogger(sink, Config{})
+	clock := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	logger.now = func() time.Time { return clock }
+	m := newTestManager(t, logger)
+	ctx := context.Background()
+
+	m.StringVariant(ctx, "checkout-experiment", session("u1", "s1"), "v1")
+	m.StringVariant(ctx, "checkout-experiment", session("u1", "s1"), "v1") // same session: deduplicated
+	m.StringVariant(ctx, "checkout-experiment", session("u1", "s2"), "v1") // new session
+	m.IsEnabledFor(ctx, "new-checkout", session("u2", "s3"))
+	m.IsEnabledFor(ctx, "new-checkout", featureflag.EvalContext{}) // anonymous: not an exposure
+	m.IsEnabledFor(ctx, "missing", session("u2", "s3"))            // undefined: not an exposure
+
+	if got := logger.Pending(); got != 3 {
+		t.Fatalf("Pending() = %d, want 3", got)
+	}
+	if err := logger.Flush(ctx); err != nil {
+		t.Fatalf("Flush() unexpected error: %v", err)
+	}
+
+	events := sink.Events()
+	if len(events) != 3 {
+		t.Fatalf("Events() = %+v, want 3", events)
+	}
+	first := events[0]
+	if first.Flag != "checkout-experiment" || first.UserID != "u1" || first.SessionID != "s1" ||
+		first.Variant == "" || !first.Enabled || !first.Timestamp.Equal(clock) {
+		t.Errorf("events[0] = %+v", first)
+	}
+	if events[2].Flag != "new-checkout" || events[2].Variant != "" {
+		t.Errorf("events[2] = %+v", events[2])
+	}
+	if logger.Pending() != 0 {
+		t.Errorf("Pending() after Flush = %d, want 0", logger.Pending())
+	}
+}
+
+func TestLogger_DedupeAcrossAccessors(t *testing.T) {
+	sink := NewMemorySink()
+	logger := NewLogger(sink, Config{})
+	m := newTestManager(t, logger)
+	ctx := context.Background()
+
+	m.IsEnabledFor(ctx, "checkout-experiment", session("u1", "s1"))
+	m.StringVariant(ctx, "checkout-experiment", session("u1", "s1"), "v1")
+	m.Evaluate(ctx, "checkout-experiment", session("u1", "s1"))
+	logger.Flush(ctx)
+
+	events := sink.Events()
+	if len(events) != 1 || events[0].Variant == "" {
+		t.Errorf("Events() = %+v, want one exposure naming the variant served", events)
+	}
+}
+
+func TestLogger_Config(t *testing.T) {
+	sink := NewMemorySink()
+	logger := NewLogger(sink, Config{
+		SessionAttribute: "visit",
+		Filter: func(eval featureflag.Evaluation) bool {
+			return eval.Variant != "" // only experiments
+		},
+	})
+	m := newTestManager(t, logger)
+	ctx := context.Background()
+	visit := featureflag.EvalContext{UserID: "u1", Attributes: map[string]string{"visit": "v9"}}
+
+	m.IsEnabledFor(ctx, "new-checkout", visit)
+	m.StringVariant(ctx, "checkout-experiment", visit, "v1")
+	logger.Flush(ctx)
+
+	events := sink.Events()
+	if len(events) != 1 || events[0].SessionID != "v9" {
+		t.Errorf("Events() = %+v, want one experiment exposure in session v9", events)
+	}
+}
+
+// failingSink fails until it is healed. If during is set, it is called
+// while each batch is being written.
+type failingSink struct {
+	MemorySink
+	failing bool
+	during  func()
+}
+
+func (s *failingSink) Write(ctx context.Context, events []Event) error {
+	if s.during != nil {
+		s.during()
+	}
+	if s.failing {
+		return errors.New("sink unavailable")
+	}
+	return s.MemorySink.Write(ctx, events)
+}
+
+func TestLogger_BatchingAndFailures(t *testing.T) {
+	sink := &failingSink{failing: true}
+	logger := NewLogger(sink, Config{BatchSize: 2, MaxPending: 3})
+	m := newTestManager(t, logger)
+	ctx := context.Background()
+
+	for _, user := range []string{"u1", "u2", "u3", "u4"} {
+		m.IsEnabledFor(ctx, "new-checkout", session(user, "s"))
+	}
+	if logger.Dropped() != 1 || logger.Pending() != 3 {
+		t.Fatalf("Dropped() = %d, Pending() = %d; want 1 and 3", logger.Dropped(), logger.Pending())
+	}
+
+	if err := logger.Flush(ctx); err == nil {
+		t.Fatal("Flush() to failing sink succeeded")
+	}
+	if logger.Pending() != 3 {
+		t.Errorf("Pending() after failed flush = %d, want events kept", logger.Pending())
+	}
+
+	sink.failing = false
+	if err := logger.Flush(ctx); err != nil {
+		t.Fatalf("Flush() unexpected error: %v", err)
+	}
+	if got := len(sink.Events()); got != 3 {
+		t.Errorf("Events() = %d, want 3", got)
+	}
+}
+
+func TestLogger_RequeueOverflowIsLoggedAgain(t *testing.T) {
+	sink := &failingSink{failing: true}
+	logger := NewLogger(sink, Config{BatchSize: 10, MaxPending: 2})
+	m := newTestManager(t, logger)
+	ctx := context.Background()
+
+	m.IsEnabledFor(ctx, "new-checkout", session("u1", "s"))
+	m.IsEnabledFor(ctx, "new-checkout", session("u2", "s"))
+	// u3 is queued while the batch is being written; when the write fails
+	// the batch is requeued ahead of it and u3 no longer fits.
+	sink.during = func() { m.IsEnabledFor(ctx, "new-checkout", session("u3", "s")) }
+	if err := logger.Flush(ctx); err == nil {
+		t.Fatal("Flush() to failing sink succeeded")
+	}
+	if logger.Pending() != 2 || logger.Dropped() != 1 {
+		t.Fatalf("Pending() = %d, Dropped() = %d; want 2 and 1", logger.Pending(), logger.Dropped())
+	}
+
+	sink.failing, sink.during = false, nil
+	if err := logger.Flush(ctx); err != nil {
+		t.Fatalf("Flush() unexpected error: %v", err)
+	}
+
+	// The dropped exposure was not remembered, so it is logged next time.
+	m.IsEnabledFor(ctx, "new-checkout", session("u3", "s"))
+	if err := logger.Flush(ctx); err != nil {
+		t.Fatalf("Flush() unexpected error: %v", err)
+	}
+	events := sink.Events()
+	if len(events) != 3 || events[2].UserID != "u3" {
+		t.Errorf("Events() = %+v, want u1, u2 and then u3", events)
+	}
+}
+
+func TestLogger_Run(t *testing.T) {
+	sink := NewMemorySink()
+	logger := NewLogger(sink, Config{BatchSize: 2, FlushInterval: time.Hour})
+	m := newTestManager(t, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		logger.Run(ctx)
+		close(done)
+	}()
+
+	// A full batch is written without waiting for the interval.
+	m.IsEnabledFor(ctx, "new-checkout", session("u1", "s"))
+	m.IsEnabledFor(ctx, "new-checkout", session("u2", "s"))
+	deadline := time.Now().Add(2 * time.Second)
+	for len(sink.Events()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := len(sink.Events()); got != 2 {
+		t.Fatalf("Events() = %d after full batch, want 2", got)
+	}
+
+	// Cancelling flushes what is left.
+	m.IsEnabledFor(ctx, "new-checkout", session("u3", "s"))
+	cancel()
+	<-done
+	if got := len(sink.Events()); got != 3 {
+		t.Errorf("Events() = %d after Run returned, want 3", got)
+	}
+}
@@ -0,0 +1,30 @@
+package exposure
+
+import (
+	"fmt"
+
+	"github.com/JWindy92/obelisk-platform/libs/store"
+	"github.com/JWindy92/obelisk-platform/libs/store/migrate"
+)
+
+// Migrations returns the migration set that creates the exposure table
+// named by config.TableName, for registration with a migrate.Migrator.
+func Migrations(dialect store.Dialect, config StoreConfig) migrate.Set {
+	tableName := config.TableName
+	if tableName == "" {
+		tableName = DefaultStoreConfig().TableName
+	}
+
+	return migrate.CreateTable("exposure", tableName, fmt.Sprintf(`
+		CREATE TABLE %[1]s (
+			flag TEXT NOT NULL,
+			variant TEXT NOT NULL DEFAULT '',
+			enabled BOOLEAN NOT NULL,
+			user_id TEXT NOT NULL DEFAULT '',
+			session_id TEXT NOT NULL DEFAULT '',
+			exposed_at %[2]s NOT NULL
+		);
+		CREATE INDEX %[1]s_flag_exposed_at_idx ON %[1]s (flag, exposed_at);
+		CREATE INDEX %[1]s_user_id_idx ON %[1]s (user_id);
+	`, tableName, dialect.TimestampType()))
+}
@@ -0,0 +1,73 @@
+package exposure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// MemorySink keeps events in memory. It is meant for tests and debugging.
+type MemorySink struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+var _ Sink = (*MemorySink)(nil)
+
+// NewMemorySink creates an empty in-memory sink.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+// Write appends the events.
+func (s *MemorySink) Write(ctx context.Context, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, events...)
+	return nil
+}
+
+// Events returns a copy of every event written so far.
+func (s *MemorySink) Events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Event(nil), s.events...)
+}
+
+// JSONLSink writes events as JSON Lines, one event object per line, the
+// format most log shippers and warehouse loaders ingest directly. For a
+// file, open it with os.O_APPEND so concurrent processes don't interleave
+// partial lines.
+type JSONLSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+var _ Sink = (*JSONLSink)(nil)
+
+// NewJSONLSink creates a sink writing to w.
+func NewJSONLSink(w io.Writer) *JSONLSink {
+	return &JSONLSink{w: w}
+}
+
+// Write encodes the batch and writes it to the underlying writer in one
+// call.
+func (s *JSONLSink) Write(ctx context.Context, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			return fmt.Errorf("failed to encode exposure event: %w", err)
+		}
+	}
+	if _, err := s.w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write exposure events: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package exposure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JWindy92/obelisk-platform/libs/internal/storetest"
+	"github.com/JWindy92/obelisk-platform/libs/store"
+)
+
+var testEvents = []Event{
+	{Flag: "checkout-experiment", Variant: "treatment", Enabled: true, UserID: "u1", SessionID: "s1", Timestamp: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)},
+	{Flag: "new-checkout", Enabled: false, UserID: "u2", Timestamp: time.Date(2025, 3, 1, 12, 0, 1, 0, time.UTC)},
+}
+
+func TestJSONLSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONLSink(&buf)
+	if err := sink.Write(context.Background(), testEvents); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("wrote %d lines, want 2:\n%s", len(lines), &buf)
+	}
+	var got Event
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if got != testEvents[0] {
+		t.Errorf("line 0 = %+v, want %+v", got, testEvents[0])
+	}
+	if !strings.Contains(lines[1], `"user_id":"u2"`) || strings.Contains(lines[1], "session_id") {
+		t.Errorf("line 1 = %s", lines[1])
+	}
+}
+
+// countingWriter counts the Write calls made to it.
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestJSONLSink_SingleWrite(t *testing.T) {
+	var w countingWriter
+	sink := NewJSONLSink(&w)
+
+	// A default-sized batch is well over any internal buffer size.
+	events := make([]Event, DefaultConfig().BatchSize)
+	for i := range events {
+		events[i] = testEvents[i%len(testEvents)]
+	}
+	if err := sink.Write(context.Background(), events); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+	if w.writes != 1 {
+		t.Errorf("Write() made %d writes for %d bytes, want 1", w.writes, w.Len())
+	}
+	if lines := strings.Count(w.String(), "\n"); lines != len(events) {
+		t.Errorf("wrote %d lines, want %d", lines, len(events))
+	}
+}
+
+func TestStoreSink(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultStoreConfig()
+	st := storetest.SQLite(t, Migrations(store.DialectSQLite, config))
+
+	// More events than fit in one INSERT statement.
+	var events []Event
+	for i := 0; i < insertRows+5; i++ {
+		events = append(events, testEvents[i%len(testEvents)])
+	}
+	sink := NewStoreSink(st, config)
+	if err := sink.Write(ctx, events); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+
+	var count int
+	if err := st.DB().QueryRowContext(ctx, "SELECT COUNT(*) FROM feature_exposures").Scan(&count); err != nil {
+		t.Fatalf("count query failed: %v", err)
+	}
+	if count != len(events) {
+		t.Errorf("stored %d events, want %d", count, len(events))
+	}
+
+	var got Event
+	row := st.DB().QueryRowContext(ctx,
+		"SELECT flag, variant, enabled, user_id, session_id, exposed_at FROM feature_exposures WHERE variant = 'treatment' LIMIT 1")
+	if err := row.Scan(&got.Flag, &got.Variant, &got.Enabled, &got.UserID, &got.SessionID, &got.Timestamp); err != nil {
+		t.Fatalf("Scan() failed: %v", err)
+	}
+	if got.Flag != testEvents[0].Flag || got.UserID != "u1" || got.SessionID != "s1" || !got.Enabled || !got.Timestamp.Equal(testEvents[0].Timestamp) {
+		t.Errorf("stored event = %+v, want %+v", got, testEvents[0])
+	}
+}
@@ -0,0 +1,80 @@
+package exposure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/JWindy92/obelisk-platform/libs/store"
+)
+
+// StoreConfig holds configuration options for a StoreSink.
+type StoreConfig struct {
+	// TableName specifies the database table name for exposure events.
+	// Defaults to "feature_exposures" if not specified.
+	TableName string
+}
+
+// DefaultStoreConfig returns a StoreConfig with sensible defaults.
+func DefaultStoreConfig() StoreConfig {
+	return StoreConfig{
+		TableName: "feature_exposures",
+	}
+}
+
+// StoreSink writes events to a database table, one transaction per batch.
+// Create the table with the set returned by Migrations.
+type StoreSink struct {
+	store     store.Store
+	tableName string
+}
+
+var _ Sink = (*StoreSink)(nil)
+
+// NewStoreSink creates a sink that inserts events through st.
+func NewStoreSink(st store.Store, config StoreConfig) *StoreSink {
+	if config.TableName == "" {
+		config.TableName = DefaultStoreConfig().TableName
+	}
+	return &StoreSink{
+		store:     st,
+		tableName: config.TableName,
+	}
+}
+
+// insertRows caps the rows per INSERT statement, keeping the number of
+// bind parameters well under every driver's limit.
+const insertRows = 100
+
+// Write inserts the batch in one transaction, using multi-row INSERT
+// statements.
+func (s *StoreSink) Write(ctx context.Context, events []Event) error {
+	return s.store.WithTx(ctx, func(ctx context.Context) error {
+		for len(events) > 0 {
+			n := min(len(events), insertRows)
+			if err := s.insert(ctx, events[:n]); err != nil {
+				return err
+			}
+			events = events[n:]
+		}
+		return nil
+	})
+}
+
+func (s *StoreSink) insert(ctx context.Context, events []Event) error {
+	rows := make([]string, len(events))
+	args := make([]any, 0, len(events)*6)
+	for i, e := range events {
+		rows[i] = "(?, ?, ?, ?, ?, ?)"
+		args = append(args, e.Flag, e.Variant, e.Enabled, e.UserID, e.SessionID, e.Timestamp)
+	}
+	query := s.store.Dialect().Rebind(fmt.Sprintf(
+		"INSERT INTO %s (flag, variant, enabled, user_id, session_id, exposed_at) VALUES %s",
+		s.tableName, strings.Join(rows, ", "),
+	))
+
+	if _, err := store.QuerierFrom(ctx, s.store).ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to insert exposure events: %w", err)
+	}
+	return nil
+}
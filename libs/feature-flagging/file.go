@@ -0,0 +1,176 @@
+package featureflag
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// FileConfig holds configuration options for a FileProvider.
+type FileConfig struct {
+	// Path is the flag definition file to load.
+	Path string
+
+	// Format of the file. Inferred from the extension if not specified.
+	Format Format
+
+	// PollInterval is how often RunWatcher checks the file for changes.
+	// Defaults to 5 seconds if not specified.
+	PollInterval time.Duration
+
+	// OnReloadError is called by RunWatcher when a changed file fails to
+	// load. The previous flags stay in effect. May be nil.
+	OnReloadError func(error)
+}
+
+// FileProvider serves flags from a JSON, YAML or TOML definition file (see
+// ParseFlags for the schema). RunWatcher polls the file and hot-reloads it;
+// the flag set is swapped atomically, and a file that fails to parse or
+// validate is ignored so the last good config stays in effect. Changes
+// picked up by a reload are reported to watchers registered with Watch.
+type FileProvider struct {
+	Broadcaster
+
+	config FileConfig
+	flags  atomic.Pointer[map[string]Flag]
+
+	// mu serializes reloads; lookups never take it.
+	mu      sync.Mutex
+	content []byte
+	modTime time.Time
+	size    int64
+}
+
+var (
+	_ Watchable = (*FileProvider)(nil)
+	_ Lister    = (*FileProvider)(nil)
+)
+
+// NewFileProvider creates a provider and loads the file. It fails if the
+// initial load fails, since there is no previous config to fall back to.
+func NewFileProvider(config FileConfig) (*FileProvider, error) {
+	if config.Format == "" {
+		format, err := FormatFromPath(config.Path)
+		if err != nil {
+			return nil, err
+		}
+		config.Format = format
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 5 * time.Second
+	}
+
+	p := &FileProvider{config: config}
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// IsEnabled checks if a feature flag is enabled.
+// Returns false if the flag doesn't exist (fail-safe default).
+func (p *FileProvider) IsEnabled(flagName string) bool {
+	flag, _ := p.Flag(flagName)
+	return flag.Enabled
+}
+
+// Lookup reports whether a feature flag is enabled and whether it exists.
+func (p *FileProvider) Lookup(flagName string) (enabled, exists bool) {
+	flag, exists := p.Flag(flagName)
+	return flag.Enabled, exists
+}
+
+// Flag returns the definition of a flag and whether it exists.
+func (p *FileProvider) Flag(flagName string) (Flag, bool) {
+	flag, exists := (*p.flags.Load())[flagName]
+	return flag, exists
+}
+
+// Flags returns every defined flag, sorted by name.
+func (p *FileProvider) Flags() []Flag {
+	return sortedFlags(*p.flags.Load())
+}
+
+// Reload reads and parses the file, replacing the flag set on success.
+// On error the previous flag set is kept.
+func (p *FileProvider) Reload() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(p.config.Path)
+	if err != nil {
+		return fmt.Errorf("failed to stat flag file: %w", err)
+	}
+	return p.load(info)
+}
+
+// RunWatcher polls the file every PollInterval until ctx is cancelled and
+// reloads it when its size or modification time changes. Reload failures
+// are passed to OnReloadError. Run it in its own goroutine:
+//
+//	go flags.RunWatcher(ctx)
+func (p *FileProvider) RunWatcher(ctx context.Context) {
+	ticker := time.NewTicker(p.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.reloadIfChanged(); err != nil && p.config.OnReloadError != nil {
+				p.config.OnReloadError(err)
+			}
+		}
+	}
+}
+
+func (p *FileProvider) reloadIfChanged() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(p.config.Path)
+	if err != nil {
+		return fmt.Errorf("failed to stat flag file: %w", err)
+	}
+	if info.ModTime().Equal(p.modTime) && info.Size() == p.size {
+		return nil
+	}
+	return p.load(info)
+}
+
+// load parses the file and swaps in its flags. The caller must hold p.mu.
+func (p *FileProvider) load(info os.FileInfo) error {
+	data, err := os.ReadFile(p.config.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read flag file: %w", err)
+	}
+
+	// Remember the stat even when the content is rejected, so a broken file
+	// is reported once rather than on every poll.
+	p.modTime, p.size = info.ModTime(), info.Size()
+	if p.flags.Load() != nil && bytes.Equal(data, p.content) {
+		return nil
+	}
+
+	flags, err := ParseFlags(data, p.config.Format)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", p.config.Path, err)
+	}
+
+	next := make(map[string]Flag, len(flags))
+	for _, flag := range flags {
+		next[flag.Name] = flag
+	}
+	previous := p.flags.Swap(&next)
+	p.content = data
+
+	if previous != nil {
+		p.Publish(Diff(*previous, next)...)
+	}
+	return nil
+}
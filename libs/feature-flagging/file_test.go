@@ -0,0 +1,317 @@
+package featureflag
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const yamlFlags = `
+flags:
+  new-checkout:
+    enabled: true
+    rules:
+      - attribute: tenant
+        operator: in
+        values: [acme, globex]
+    rollout:
+      percentage: 10
+  max-batch:
+    enabled: true
+    variants:
+      - name: large
+        value: 500
+`
+
+const jsonFlags = `{
+  "flags": {
+    "new-checkout": {
+      "enabled": true,
+      "rules": [{"attribute": "tenant", "operator": "in", "values": ["acme", "globex"]}],
+      "rollout": {"percentage": 10}
+    },
+    "max-batch": {
+      "enabled": true,
+      "variants": [{"name": "large", "value": 500}]
+    }
+  }
+}`
+
+const tomlFlags = `
+[flags.new-checkout]
+enabled = true
+rollout = { percentage = 10 }
+
+[[flags.new-checkout.rules]]
+attribute = "tenant"
+operator = "in"
+values = ["acme", "globex"]
+
+[flags.max-batch]
+enabled = true
+
+[[flags.max-batch.variants]]
+name = "large"
+value = 500
+`
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format Format
+	}{
+		{name: "yaml", data: yamlFlags, format: FormatYAML},
+		{name: "json", data: jsonFlags, format: FormatJSON},
+		{name: "toml", data: tomlFlags, format: FormatTOML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags, err := ParseFlags([]byte(tt.data), tt.format)
+			if err != nil {
+				t.Fatalf("ParseFlags() unexpected error: %v", err)
+			}
+			if len(flags) != 2 {
+				t.Fatalf("ParseFlags() returned %d flags, want 2", len(flags))
+			}
+
+			batch, checkout := flags[0], flags[1]
+			if batch.Name != "max-batch" || len(batch.Variants) != 1 {
+				t.Errorf("max-batch = %+v", batch)
+			}
+			if checkout.Name != "new-checkout" || !checkout.Enabled || len(checkout.Rules) != 1 ||
+				checkout.Rules[0].Operator != OpIn || checkout.Rollout == nil || checkout.Rollout.Percentage != 10 {
+				t.Errorf("new-checkout = %+v", checkout)
+			}
+
+			m := New(newStaticProvider(t, flags...))
+			if got := m.IntVariant(context.Background(), "max-batch", EvalContext{}, 0); got != 500 {
+				t.Errorf("IntVariant() = %d, want 500", got)
+			}
+		})
+	}
+}
+
+func TestParseFlags_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name:    "unknown field",
+			data:    "flags:\n  f:\n    enabeld: true\n",
+			wantErr: "unknown field",
+		},
+		{
+			name:    "unknown top-level field",
+			data:    "flag:\n  f:\n    enabled: true\n",
+			wantErr: "unknown field",
+		},
+		{
+			name:    "unknown operator",
+			data:    "flags:\n  f:\n    rules:\n      - {attribute: tenant, operator: like, values: [a]}\n",
+			wantErr: "unknown operator",
+		},
+		{
+			name:    "rollout out of range",
+			data:    "flags:\n  f:\n    rollout: {percentage: 150}\n",
+			wantErr: "between 0 and 100",
+		},
+		{
+			name:    "undefined default variant",
+			data:    "flags:\n  f:\n    default_variant: b\n    variants: [{name: a, value: 1}]\n",
+			wantErr: "not defined",
+		},
+		{
+			name:    "mismatched name",
+			data:    "flags:\n  f:\n    name: g\n",
+			wantErr: "mismatched name",
+		},
+		{
+			name:    "self prerequisite",
+			data:    "flags:\n  f:\n    prerequisites: [f]\n",
+			wantErr: "depends on itself",
+		},
+		{
+			name:    "dependency cycle",
+			data:    "flags:\n  a:\n    prerequisites: [b]\n  b:\n    kill_switch: a\n",
+			wantErr: "a -> b -> a",
+		},
+		{
+			name:    "malformed yaml",
+			data:    "flags: [",
+			wantErr: "YAML",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFlags([]byte(tt.data), FormatYAML)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseFlags() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := map[string]Format{
+		"flags.json": FormatJSON,
+		"flags.yaml": FormatYAML,
+		"flags.YML":  FormatYAML,
+		"flags.toml": FormatTOML,
+	}
+	for path, want := range tests {
+		if got, err := FormatFromPath(path); err != nil || got != want {
+			t.Errorf("FormatFromPath(%q) = %q, %v; want %q", path, got, err, want)
+		}
+	}
+	if _, err := FormatFromPath("flags.ini"); err == nil {
+		t.Error("FormatFromPath(flags.ini) expected error, got nil")
+	}
+}
+
+// writeFlagFile writes data and bumps the modification time so pollers see
+// the change even on filesystems with coarse timestamps.
+func writeFlagFile(t *testing.T, path, data string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Chtimes() failed: %v", err)
+	}
+}
+
+func TestFileProvider_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	base := time.Now()
+	writeFlagFile(t, path, "flags:\n  a:\n    enabled: true\n", base)
+
+	p, err := NewFileProvider(FileConfig{Path: path})
+	if err != nil {
+		t.Fatalf("NewFileProvider() unexpected error: %v", err)
+	}
+	if !p.IsEnabled("a") {
+		t.Error("IsEnabled(a) = false, want true")
+	}
+
+	writeFlagFile(t, path, "flags:\n  a:\n    enabled: false\n  b:\n    enabled: true\n", base.Add(time.Second))
+	if err := p.Reload(); err != nil {
+		t.Fatalf("Reload() unexpected error: %v", err)
+	}
+	if p.IsEnabled("a") || !p.IsEnabled("b") {
+		t.Error("Reload() did not apply the new flags")
+	}
+
+	writeFlagFile(t, path, "flags:\n  a:\n    enabeld: true\n", base.Add(2*time.Second))
+	if err := p.Reload(); err == nil {
+		t.Error("Reload() expected error for invalid file, got nil")
+	}
+	if !p.IsEnabled("b") {
+		t.Error("Reload() failure discarded the last good config")
+	}
+}
+
+func TestNewFileProvider_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := NewFileProvider(FileConfig{Path: filepath.Join(dir, "missing.yaml")}); err == nil {
+		t.Error("NewFileProvider() expected error for missing file, got nil")
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	writeFlagFile(t, bad, `{"flags": {"a": {"enabled": "yes"}}}`, time.Now())
+	if _, err := NewFileProvider(FileConfig{Path: bad}); err == nil {
+		t.Error("NewFileProvider() expected error for invalid file, got nil")
+	}
+}
+
+func TestFileProvider_RunWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.toml")
+	base := time.Now()
+	writeFlagFile(t, path, "[flags.a]\nenabled = false\n", base)
+
+	var mu sync.Mutex
+	var reloadErrs []error
+	p, err := NewFileProvider(FileConfig{
+		Path:         path,
+		PollInterval: 5 * time.Millisecond,
+		OnReloadError: func(err error) {
+			mu.Lock()
+			reloadErrs = append(reloadErrs, err)
+			mu.Unlock()
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewFileProvider() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.RunWatcher(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	waitFor := func(cond func() bool) bool {
+		for i := 0; i < 400; i++ {
+			if cond() {
+				return true
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		return false
+	}
+
+	writeFlagFile(t, path, "[flags.a]\nenabled = true\n", base.Add(time.Second))
+	if !waitFor(func() bool { return p.IsEnabled("a") }) {
+		t.Fatal("RunWatcher() did not pick up the change")
+	}
+
+	writeFlagFile(t, path, "[flags.a]\nenabled = \n", base.Add(2*time.Second))
+	if !waitFor(func() bool { mu.Lock(); defer mu.Unlock(); return len(reloadErrs) > 0 }) {
+		t.Fatal("OnReloadError was not called for an invalid file")
+	}
+	if !p.IsEnabled("a") {
+		t.Error("invalid reload discarded the last good config")
+	}
+
+	// A broken file is reported once, not on every poll.
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	if len(reloadErrs) != 1 {
+		t.Errorf("OnReloadError called %d times, want 1", len(reloadErrs))
+	}
+	mu.Unlock()
+}
+
+func TestMarshalFlags(t *testing.T) {
+	flags := []Flag{
+		{Name: "a", Enabled: true, Rollout: &Rollout{Percentage: 10}},
+		{Name: "b", Prerequisites: []string{"a"}, Tags: []string{"payments"}},
+	}
+
+	data, err := MarshalFlags(flags)
+	if err != nil {
+		t.Fatalf("MarshalFlags() unexpected error: %v", err)
+	}
+	got, err := ParseFlags(data, FormatJSON)
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, flags) {
+		t.Errorf("round trip = %+v, want %+v", got, flags)
+	}
+}
@@ -1,7 +1,254 @@
 package featureflag
 
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+)
+
+// ErrInvalidFlag is returned, wrapped, when a flag definition fails
+// validation. Dependency cycles are reported with ErrDependencyCycle.
+var ErrInvalidFlag = errors.New("invalid feature flag")
+
 // Flag represents a feature flag with a name and enabled state.
+//
+// Enabled is the master switch: a disabled flag is off for everyone. When a
+// flag is enabled, Rules and Rollout can narrow it down to specific users;
+// see Manager.IsEnabledFor. Multivariate flags additionally carry Variants,
+// read with Manager.StringVariant and friends. Prerequisites and a
+// KillSwitch make the flag depend on other flags, and a Schedule turns it
+// on and off over time.
 type Flag struct {
-	Name    string
-	Enabled bool
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+
+	// Rules restrict the flag to evaluation contexts that match every rule.
+	// A flag without rules applies to everyone.
+	Rules []Rule `json:"rules,omitempty"`
+
+	// Rollout, when set, enables the flag for a deterministic percentage of
+	// the contexts that pass Rules.
+	Rollout *Rollout `json:"rollout,omitempty"`
+
+	// Variants are the values a multivariate flag can serve. When the flag
+	// is on for a context, one variant is picked by weight; with no weights
+	// set, DefaultVariant (or else the first variant) is served to everyone.
+	Variants []Variant `json:"variants,omitempty"`
+
+	// DefaultVariant names the variant served when the flag is off for a
+	// context. If empty, callers receive the default they passed in.
+	DefaultVariant string `json:"default_variant,omitempty"`
+
+	// Prerequisites name flags that must be on for the same evaluation
+	// context before this flag is considered. A missing prerequisite
+	// counts as off.
+	Prerequisites []string `json:"prerequisites,omitempty"`
+
+	// KillSwitch names a flag that shuts this one off: while the kill
+	// switch is on, this flag is off for everyone. Flags sharing a kill
+	// switch form a group that can be turned off at once during an incident.
+	KillSwitch string `json:"kill_switch,omitempty"`
+
+	// Schedule, when set, limits the times the flag can be on, e.g. from a
+	// launch date or during business hours.
+	Schedule *Schedule `json:"schedule,omitempty"`
+
+	// Tags label the flag for grouping and filtering. They do not affect
+	// evaluation.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// HasTag reports whether the flag is labelled with tag.
+func (f Flag) HasTag(tag string) bool {
+	return slices.Contains(f.Tags, tag)
+}
+
+// Dependencies returns the names of the flags f's evaluation depends on:
+// its prerequisites followed by its kill switch, if any. The returned slice
+// is a copy the caller may modify.
+func (f Flag) Dependencies() []string {
+	deps := slices.Clone(f.Prerequisites)
+	if f.KillSwitch != "" {
+		deps = append(deps, f.KillSwitch)
+	}
+	return deps
+}
+
+// Variant is one value of a multivariate flag.
+type Variant struct {
+	Name string `json:"name"`
+
+	// Value is the variant's payload: a string, number, bool, or any
+	// JSON-encodable value.
+	Value any `json:"value"`
+
+	// Weight is the variant's relative share of contexts in an A/B split.
+	// Variants with zero weight are only served as the DefaultVariant.
+	Weight int `json:"weight,omitempty"`
+}
+
+// Variant returns the named variant and whether the flag defines it.
+func (f Flag) Variant(name string) (Variant, bool) {
+	for _, v := range f.Variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Variant{}, false
+}
+
+// Operator is a comparison used by a targeting Rule.
+type Operator string
+
+const (
+	// OpEquals matches when the attribute equals Values[0].
+	OpEquals Operator = "equals"
+	// OpNotEquals matches when the attribute is present and differs from Values[0].
+	OpNotEquals Operator = "not_equals"
+	// OpIn matches when the attribute equals any of Values.
+	OpIn Operator = "in"
+	// OpNotIn matches when the attribute is present and equals none of Values.
+	OpNotIn Operator = "not_in"
+	// OpMatches matches when the attribute matches the regular expression Values[0].
+	OpMatches Operator = "matches"
+	// OpSemverEq matches when the attribute is the same semantic version as Values[0].
+	OpSemverEq Operator = "semver_eq"
+	// OpSemverGt matches when the attribute is a later semantic version than Values[0].
+	OpSemverGt Operator = "semver_gt"
+	// OpSemverGte matches when the attribute is the same as or later than Values[0].
+	OpSemverGte Operator = "semver_gte"
+	// OpSemverLt matches when the attribute is an earlier semantic version than Values[0].
+	OpSemverLt Operator = "semver_lt"
+	// OpSemverLte matches when the attribute is the same as or earlier than Values[0].
+	OpSemverLte Operator = "semver_lte"
+)
+
+// AttributeUserID is the attribute name that refers to EvalContext.UserID
+// in rules and rollouts.
+const AttributeUserID = "user_id"
+
+// Rule is a targeting condition on one attribute of the evaluation context.
+// A rule never matches a context that lacks the attribute.
+type Rule struct {
+	Attribute string   `json:"attribute"`
+	Operator  Operator `json:"operator"`
+	Values    []string `json:"values"`
+}
+
+// Rollout enables a flag for a percentage of evaluation contexts.
+// Contexts are bucketed by hashing the flag name with the BucketBy
+// attribute, so a given user consistently lands in the same bucket and
+// raising the percentage only ever adds users.
+type Rollout struct {
+	// Percentage of contexts that get the flag, from 0 to 100.
+	Percentage float64 `json:"percentage"`
+
+	// BucketBy is the attribute used for bucketing.
+	// Defaults to the user ID if not specified.
+	BucketBy string `json:"bucket_by,omitempty"`
+}
+
+// Validate checks that the flag definition is well formed: rules use known
+// operators with values, the rollout percentage is within 0-100, the
+// schedule has valid times, days and location, variants
+// are uniquely named with non-negative weights, and the flag does not
+// depend on itself. Use ValidateFlags to also check for dependency cycles
+// across a set of flags. Errors wrap ErrInvalidFlag.
+func (f Flag) Validate() error {
+	if err := f.validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidFlag, err)
+	}
+	return nil
+}
+
+func (f Flag) validate() error {
+	if f.Name == "" {
+		return fmt.Errorf("flag name is required")
+	}
+
+	for i, rule := range f.Rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("flag %q rule %d: %w", f.Name, i, err)
+		}
+	}
+
+	// NaN fails every comparison, so it must be rejected explicitly.
+	if f.Rollout != nil && (math.IsNaN(f.Rollout.Percentage) || f.Rollout.Percentage < 0 || f.Rollout.Percentage > 100) {
+		return fmt.Errorf("flag %q: rollout percentage must be between 0 and 100, got %v", f.Name, f.Rollout.Percentage)
+	}
+
+	if f.Schedule != nil {
+		if err := f.Schedule.validate(); err != nil {
+			return fmt.Errorf("flag %q: %w", f.Name, err)
+		}
+	}
+
+	seen := make(map[string]bool, len(f.Variants))
+	for _, v := range f.Variants {
+		if v.Name == "" {
+			return fmt.Errorf("flag %q: variant name is required", f.Name)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("flag %q: duplicate variant %q", f.Name, v.Name)
+		}
+		if v.Weight < 0 {
+			return fmt.Errorf("flag %q: variant %q has negative weight", f.Name, v.Name)
+		}
+		seen[v.Name] = true
+	}
+	if f.DefaultVariant != "" && !seen[f.DefaultVariant] {
+		return fmt.Errorf("flag %q: default variant %q is not defined", f.Name, f.DefaultVariant)
+	}
+
+	for _, dep := range f.Dependencies() {
+		if dep == "" {
+			return fmt.Errorf("flag %q: prerequisite name is required", f.Name)
+		}
+		if dep == f.Name {
+			return fmt.Errorf("%w: flag %q depends on itself", ErrDependencyCycle, f.Name)
+		}
+	}
+	for _, tag := range f.Tags {
+		if tag == "" {
+			return fmt.Errorf("flag %q: tags must not be empty", f.Name)
+		}
+	}
+	return nil
+}
+
+func (r Rule) validate() error {
+	if r.Attribute == "" {
+		return fmt.Errorf("attribute is required")
+	}
+	if len(r.Values) == 0 {
+		return fmt.Errorf("at least one value is required")
+	}
+
+	switch r.Operator {
+	case OpEquals, OpNotEquals, OpIn, OpNotIn:
+		return nil
+	case OpMatches:
+		if _, err := compileCached(r.Values[0]); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		return nil
+	case OpSemverEq, OpSemverGt, OpSemverGte, OpSemverLt, OpSemverLte:
+		if _, ok := parseSemver(r.Values[0]); !ok {
+			return fmt.Errorf("invalid semantic version %q", r.Values[0])
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown operator %q", r.Operator)
+}
+
+// sortedFlags returns the values of flags sorted by name.
+func sortedFlags(flags map[string]Flag) []Flag {
+	list := make([]Flag, 0, len(flags))
+	for _, flag := range flags {
+		list = append(list, flag)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
 }
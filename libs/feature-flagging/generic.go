@@ -0,0 +1,127 @@
+package featureflag
+
+import (
+	"context"
+	"sync"
+)
+
+// Select returns the result of enabled if the flag is on, otherwise the
+// result of fallback. It is the type-safe counterpart of Manager.Select:
+//
+//	svc := featureflag.Select(ff, "user-service-v2",
+//	    func() UserService { return NewUserServiceV2(repo) },
+//	    func() UserService { return NewUserServiceV1(repo) },
+//	)
+func Select[T any](m *Manager, flagName string, enabled, fallback func() T) T {
+	if m.IsEnabled(flagName) {
+		return enabled()
+	}
+	return fallback()
+}
+
+// SelectCtx is Select for the evaluation context attached to ctx, honoring
+// overrides and the request cache on ctx like Manager.IsEnabledCtx.
+func SelectCtx[T any](ctx context.Context, m *Manager, flagName string, enabled, fallback func() T) T {
+	if m.IsEnabledCtx(ctx, flagName) {
+		return enabled()
+	}
+	return fallback()
+}
+
+// Switch returns the result of the case keyed by the name of the variant
+// the flag serves, or of fallback if the flag is missing, serves no variant
+// or has no matching case. Like IsEnabled, it evaluates the flag without an
+// evaluation context.
+//
+//	algo := featureflag.Switch(ff, "checkout-algorithm", map[string]func() Checkout{
+//	    "v2": func() Checkout { return NewCheckoutV2() },
+//	    "v3": func() Checkout { return NewCheckoutV3() },
+//	}, func() Checkout { return NewCheckoutV1() })
+func Switch[T any](m *Manager, flagName string, cases map[string]func() T, fallback func() T) T {
+	return SwitchCtx(context.Background(), m, flagName, cases, fallback)
+}
+
+// SwitchCtx is Switch for the evaluation context attached to ctx, so the
+// variant is bucketed by the caller's user or tenant.
+func SwitchCtx[T any](ctx context.Context, m *Manager, flagName string, cases map[string]func() T, fallback func() T) T {
+	if build, ok := cases[variantName(ctx, m, flagName)]; ok {
+		return build()
+	}
+	return fallback()
+}
+
+// Lazy memoizes a value chosen by a flag and rebuilds it only when the flag's
+// result changes, so long-lived components can follow a flag without being
+// rebuilt on every call. It is safe for concurrent use.
+type Lazy[T any] struct {
+	key   func(ctx context.Context) string
+	build func(key string) T
+
+	mu      sync.Mutex
+	built   bool
+	lastKey string
+	value   T
+}
+
+// NewLazy returns a Lazy that holds the result of enabled while the flag is
+// on and of fallback while it is off.
+func NewLazy[T any](m *Manager, flagName string, enabled, fallback func() T) *Lazy[T] {
+	return &Lazy[T]{
+		key: func(ctx context.Context) string {
+			if m.IsEnabledCtx(ctx, flagName) {
+				return "on"
+			}
+			return "off"
+		},
+		build: func(key string) T {
+			if key == "on" {
+				return enabled()
+			}
+			return fallback()
+		},
+	}
+}
+
+// NewLazySwitch returns a Lazy that holds the result of the case matching
+// the flag's current variant, as chosen by Switch.
+func NewLazySwitch[T any](m *Manager, flagName string, cases map[string]func() T, fallback func() T) *Lazy[T] {
+	return &Lazy[T]{
+		key: func(ctx context.Context) string { return variantName(ctx, m, flagName) },
+		build: func(key string) T {
+			if build, ok := cases[key]; ok {
+				return build()
+			}
+			return fallback()
+		},
+	}
+}
+
+// Get evaluates the flag and returns the memoized value, building a new one
+// first if this is the first call or the flag's result has changed.
+func (l *Lazy[T]) Get() T {
+	return l.GetCtx(context.Background())
+}
+
+// GetCtx is Get with the flag evaluated for the evaluation context attached
+// to ctx. The memoized value is shared by all callers, so it is rebuilt
+// whenever the result differs from the previous call's; use it for flags
+// whose result rarely differs between callers, such as tenant-wide ones.
+func (l *Lazy[T]) GetCtx(ctx context.Context) T {
+	key := l.key(ctx)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.built || key != l.lastKey {
+		l.value = l.build(key)
+		l.lastKey = key
+		l.built = true
+	}
+	return l.value
+}
+
+// variantName returns the name of the variant the flag serves to the
+// evaluation context attached to ctx, or "" if it serves none.
+func variantName(ctx context.Context, m *Manager, flagName string) string {
+	v, _ := m.VariantCtx(ctx, flagName)
+	return v.Name
+}
@@ -0,0 +1,149 @@
+package featureflag
+
+import (
+	"sync"
+	"testing"
+)
+
+type greeter interface{ Greet() string }
+
+type v1Greeter struct{}
+
+func (v1Greeter) Greet() string { return "hello" }
+
+type v2Greeter struct{}
+
+func (v2Greeter) Greet() string { return "hi there" }
+
+func TestSelect(t *testing.T) {
+	m := New(NewStaticProvider(map[string]bool{"greeter-v2": true}))
+
+	g := Select(m, "greeter-v2",
+		func() greeter { return v2Greeter{} },
+		func() greeter { return v1Greeter{} },
+	)
+	if g.Greet() != "hi there" {
+		t.Errorf("Select() = %T, want v2Greeter", g)
+	}
+
+	n := Select(m, "missing", func() int { return 2 }, func() int { return 1 })
+	if n != 1 {
+		t.Errorf("Select() = %d, want fallback 1", n)
+	}
+}
+
+func TestSwitch(t *testing.T) {
+	p := newStaticProvider(t, Flag{
+		Name:           "algorithm",
+		Enabled:        true,
+		DefaultVariant: "v2",
+		Variants: []Variant{
+			{Name: "v2", Value: "v2"},
+			{Name: "v3", Value: "v3"},
+		},
+	})
+	m := New(p)
+	cases := map[string]func() string{
+		"v2": func() string { return "algorithm two" },
+		"v3": func() string { return "algorithm three" },
+	}
+	fallback := func() string { return "algorithm one" }
+
+	tests := []struct {
+		name string
+		flag Flag
+		want string
+	}{
+		{name: "default variant", flag: Flag{Name: "algorithm", Enabled: true, DefaultVariant: "v2", Variants: []Variant{{Name: "v2"}, {Name: "v3"}}}, want: "algorithm two"},
+		{name: "other variant", flag: Flag{Name: "algorithm", Enabled: true, DefaultVariant: "v3", Variants: []Variant{{Name: "v2"}, {Name: "v3"}}}, want: "algorithm three"},
+		{name: "no matching case", flag: Flag{Name: "algorithm", Enabled: true, Variants: []Variant{{Name: "v9"}}}, want: "algorithm one"},
+		{name: "disabled", flag: Flag{Name: "algorithm", Enabled: false, Variants: []Variant{{Name: "v2"}}}, want: "algorithm one"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p.SetFlag(tt.flag)
+			if got := Switch(m, "algorithm", cases, fallback); got != tt.want {
+				t.Errorf("Switch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLazy(t *testing.T) {
+	p := NewStaticProvider(map[string]bool{"greeter-v2": false})
+	m := New(p)
+
+	builds := 0
+	lazy := NewLazy(m, "greeter-v2",
+		func() greeter { builds++; return v2Greeter{} },
+		func() greeter { builds++; return v1Greeter{} },
+	)
+
+	for i := 0; i < 3; i++ {
+		if lazy.Get().Greet() != "hello" {
+			t.Fatal("Get() returned v2 while flag is off")
+		}
+	}
+	if builds != 1 {
+		t.Errorf("built %d times while flag unchanged, want 1", builds)
+	}
+
+	p.Set("greeter-v2", true)
+	if lazy.Get().Greet() != "hi there" {
+		t.Error("Get() did not rebuild after flag flipped on")
+	}
+	lazy.Get()
+	if builds != 2 {
+		t.Errorf("built %d times after one flip, want 2", builds)
+	}
+}
+
+func TestLazySwitch(t *testing.T) {
+	p := newStaticProvider(t, Flag{
+		Name:           "algorithm",
+		Enabled:        true,
+		DefaultVariant: "v2",
+		Variants:       []Variant{{Name: "v2"}, {Name: "v3"}},
+	})
+	m := New(p)
+
+	builds := 0
+	lazy := NewLazySwitch(m, "algorithm", map[string]func() string{
+		"v2": func() string { builds++; return "two" },
+		"v3": func() string { builds++; return "three" },
+	}, func() string { builds++; return "one" })
+
+	if lazy.Get() != "two" || lazy.Get() != "two" || builds != 1 {
+		t.Errorf("Get() = %q after %d builds, want two after 1", lazy.Get(), builds)
+	}
+
+	flag, _ := p.Flag("algorithm")
+	flag.DefaultVariant = "v3"
+	p.SetFlag(flag)
+	if lazy.Get() != "three" || builds != 2 {
+		t.Errorf("Get() after variant change = %q after %d builds", lazy.Get(), builds)
+	}
+}
+
+func TestLazy_Concurrent(t *testing.T) {
+	p := NewStaticProvider(map[string]bool{"f": false})
+	lazy := NewLazy(New(p), "f", func() int { return 2 }, func() int { return 1 })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if i == 0 {
+					p.Set("f", j%2 == 0)
+				}
+				if v := lazy.Get(); v != 1 && v != 2 {
+					t.Errorf("Get() = %d", v)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
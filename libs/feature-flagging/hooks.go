@@ -0,0 +1,59 @@
+package featureflag
+
+import "context"
+
+// Evaluation describes one flag evaluation by a Manager.
+type Evaluation struct {
+	FlagName string
+
+	// Exists reports whether the provider defines the flag.
+	Exists bool
+
+	// Enabled is the result of evaluating the flag for EvalContext.
+	Enabled bool
+
+	// Variant is the name of the variant the flag serves to EvalContext,
+	// whichever accessor made the evaluation, so on/off checks and variant
+	// lookups of the same flag report the same result. It is empty when the
+	// flag has no variants.
+	Variant string
+
+	// Overridden reports whether an override attached to the context with
+	// WithOverrides decided Enabled.
+	Overridden bool
+
+	// Flag is the definition the flag was evaluated against, or the zero
+	// Flag if the provider doesn't define it.
+	Flag Flag
+
+	EvalContext EvalContext
+}
+
+// Hook observes flag evaluations, e.g. to record telemetry.
+type Hook interface {
+	// AfterEvaluation is called synchronously after every evaluation, so
+	// it must be cheap and must not block.
+	AfterEvaluation(ctx context.Context, eval Evaluation)
+}
+
+// HookFunc adapts a function to the Hook interface.
+type HookFunc func(ctx context.Context, eval Evaluation)
+
+// AfterEvaluation calls f(ctx, eval).
+func (f HookFunc) AfterEvaluation(ctx context.Context, eval Evaluation) {
+	f(ctx, eval)
+}
+
+// WithHook registers a hook that observes every evaluation the Manager
+// makes. Hooks run in the order they were registered.
+func WithHook(hook Hook) Option {
+	return func(m *Manager) {
+		m.hooks = append(m.hooks, hook)
+	}
+}
+
+func (m *Manager) runHooks(ctx context.Context, eval Evaluation) {
+	for _, hook := range m.hooks {
+		hook.AfterEvaluation(ctx, eval)
+	}
+}
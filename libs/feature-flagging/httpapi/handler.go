@@ -0,0 +1,217 @@
+// Package httpapi exposes feature flags over HTTP: an admin API for
+// inspecting and changing flags on a running service, and middleware that
+// prepares each request for flag evaluation.
+//
+// The admin API has no authentication of its own; mount it behind the
+// service's admin auth.
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	featureflag "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+)
+
+// WritableProvider is a provider whose flags can be listed and changed,
+// such as featureflag.StaticProvider or storeprovider.Provider.
+type WritableProvider interface {
+	featureflag.Provider
+	featureflag.Lister
+
+	// SaveFlag creates the flag or replaces its definition.
+	SaveFlag(ctx context.Context, flag featureflag.Flag) error
+}
+
+// Toggler is implemented by providers that can switch a flag on or off in
+// their backing store without rewriting the rest of its definition, such
+// as storeprovider.Provider. Toggle returns an error wrapping
+// featureflag.ErrFlagNotFound if the flag doesn't exist.
+type Toggler interface {
+	Toggle(ctx context.Context, flagName string, enabled bool) error
+}
+
+// getter is implemented by providers that can read a flag straight from
+// their backing store rather than from a cache, such as
+// storeprovider.Provider.
+type getter interface {
+	Get(ctx context.Context, flagName string) (featureflag.Flag, error)
+}
+
+// Handler serves the admin API:
+//
+//	GET  /flags               list every flag, or those with ?tag=
+//	GET  /flags/{name}        get one flag
+//	PUT  /flags/{name}        create or replace a flag from a JSON definition
+//	POST /flags/{name}/toggle switch a flag on or off
+//
+// Saved definitions are validated, including for dependency cycles with
+// the provider's other flags. The toggle endpoint takes an optional
+// {"enabled": bool} body and flips the flag without one; providers that
+// implement Toggler only have the enabled state changed, so concurrent
+// edits to the rest of the definition are kept. Responses are JSON; errors
+// are {"error": "message"}. Mount it under a prefix with http.StripPrefix.
+type Handler struct {
+	provider WritableProvider
+	mux      *http.ServeMux
+}
+
+// NewHandler creates an admin API handler over provider.
+func NewHandler(provider WritableProvider) *Handler {
+	h := &Handler{
+		provider: provider,
+		mux:      http.NewServeMux(),
+	}
+	h.mux.HandleFunc("GET /flags", h.list)
+	h.mux.HandleFunc("GET /flags/{name}", h.get)
+	h.mux.HandleFunc("PUT /flags/{name}", h.update)
+	h.mux.HandleFunc("POST /flags/{name}/toggle", h.toggle)
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	flags := h.provider.Flags()
+	if tag := r.URL.Query().Get("tag"); tag != "" {
+		flags = slices.DeleteFunc(flags, func(f featureflag.Flag) bool { return !f.HasTag(tag) })
+	}
+	writeJSON(w, http.StatusOK, flags)
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request) {
+	flag, ok := h.provider.Flag(r.PathValue("name"))
+	if !ok {
+		writeError(w, http.StatusNotFound, featureflag.ErrFlagNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, flag)
+}
+
+func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	var flag featureflag.Flag
+	if err := decodeJSON(r, &flag); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if flag.Name != "" && flag.Name != name {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("flag name %q does not match path %q", flag.Name, name))
+		return
+	}
+	flag.Name = name
+	if err := h.validate(flag); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.provider.SaveFlag(r.Context(), flag); err != nil {
+		writeSaveError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, flag)
+}
+
+func (h *Handler) toggle(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	body := struct {
+		Enabled *bool `json:"enabled"`
+	}{}
+	if err := decodeJSON(r, &body); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	flag, err := h.lookup(r.Context(), name)
+	if err != nil {
+		writeLookupError(w, err)
+		return
+	}
+	if body.Enabled != nil {
+		flag.Enabled = *body.Enabled
+	} else {
+		flag.Enabled = !flag.Enabled
+	}
+
+	if toggler, ok := h.provider.(Toggler); ok {
+		err = toggler.Toggle(r.Context(), name, flag.Enabled)
+	} else {
+		err = h.provider.SaveFlag(r.Context(), flag)
+	}
+	if err != nil {
+		writeSaveError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, flag)
+}
+
+// lookup returns the current definition of a flag, from the backing store
+// when the provider can read it and from the provider's cache otherwise.
+func (h *Handler) lookup(ctx context.Context, name string) (featureflag.Flag, error) {
+	if g, ok := h.provider.(getter); ok {
+		return g.Get(ctx, name)
+	}
+	flag, ok := h.provider.Flag(name)
+	if !ok {
+		return featureflag.Flag{}, featureflag.ErrFlagNotFound
+	}
+	return flag, nil
+}
+
+// validate checks flag together with the provider's other flags, so a
+// definition that would close a dependency cycle is rejected.
+func (h *Handler) validate(flag featureflag.Flag) error {
+	flags := slices.DeleteFunc(h.provider.Flags(), func(f featureflag.Flag) bool { return f.Name == flag.Name })
+	return featureflag.ValidateFlags(append(flags, flag))
+}
+
+// maxBodyBytes caps request bodies; flag definitions are small.
+const maxBodyBytes = 1 << 20
+
+func decodeJSON(r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeLookupError reports a missing flag as 404 and anything else as 500.
+func writeLookupError(w http.ResponseWriter, err error) {
+	if errors.Is(err, featureflag.ErrFlagNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
+
+// writeSaveError reports a failed save. The provider validates again when
+// saving, against flags the handler may not have seen, so validation
+// errors are the client's fault as they are before saving.
+func writeSaveError(w http.ResponseWriter, err error) {
+	if errors.Is(err, featureflag.ErrInvalidFlag) || errors.Is(err, featureflag.ErrDependencyCycle) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeLookupError(w, err)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,240 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	featureflag "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+	"github.com/JWindy92/obelisk-platform/libs/feature-flagging/storeprovider"
+	"github.com/JWindy92/obelisk-platform/libs/internal/storetest"
+	"github.com/JWindy92/obelisk-platform/libs/store"
+)
+
+func do(t *testing.T, h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	var req *http.Request
+	if body == "" {
+		req = httptest.NewRequest(method, path, nil)
+	} else {
+		req = httptest.NewRequest(method, path, strings.NewReader(body))
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	provider, err := featureflag.NewStaticProviderFromFlags(
+		featureflag.Flag{Name: "beta-api", Enabled: false},
+		featureflag.Flag{Name: "new-checkout", Enabled: true, Rollout: &featureflag.Rollout{Percentage: 10}},
+	)
+	if err != nil {
+		t.Fatalf("NewStaticProviderFromFlags() failed: %v", err)
+	}
+	h := NewHandler(provider)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+		check      func(t *testing.T)
+	}{
+		{
+			name: "list", method: "GET", path: "/flags",
+			wantStatus: http.StatusOK, wantBody: `"name":"beta-api"`,
+		},
+		{
+			name: "get", method: "GET", path: "/flags/new-checkout",
+			wantStatus: http.StatusOK, wantBody: `"percentage":10`,
+		},
+		{
+			name: "get missing", method: "GET", path: "/flags/missing",
+			wantStatus: http.StatusNotFound, wantBody: `"error"`,
+		},
+		{
+			name: "toggle flips", method: "POST", path: "/flags/beta-api/toggle",
+			wantStatus: http.StatusOK, wantBody: `"enabled":true`,
+			check: func(t *testing.T) {
+				if !provider.IsEnabled("beta-api") {
+					t.Error("toggle did not enable beta-api")
+				}
+			},
+		},
+		{
+			name: "toggle explicit", method: "POST", path: "/flags/new-checkout/toggle", body: `{"enabled": false}`,
+			wantStatus: http.StatusOK, wantBody: `"enabled":false`,
+			check: func(t *testing.T) {
+				flag, _ := provider.Flag("new-checkout")
+				if flag.Enabled || flag.Rollout == nil {
+					t.Errorf("toggle result = %+v, want disabled with rollout kept", flag)
+				}
+			},
+		},
+		{
+			name: "toggle missing", method: "POST", path: "/flags/missing/toggle",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "update creates", method: "PUT", path: "/flags/max-batch",
+			body:       `{"enabled": true, "variants": [{"name": "large", "value": 500}]}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T) {
+				flag, ok := provider.Flag("max-batch")
+				if !ok || len(flag.Variants) != 1 {
+					t.Errorf("Flag(max-batch) = %+v, %v", flag, ok)
+				}
+			},
+		},
+		{
+			name: "update invalid definition", method: "PUT", path: "/flags/bad",
+			body:       `{"enabled": true, "rollout": {"percentage": 200}}`,
+			wantStatus: http.StatusBadRequest, wantBody: "between 0 and 100",
+		},
+		{
+			name: "update unknown field", method: "PUT", path: "/flags/bad",
+			body:       `{"enabeld": true}`,
+			wantStatus: http.StatusBadRequest, wantBody: "unknown field",
+		},
+		{
+			name: "update name mismatch", method: "PUT", path: "/flags/a",
+			body:       `{"name": "b"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "update with undefined prerequisite", method: "PUT", path: "/flags/beta-api",
+			body:       `{"enabled": true, "prerequisites": ["bad-parent"]}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "update closing cycle", method: "PUT", path: "/flags/bad-parent",
+			body:       `{"enabled": true, "kill_switch": "beta-api"}`,
+			wantStatus: http.StatusBadRequest, wantBody: "cycle",
+		},
+		{
+			name: "list by tag", method: "PUT", path: "/flags/tagged",
+			body:       `{"enabled": true, "tags": ["payments"]}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T) {
+				rec := do(t, h, "GET", "/flags?tag=payments", "")
+				var flags []featureflag.Flag
+				if err := json.Unmarshal(rec.Body.Bytes(), &flags); err != nil {
+					t.Fatalf("Unmarshal() unexpected error: %v", err)
+				}
+				if len(flags) != 1 || flags[0].Name != "tagged" {
+					t.Errorf("GET /flags?tag=payments = %+v, want only tagged", flags)
+				}
+			},
+		},
+		{
+			name: "method not allowed", method: "DELETE", path: "/flags/beta-api",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(t, h, tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantBody != "" && !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want containing %s", rec.Body, tt.wantBody)
+			}
+			if tt.check != nil {
+				tt.check(t)
+			}
+		})
+	}
+
+	if _, ok := provider.Flag("bad"); ok {
+		t.Error("invalid update was saved")
+	}
+}
+
+func TestHandler_ListDecodes(t *testing.T) {
+	h := NewHandler(featureflag.NewStaticProvider(map[string]bool{"a": true, "b": false}))
+
+	rec := do(t, h, "GET", "/flags", "")
+	var flags []featureflag.Flag
+	if err := json.Unmarshal(rec.Body.Bytes(), &flags); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if len(flags) != 2 || flags[0].Name != "a" || !flags[0].Enabled || flags[1].Name != "b" {
+		t.Errorf("list = %+v", flags)
+	}
+}
+
+func TestHandler_ToggleStoreProvider(t *testing.T) {
+	ctx := context.Background()
+	config := storeprovider.DefaultConfig()
+	st := storetest.SQLite(t, storeprovider.Migrations(store.DialectSQLite, config))
+
+	// provider serves the admin API; other is another instance on the same table.
+	provider := storeprovider.NewProvider(st, config)
+	other := storeprovider.NewProvider(st, config)
+	if err := other.Create(ctx, featureflag.Flag{Name: "new-checkout", Enabled: false}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if err := provider.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+
+	rules := []featureflag.Rule{{Attribute: "tenant", Operator: featureflag.OpIn, Values: []string{"acme"}}}
+	if err := other.Update(ctx, featureflag.Flag{Name: "new-checkout", Enabled: false, Rules: rules}); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	if err := other.Create(ctx, featureflag.Flag{Name: "beta-api", Enabled: true}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	h := NewHandler(provider)
+
+	if rec := do(t, h, "POST", "/flags/new-checkout/toggle", ""); rec.Code != http.StatusOK {
+		t.Fatalf("toggle new-checkout status = %d, body = %s", rec.Code, rec.Body)
+	}
+	flag, err := other.Get(ctx, "new-checkout")
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if !flag.Enabled || len(flag.Rules) != 1 {
+		t.Errorf("Get() = %+v, want enabled with the other instance's rules kept", flag)
+	}
+
+	rec := do(t, h, "POST", "/flags/beta-api/toggle", `{"enabled": false}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("toggle uncached flag status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if flag, _ := other.Get(ctx, "beta-api"); flag.Enabled {
+		t.Error("toggle did not disable beta-api")
+	}
+
+	if rec := do(t, h, "POST", "/flags/missing/toggle", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("toggle missing status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandler_UpdateCycleFromStore(t *testing.T) {
+	ctx := context.Background()
+	config := storeprovider.DefaultConfig()
+	st := storetest.SQLite(t, storeprovider.Migrations(store.DialectSQLite, config))
+
+	// other adds a flag the handler's provider hasn't loaded, so only the
+	// check against the database at save time sees the cycle.
+	provider := storeprovider.NewProvider(st, config)
+	other := storeprovider.NewProvider(st, config)
+	if err := other.Create(ctx, featureflag.Flag{Name: "new-cart", Enabled: true, Prerequisites: []string{"new-checkout"}}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	h := NewHandler(provider)
+
+	rec := do(t, h, "PUT", "/flags/new-checkout", `{"enabled": true, "prerequisites": ["new-cart"]}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("update closing a cycle status = %d, want %d, body = %s", rec.Code, http.StatusBadRequest, rec.Body)
+	}
+}
@@ -0,0 +1,81 @@
+package httpapi
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	featureflag "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+)
+
+// MiddlewareConfig holds configuration options for Middleware.
+type MiddlewareConfig struct {
+	// EvalContext builds the evaluation context for a request.
+	// Defaults to FromRequest if not specified.
+	EvalContext func(r *http.Request) featureflag.EvalContext
+
+	// OverrideSecret is the HMAC key for X-Feature-Override headers.
+	// Overrides are ignored if it is empty.
+	OverrideSecret []byte
+
+	// OnInvalidOverride is called when a request carries an override
+	// header that fails verification; the request proceeds without
+	// overrides. May be nil.
+	OnInvalidOverride func(r *http.Request, err error)
+}
+
+// Middleware prepares every request for flag evaluation. It attaches an
+// evaluation context with featureflag.WithEvalContext, applies verified
+// X-Feature-Override headers with featureflag.WithOverrides, and adds a
+// featureflag.WithRequestCache so each flag evaluates the same way for the
+// whole request. Handlers then use the Manager's context-aware methods:
+//
+//	if ff.IsEnabledCtx(r.Context(), "new-checkout") { ... }
+func Middleware(config MiddlewareConfig) func(http.Handler) http.Handler {
+	if config.EvalContext == nil {
+		config.EvalContext = FromRequest
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := featureflag.WithEvalContext(r.Context(), config.EvalContext(r))
+
+			if header := r.Header.Get(OverrideHeader); header != "" && len(config.OverrideSecret) > 0 {
+				overrides, err := VerifyOverride(config.OverrideSecret, header, time.Now())
+				if err == nil {
+					ctx = featureflag.WithOverrides(ctx, overrides)
+				} else if config.OnInvalidOverride != nil {
+					config.OnInvalidOverride(r, err)
+				}
+			}
+
+			ctx = featureflag.WithRequestCache(ctx)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// FromRequest builds an evaluation context from common request data: the
+// user ID from the X-User-ID header, and the attributes "tenant" (from
+// X-Tenant-ID), "ip", "user_agent" and "path". Services with their own
+// authentication should supply a MiddlewareConfig.EvalContext that reads
+// the authenticated user instead.
+func FromRequest(r *http.Request) featureflag.EvalContext {
+	attrs := map[string]string{
+		"path": r.URL.Path,
+	}
+	if tenant := r.Header.Get("X-Tenant-ID"); tenant != "" {
+		attrs["tenant"] = tenant
+	}
+	if ua := r.UserAgent(); ua != "" {
+		attrs["user_agent"] = ua
+	}
+	if ip, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		attrs["ip"] = ip
+	}
+
+	return featureflag.EvalContext{
+		UserID:     r.Header.Get("X-User-ID"),
+		Attributes: attrs,
+	}
+}
@@ -0,0 +1,176 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	featureflag "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+)
+
+func TestSignAndVerifyOverride(t *testing.T) {
+	secret := []byte("qa-secret")
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	value, err := SignOverride(secret, map[string]bool{"a": true, "b": false}, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("SignOverride() unexpected error: %v", err)
+	}
+
+	flags, err := VerifyOverride(secret, value, now)
+	if err != nil {
+		t.Fatalf("VerifyOverride() unexpected error: %v", err)
+	}
+	if len(flags) != 2 || !flags["a"] || flags["b"] {
+		t.Errorf("VerifyOverride() = %v", flags)
+	}
+
+	tampered := "e30" + value[3:]
+	tests := []struct {
+		name   string
+		secret []byte
+		value  string
+		now    time.Time
+	}{
+		{name: "wrong secret", secret: []byte("other"), value: value, now: now},
+		{name: "expired", secret: secret, value: value, now: now.Add(2 * time.Hour)},
+		{name: "tampered payload", secret: secret, value: tampered, now: now},
+		{name: "malformed", secret: secret, value: "not-an-override", now: now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := VerifyOverride(tt.secret, tt.value, tt.now); !errors.Is(err, ErrInvalidOverride) {
+				t.Errorf("VerifyOverride() error = %v, want ErrInvalidOverride", err)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	secret := []byte("qa-secret")
+	provider, err := featureflag.NewStaticProviderFromFlags(
+		featureflag.Flag{
+			Name:    "tenant-beta",
+			Enabled: true,
+			Rules: []featureflag.Rule{
+				{Attribute: "tenant", Operator: featureflag.OpEquals, Values: []string{"acme"}},
+			},
+		},
+		featureflag.Flag{Name: "new-checkout", Enabled: false},
+	)
+	if err != nil {
+		t.Fatalf("NewStaticProviderFromFlags() failed: %v", err)
+	}
+	ff := featureflag.New(provider)
+
+	var invalid []error
+	mw := Middleware(MiddlewareConfig{
+		OverrideSecret:    secret,
+		OnInvalidOverride: func(r *http.Request, err error) { invalid = append(invalid, err) },
+	})
+
+	type result struct{ beta, checkout bool }
+	var got result
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := featureflag.FromContext(r.Context()); !ok {
+			t.Fatal("FromContext() found no evaluation context")
+		}
+		got = result{
+			beta:     ff.IsEnabledCtx(r.Context(), "tenant-beta"),
+			checkout: ff.IsEnabledCtx(r.Context(), "new-checkout"),
+		}
+	}))
+
+	valid, _ := SignOverride(secret, map[string]bool{"new-checkout": true}, time.Now().Add(time.Hour))
+	forged, _ := SignOverride([]byte("guess"), map[string]bool{"new-checkout": true}, time.Now().Add(time.Hour))
+
+	tests := []struct {
+		name        string
+		headers     map[string]string
+		want        result
+		wantInvalid int
+	}{
+		{name: "no context", want: result{}},
+		{name: "targeted tenant", headers: map[string]string{"X-Tenant-ID": "acme"}, want: result{beta: true}},
+		{name: "valid override", headers: map[string]string{OverrideHeader: valid}, want: result{checkout: true}},
+		{name: "forged override", headers: map[string]string{OverrideHeader: forged}, want: result{}, wantInvalid: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalid = nil
+			req := httptest.NewRequest("GET", "/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("evaluations = %+v, want %+v", got, tt.want)
+			}
+			if len(invalid) != tt.wantInvalid {
+				t.Errorf("OnInvalidOverride called %d times, want %d", len(invalid), tt.wantInvalid)
+			}
+		})
+	}
+}
+
+func TestMiddleware_IgnoresOverridesWithoutSecret(t *testing.T) {
+	ff := featureflag.New(featureflag.NewStaticProvider(map[string]bool{"f": false}))
+	value, _ := SignOverride(nil, map[string]bool{"f": true}, time.Now().Add(time.Hour))
+
+	var enabled bool
+	h := Middleware(MiddlewareConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		enabled = ff.IsEnabledFor(r.Context(), "f", featureflag.EvalContext{})
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(OverrideHeader, value)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if enabled {
+		t.Error("override applied although no secret is configured")
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	req := httptest.NewRequest("GET", "/checkout", nil)
+	req.RemoteAddr = "203.0.113.7:4321"
+	req.Header.Set("X-User-ID", "user-1")
+	req.Header.Set("X-Tenant-ID", "acme")
+	req.Header.Set("User-Agent", "test/1.0")
+
+	evalCtx := FromRequest(req)
+	if evalCtx.UserID != "user-1" {
+		t.Errorf("UserID = %q, want user-1", evalCtx.UserID)
+	}
+	want := map[string]string{"tenant": "acme", "ip": "203.0.113.7", "user_agent": "test/1.0", "path": "/checkout"}
+	for k, v := range want {
+		if evalCtx.Attributes[k] != v {
+			t.Errorf("Attributes[%s] = %q, want %q", k, evalCtx.Attributes[k], v)
+		}
+	}
+}
+
+func TestMiddleware_RequestCache(t *testing.T) {
+	provider := featureflag.NewStaticProvider(map[string]bool{"f": true})
+	ff := featureflag.New(provider)
+
+	var first, second bool
+	h := Middleware(MiddlewareConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		first = ff.IsEnabledCtx(r.Context(), "f")
+		provider.Set("f", false) // flipped mid-request
+		second = ff.IsEnabledCtx(r.Context(), "f")
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if !first || !second {
+		t.Errorf("evaluations = %v, %v; want the flag to stay on for the request", first, second)
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if first {
+		t.Error("next request still saw the flag on")
+	}
+}
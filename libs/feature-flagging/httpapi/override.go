@@ -0,0 +1,72 @@
+package httpapi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OverrideHeader carries signed flag overrides, created with SignOverride.
+const OverrideHeader = "X-Feature-Override"
+
+// ErrInvalidOverride is returned when an override header is malformed,
+// has a bad signature or has expired.
+var ErrInvalidOverride = errors.New("invalid feature override")
+
+// overridePayload is the signed content of an override header.
+type overridePayload struct {
+	Flags   map[string]bool `json:"flags"`
+	Expires int64           `json:"exp"`
+}
+
+// SignOverride creates an X-Feature-Override header value that forces the
+// given flags on or off until expires. QA tooling shares secret with the
+// service's MiddlewareConfig.OverrideSecret.
+//
+// The value is "<payload>.<signature>": base64url-encoded JSON and its
+// HMAC-SHA256 under secret.
+func SignOverride(secret []byte, flags map[string]bool, expires time.Time) (string, error) {
+	payload, err := json.Marshal(overridePayload{Flags: flags, Expires: expires.Unix()})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode feature override: %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + signature(secret, encoded), nil
+}
+
+// VerifyOverride checks an X-Feature-Override header value and returns the
+// flags it overrides.
+func VerifyOverride(secret []byte, value string, now time.Time) (map[string]bool, error) {
+	encoded, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil, ErrInvalidOverride
+	}
+	if !hmac.Equal([]byte(sig), []byte(signature(secret, encoded))) {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidOverride)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOverride, err)
+	}
+	var payload overridePayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOverride, err)
+	}
+	if !now.Before(time.Unix(payload.Expires, 0)) {
+		return nil, fmt.Errorf("%w: expired", ErrInvalidOverride)
+	}
+	return payload.Flags, nil
+}
+
+func signature(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -1,20 +1,125 @@
 package featureflag
 
+import (
+	"context"
+	"time"
+)
+
 // Manager provides the main API for working with feature flags.
+// It is safe for concurrent use as long as its provider is; all providers
+// in this package are.
 type Manager struct {
 	provider Provider
+	hooks    []Hook
+	now      func() time.Time
 }
 
+// Option configures optional Manager behaviour.
+type Option func(*Manager)
+
 // New creates a new Manager with the given provider.
-func New(provider Provider) *Manager {
-	return &Manager{
+func New(provider Provider, opts ...Option) *Manager {
+	m := &Manager{
 		provider: provider,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// WithClock sets the clock flag schedules are evaluated against.
+// Defaults to time.Now; tests can pass a fixed or simulated time.
+func WithClock(now func() time.Time) Option {
+	return func(m *Manager) {
+		m.now = now
 	}
 }
 
+// Provider returns the provider the Manager evaluates flags from.
+func (m *Manager) Provider() Provider {
+	return m.provider
+}
+
 // IsEnabled checks if a feature flag is enabled.
+// It evaluates the flag without an evaluation context, so flags restricted
+// by targeting rules or a partial rollout are reported as disabled.
 func (m *Manager) IsEnabled(flagName string) bool {
-	return m.provider.IsEnabled(flagName)
+	return m.IsEnabledFor(context.Background(), flagName, EvalContext{})
+}
+
+// IsEnabledFor checks if a feature flag is enabled for the given evaluation
+// context. The flag's targeting rules must all match, the context must
+// fall within its percentage rollout and the flag's schedule, and its
+// prerequisites must be on and its kill switch off, evaluated for the same
+// context. Overrides attached to ctx with
+// WithOverrides take precedence.
+func (m *Manager) IsEnabledFor(ctx context.Context, flagName string, evalCtx EvalContext) bool {
+	eval, _, _ := m.evaluateVariant(ctx, flagName, evalCtx)
+	return eval.Enabled
+}
+
+// evaluate looks up a flag and reports whether it exists and is enabled
+// for evalCtx, honoring overrides on ctx, schedules, prerequisites and kill
+// switches. Results are cached when ctx carries a request cache.
+func (m *Manager) evaluate(ctx context.Context, flagName string, evalCtx EvalContext) evaluation {
+	return m.evaluateDependency(ctx, flagName, evalCtx, nil)
+}
+
+// evaluateDependency is evaluate for a flag reached through the
+// dependencies of the flags on path. Dependencies are cached like the flags
+// that depend on them, so a whole request sees one result for each.
+func (m *Manager) evaluateDependency(ctx context.Context, flagName string, evalCtx EvalContext, path []string) evaluation {
+	cache := requestCacheFrom(ctx)
+	if cache == nil {
+		return m.evaluateUncached(ctx, flagName, evalCtx, path)
+	}
+
+	key := cacheKey(flagName, evalCtx)
+	if cached, ok := cache.get(m, key); ok {
+		return cached
+	}
+	result := m.evaluateUncached(ctx, flagName, evalCtx, path)
+	cache.put(m, key, result)
+	return result
+}
+
+func (m *Manager) evaluateUncached(ctx context.Context, flagName string, evalCtx EvalContext, path []string) evaluation {
+	flag, exists := m.provider.Flag(flagName)
+	if forced, ok := overridesFrom(ctx)[flagName]; ok {
+		return evaluation{flag: flag, exists: exists, enabled: forced, overridden: true}
+	}
+	if !exists {
+		return evaluation{} // Fail-safe: unknown flags are disabled
+	}
+	enabled := evaluate(flag, evalCtx) &&
+		flag.Schedule.Active(m.now()) &&
+		m.dependenciesMet(ctx, flag, evalCtx, path)
+	return evaluation{flag: flag, exists: true, enabled: enabled}
+}
+
+// IsEnabledCtx checks if a feature flag is enabled for the evaluation
+// context attached to ctx with WithEvalContext, honoring overrides and the
+// request cache on ctx. Without an attached context it behaves like
+// IsEnabledFor with an empty one.
+func (m *Manager) IsEnabledCtx(ctx context.Context, flagName string) bool {
+	evalCtx, _ := FromContext(ctx)
+	return m.IsEnabledFor(ctx, flagName, evalCtx)
+}
+
+// IsDisabledCtx is the inverse of IsEnabledCtx.
+func (m *Manager) IsDisabledCtx(ctx context.Context, flagName string) bool {
+	return !m.IsEnabledCtx(ctx, flagName)
+}
+
+// WhenCtx is When for the evaluation context attached to ctx.
+func (m *Manager) WhenCtx(ctx context.Context, flagName string, enabled, fallback func()) {
+	if m.IsEnabledCtx(ctx, flagName) {
+		enabled()
+	} else {
+		fallback()
+	}
 }
 
 // IsDisabled checks if a feature flag is disabled (convenience method).
@@ -24,6 +129,7 @@ func (m *Manager) IsDisabled(flagName string) bool {
 
 // Select returns the enabled implementation if the flag is on, otherwise the fallback.
 // Both enabled and fallback are factory functions that create the implementation.
+// Prefer the generic Select function, which avoids the type assertion.
 func (m *Manager) Select(flagName string, enabled, fallback func() any) any {
 	if m.IsEnabled(flagName) {
 		return enabled()
@@ -0,0 +1,269 @@
+package featureflag
+
+import (
+	"context"
+	"testing"
+)
+
+func TestManager_IsEnabled(t *testing.T) {
+	provider := NewStaticProvider(map[string]bool{
+		"on":  true,
+		"off": false,
+	})
+	m := New(provider)
+
+	if !m.IsEnabled("on") {
+		t.Error("IsEnabled(on) = false, want true")
+	}
+	if m.IsEnabled("off") || !m.IsDisabled("off") {
+		t.Error("IsEnabled(off) = true, want false")
+	}
+	if m.IsEnabled("missing") {
+		t.Error("IsEnabled(missing) = true, want fail-safe false")
+	}
+
+	provider.Set("off", true)
+	if !m.IsEnabled("off") {
+		t.Error("IsEnabled(off) after Set = false, want true")
+	}
+}
+
+func TestManager_IsEnabledFor(t *testing.T) {
+	ctx := context.Background()
+	m := New(newStaticProvider(t,
+		Flag{
+			Name:    "tenant-beta",
+			Enabled: true,
+			Rules: []Rule{
+				{Attribute: "tenant", Operator: OpIn, Values: []string{"acme", "globex"}},
+				{Attribute: "app_version", Operator: OpSemverGte, Values: []string{"2.0.0"}},
+			},
+		},
+		Flag{
+			Name:    "killed",
+			Enabled: false,
+			Rules:   []Rule{{Attribute: "tenant", Operator: OpEquals, Values: []string{"acme"}}},
+		},
+	))
+
+	acmeV2 := EvalContext{UserID: "u1", Attributes: map[string]string{"tenant": "acme", "app_version": "2.1.0"}}
+	acmeV1 := EvalContext{UserID: "u2", Attributes: map[string]string{"tenant": "acme", "app_version": "1.9.0"}}
+	other := EvalContext{UserID: "u3", Attributes: map[string]string{"tenant": "initech", "app_version": "3.0.0"}}
+
+	if !m.IsEnabledFor(ctx, "tenant-beta", acmeV2) {
+		t.Error("IsEnabledFor() = false for matching context")
+	}
+	if m.IsEnabledFor(ctx, "tenant-beta", acmeV1) {
+		t.Error("IsEnabledFor() = true when one rule fails")
+	}
+	if m.IsEnabledFor(ctx, "tenant-beta", other) {
+		t.Error("IsEnabledFor() = true for non-targeted tenant")
+	}
+	if m.IsEnabled("tenant-beta") {
+		t.Error("IsEnabled() = true for targeted flag without context")
+	}
+	if m.IsEnabledFor(ctx, "killed", acmeV2) {
+		t.Error("IsEnabledFor() = true for disabled flag")
+	}
+}
+
+func TestManager_SelectAndWhen(t *testing.T) {
+	m := New(NewStaticProvider(map[string]bool{"v2": true}))
+
+	got := m.Select("v2", func() any { return "new" }, func() any { return "old" })
+	if got != "new" {
+		t.Errorf("Select() = %v, want new", got)
+	}
+	got = m.Select("v3", func() any { return "new" }, func() any { return "old" })
+	if got != "old" {
+		t.Errorf("Select() = %v, want old", got)
+	}
+
+	var ran string
+	m.When("v2", func() { ran = "enabled" }, func() { ran = "fallback" })
+	if ran != "enabled" {
+		t.Errorf("When() ran %q, want enabled", ran)
+	}
+}
+
+func TestStaticProvider_SetKeepsTargeting(t *testing.T) {
+	rule := Rule{Attribute: "tenant", Operator: OpEquals, Values: []string{"acme"}}
+	p := newStaticProvider(t, Flag{Name: "f", Enabled: false, Rules: []Rule{rule}})
+
+	p.Set("f", true)
+
+	flag, ok := p.Flag("f")
+	if !ok || !flag.Enabled || len(flag.Rules) != 1 {
+		t.Errorf("Flag() after Set = %+v, %v; want enabled with rules kept", flag, ok)
+	}
+}
+
+func TestManager_Overrides(t *testing.T) {
+	m := New(newStaticProvider(t,
+		Flag{Name: "on", Enabled: true},
+		Flag{Name: "off", Enabled: false},
+		Flag{Name: "theme", Enabled: false, Variants: []Variant{{Name: "dark", Value: "dark"}}},
+	))
+
+	ctx := WithOverrides(context.Background(), map[string]bool{"on": false, "off": true})
+	ctx = WithOverrides(ctx, map[string]bool{"theme": true})
+
+	if m.IsEnabledFor(ctx, "on", EvalContext{}) {
+		t.Error("IsEnabledFor(on) = true, want overridden off")
+	}
+	if !m.IsEnabledFor(ctx, "off", EvalContext{}) {
+		t.Error("IsEnabledFor(off) = false, want overridden on")
+	}
+	if got := m.StringVariant(ctx, "theme", EvalContext{}, "light"); got != "dark" {
+		t.Errorf("StringVariant(theme) = %q, want dark", got)
+	}
+	if m.IsEnabled("off") {
+		t.Error("IsEnabled() without overrides = true")
+	}
+	if got := OverridesFrom(ctx); len(got) != 3 {
+		t.Errorf("OverridesFrom() = %v, want 3 overrides", got)
+	}
+}
+
+func TestManager_EvalContextFromContext(t *testing.T) {
+	acmeOnly := []Rule{{Attribute: "tenant", Operator: OpEquals, Values: []string{"acme"}}}
+	m := New(newStaticProvider(t,
+		Flag{Name: "tenant-beta", Enabled: true, Rules: acmeOnly},
+		Flag{Name: "theme", Enabled: true, Variants: []Variant{{Name: "dark", Value: "dark"}}},
+		Flag{Name: "tenant-variants", Enabled: true, Rules: acmeOnly, Variants: []Variant{{Name: "acme", Value: "acme-theme"}}},
+		Flag{Name: "tenant-limit", Enabled: true, Rules: acmeOnly, Variants: []Variant{{Name: "high", Value: 50}}},
+		Flag{Name: "tenant-json", Enabled: true, Rules: acmeOnly, Variants: []Variant{{Name: "limits", Value: map[string]any{"requests": 10}}}},
+	))
+	ctx := WithEvalContext(context.Background(), EvalContext{UserID: "u1", Attributes: map[string]string{"tenant": "acme"}})
+
+	if _, ok := FromContext(context.Background()); ok {
+		t.Error("FromContext() found an evaluation context on an empty context")
+	}
+	if evalCtx, ok := FromContext(ctx); !ok || evalCtx.UserID != "u1" {
+		t.Errorf("FromContext() = %+v, %v", evalCtx, ok)
+	}
+
+	if !m.IsEnabledCtx(ctx, "tenant-beta") {
+		t.Error("IsEnabledCtx() = false for targeted tenant on context")
+	}
+	if m.IsEnabledCtx(context.Background(), "tenant-beta") {
+		t.Error("IsEnabledCtx() = true without an evaluation context")
+	}
+	if !m.IsDisabledCtx(WithOverrides(ctx, map[string]bool{"tenant-beta": false}), "tenant-beta") {
+		t.Error("IsDisabledCtx() = false with override off")
+	}
+
+	var ran string
+	m.WhenCtx(ctx, "tenant-beta", func() { ran = "enabled" }, func() { ran = "fallback" })
+	if ran != "enabled" {
+		t.Errorf("WhenCtx() ran %q, want enabled", ran)
+	}
+	if eval := m.EvaluateCtx(ctx, "theme"); eval.Variant != "dark" || eval.EvalContext.UserID != "u1" {
+		t.Errorf("EvaluateCtx() = %+v", eval)
+	}
+
+	if v, ok := m.VariantCtx(ctx, "tenant-variants"); !ok || v.Name != "acme" {
+		t.Errorf("VariantCtx() = %+v, %v; want acme", v, ok)
+	}
+	if _, ok := m.VariantCtx(context.Background(), "tenant-variants"); ok {
+		t.Error("VariantCtx() served a variant without an evaluation context")
+	}
+	if got := m.StringVariantCtx(ctx, "tenant-variants", "default"); got != "acme-theme" {
+		t.Errorf("StringVariantCtx() = %q, want acme-theme", got)
+	}
+	if got := m.IntVariantCtx(ctx, "tenant-limit", 1); got != 50 {
+		t.Errorf("IntVariantCtx() = %d, want 50", got)
+	}
+	if got := m.IntVariantCtx(context.Background(), "tenant-limit", 1); got != 1 {
+		t.Errorf("IntVariantCtx() without an evaluation context = %d, want default", got)
+	}
+	if got := m.FloatVariantCtx(ctx, "tenant-limit", 1); got != 50 {
+		t.Errorf("FloatVariantCtx() = %v, want 50", got)
+	}
+	var limits struct{ Requests int }
+	if err := m.JSONVariantCtx(ctx, "tenant-json", &limits); err != nil || limits.Requests != 10 {
+		t.Errorf("JSONVariantCtx() = %+v, %v", limits, err)
+	}
+
+	if got := SelectCtx(ctx, m, "tenant-beta", func() string { return "v2" }, func() string { return "v1" }); got != "v2" {
+		t.Errorf("SelectCtx() = %q, want v2", got)
+	}
+	cases := map[string]func() string{"acme": func() string { return "acme-impl" }}
+	if got := SwitchCtx(ctx, m, "tenant-variants", cases, func() string { return "default" }); got != "acme-impl" {
+		t.Errorf("SwitchCtx() = %q, want acme-impl", got)
+	}
+	lazy := NewLazy(m, "tenant-beta", func() string { return "v2" }, func() string { return "v1" })
+	if got := lazy.GetCtx(ctx); got != "v2" {
+		t.Errorf("Lazy.GetCtx() = %q, want v2", got)
+	}
+	if got := lazy.Get(); got != "v1" {
+		t.Errorf("Lazy.Get() = %q, want v1 without an evaluation context", got)
+	}
+}
+
+func TestManager_RequestCache(t *testing.T) {
+	provider := newStaticProvider(t,
+		Flag{Name: "f", Enabled: true, Rules: []Rule{{Attribute: "tenant", Operator: OpEquals, Values: []string{"acme"}}}},
+		Flag{Name: "theme", Enabled: true, Variants: []Variant{{Name: "dark", Value: "dark"}}},
+	)
+	m := New(provider)
+	ctx := WithRequestCache(context.Background())
+	acme := EvalContext{Attributes: map[string]string{"tenant": "acme"}}
+	globex := EvalContext{Attributes: map[string]string{"tenant": "globex"}}
+
+	if !m.IsEnabledFor(ctx, "f", acme) || m.IsEnabledFor(ctx, "f", globex) {
+		t.Fatal("IsEnabledFor() did not evaluate targeting before caching")
+	}
+	if got := m.StringVariant(ctx, "theme", acme, "light"); got != "dark" {
+		t.Fatalf("StringVariant() = %q, want dark", got)
+	}
+
+	provider.SetFlag(Flag{Name: "f", Enabled: true})
+	provider.SetFlag(Flag{Name: "theme", Enabled: true, Variants: []Variant{{Name: "blue", Value: "blue"}}})
+
+	// Results are cached per evaluation context, so both keep their answer.
+	if !m.IsEnabledFor(ctx, "f", acme) || m.IsEnabledFor(ctx, "f", globex) {
+		t.Error("IsEnabledFor() with request cache changed mid-request")
+	}
+	if got := m.StringVariant(ctx, "theme", acme, "light"); got != "dark" {
+		t.Errorf("StringVariant() with request cache = %q, want dark", got)
+	}
+	if !m.IsEnabledFor(context.Background(), "f", globex) {
+		t.Error("IsEnabledFor() without request cache did not see the change")
+	}
+}
+
+func TestManager_RequestCachePerManager(t *testing.T) {
+	on := New(newStaticProvider(t, Flag{Name: "x", Enabled: true}))
+	off := New(newStaticProvider(t, Flag{Name: "x", Enabled: false}))
+	ctx := WithRequestCache(context.Background())
+
+	if !on.IsEnabledCtx(ctx, "x") {
+		t.Error("IsEnabledCtx() on first Manager = false, want true")
+	}
+	if off.IsEnabledCtx(ctx, "x") {
+		t.Error("IsEnabledCtx() on second Manager = true, want its own result rather than the first Manager's")
+	}
+}
+
+func TestManager_RequestCacheDependencies(t *testing.T) {
+	provider := newStaticProvider(t,
+		Flag{Name: "base", Enabled: true},
+		Flag{Name: "kill", Enabled: false},
+		Flag{Name: "dependent", Enabled: true, Prerequisites: []string{"base"}, KillSwitch: "kill"},
+	)
+	m := New(provider)
+	ctx := WithRequestCache(context.Background())
+
+	if !m.IsEnabledCtx(ctx, "dependent") {
+		t.Fatal("IsEnabledCtx(dependent) = false, want true")
+	}
+
+	// The dependencies were cached while evaluating dependent, so flipping
+	// them mid-request doesn't make them disagree with it.
+	provider.SetFlag(Flag{Name: "base", Enabled: false})
+	provider.SetFlag(Flag{Name: "kill", Enabled: true})
+	if !m.IsEnabledCtx(ctx, "base") || m.IsEnabledCtx(ctx, "kill") {
+		t.Error("dependencies changed mid-request, want results cached with dependent")
+	}
+}
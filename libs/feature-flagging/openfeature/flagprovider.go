@@ -0,0 +1,79 @@
+package openfeature
+
+import (
+	"context"
+
+	featureflag "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+)
+
+// FlagProvider backs a featureflag.Provider with an OpenFeature provider,
+// so a vendor's flags can be used through featureflag.Manager.
+//
+// featureflag.Provider lookups carry no evaluation context, so the
+// OpenFeature provider is asked with the static context given to
+// NewFlagProvider and its targeting decides the result. Boolean flags map
+// to on/off flags; flags that resolve to another type become an enabled
+// flag serving the resolved value as its only variant.
+type FlagProvider struct {
+	provider FeatureProvider
+	evalCtx  FlattenedContext
+}
+
+var _ featureflag.Provider = (*FlagProvider)(nil)
+
+// NewFlagProvider creates a featureflag.Provider that resolves flags with p
+// using evalCtx, which may be nil.
+func NewFlagProvider(p FeatureProvider, evalCtx FlattenedContext) *FlagProvider {
+	return &FlagProvider{
+		provider: p,
+		evalCtx:  evalCtx,
+	}
+}
+
+// IsEnabled checks if a feature flag resolves to true.
+// Returns false if the flag doesn't exist or fails to resolve.
+func (f *FlagProvider) IsEnabled(flagName string) bool {
+	flag, _ := f.Flag(flagName)
+	return flag.Enabled
+}
+
+// Lookup reports whether a feature flag is enabled and whether it resolved.
+func (f *FlagProvider) Lookup(flagName string) (enabled, exists bool) {
+	flag, exists := f.Flag(flagName)
+	return flag.Enabled, exists
+}
+
+// Flag resolves the flag. Flags that are not found or fail to resolve for
+// any reason other than their type are reported as missing, so a Manager
+// falls back to its fail-safe default.
+func (f *FlagProvider) Flag(flagName string) (featureflag.Flag, bool) {
+	ctx := context.Background()
+
+	b := f.provider.BooleanEvaluation(ctx, flagName, false, f.evalCtx)
+	switch b.ErrorCode {
+	case "":
+		return featureflag.Flag{Name: flagName, Enabled: b.Value}, true
+	case TypeMismatchCode:
+	default:
+		return featureflag.Flag{}, false
+	}
+
+	var value any
+	var variant string
+	if s := f.provider.StringEvaluation(ctx, flagName, "", f.evalCtx); s.ErrorCode == "" {
+		value, variant = s.Value, s.Variant
+	} else if o := f.provider.ObjectEvaluation(ctx, flagName, nil, f.evalCtx); o.ErrorCode == "" {
+		value, variant = o.Value, o.Variant
+	} else {
+		return featureflag.Flag{}, false
+	}
+
+	if variant == "" {
+		variant = "value"
+	}
+	return featureflag.Flag{
+		Name:     flagName,
+		Enabled:  true,
+		Variants: []featureflag.Variant{{Name: variant, Value: value}},
+	}, true
+}
@@ -0,0 +1,152 @@
+// Package gosdk converts between the openfeature package's provider
+// contract and the OpenFeature Go SDK's
+// (github.com/open-feature/go-sdk/openfeature).
+//
+// ToSDK registers a featureflag.Manager, or any other openfeature
+// FeatureProvider, with the SDK:
+//
+//	of.SetProviderAndWait(gosdk.ToSDK(openfeature.NewProvider(manager)))
+//
+// FromSDK goes the other way, so a vendor's SDK provider can back a
+// featureflag.Provider:
+//
+//	flags := openfeature.NewFlagProvider(gosdk.FromSDK(vendor), evalCtx)
+//
+// It lives in its own package so that only programs using the SDK depend
+// on it.
+package gosdk
+
+import (
+	"context"
+
+	of "github.com/open-feature/go-sdk/openfeature"
+
+	"github.com/JWindy92/obelisk-platform/libs/feature-flagging/openfeature"
+)
+
+// ToSDK adapts p to the SDK's FeatureProvider interface.
+func ToSDK(p openfeature.FeatureProvider) of.FeatureProvider {
+	return sdkProvider{provider: p}
+}
+
+// FromSDK adapts an SDK provider to the openfeature package's
+// FeatureProvider interface. The SDK provider's hooks are not run.
+func FromSDK(p of.FeatureProvider) openfeature.FeatureProvider {
+	return provider{sdk: p}
+}
+
+// sdkProvider implements the SDK's FeatureProvider on top of ours.
+type sdkProvider struct {
+	provider openfeature.FeatureProvider
+}
+
+func (p sdkProvider) Metadata() of.Metadata {
+	return of.Metadata{Name: p.provider.Metadata().Name}
+}
+
+func (p sdkProvider) BooleanEvaluation(ctx context.Context, flag string, defaultValue bool, flatCtx of.FlattenedContext) of.BoolResolutionDetail {
+	d := p.provider.BooleanEvaluation(ctx, flag, defaultValue, openfeature.FlattenedContext(flatCtx))
+	return of.BoolResolutionDetail{Value: d.Value, ProviderResolutionDetail: toSDKDetail(d)}
+}
+
+func (p sdkProvider) StringEvaluation(ctx context.Context, flag string, defaultValue string, flatCtx of.FlattenedContext) of.StringResolutionDetail {
+	d := p.provider.StringEvaluation(ctx, flag, defaultValue, openfeature.FlattenedContext(flatCtx))
+	return of.StringResolutionDetail{Value: d.Value, ProviderResolutionDetail: toSDKDetail(d)}
+}
+
+func (p sdkProvider) FloatEvaluation(ctx context.Context, flag string, defaultValue float64, flatCtx of.FlattenedContext) of.FloatResolutionDetail {
+	d := p.provider.FloatEvaluation(ctx, flag, defaultValue, openfeature.FlattenedContext(flatCtx))
+	return of.FloatResolutionDetail{Value: d.Value, ProviderResolutionDetail: toSDKDetail(d)}
+}
+
+func (p sdkProvider) IntEvaluation(ctx context.Context, flag string, defaultValue int64, flatCtx of.FlattenedContext) of.IntResolutionDetail {
+	d := p.provider.IntEvaluation(ctx, flag, defaultValue, openfeature.FlattenedContext(flatCtx))
+	return of.IntResolutionDetail{Value: d.Value, ProviderResolutionDetail: toSDKDetail(d)}
+}
+
+func (p sdkProvider) ObjectEvaluation(ctx context.Context, flag string, defaultValue any, flatCtx of.FlattenedContext) of.InterfaceResolutionDetail {
+	d := p.provider.ObjectEvaluation(ctx, flag, defaultValue, openfeature.FlattenedContext(flatCtx))
+	return of.InterfaceResolutionDetail{Value: d.Value, ProviderResolutionDetail: toSDKDetail(d)}
+}
+
+func (p sdkProvider) Hooks() []of.Hook {
+	return nil
+}
+
+func toSDKDetail[T any](d openfeature.ResolutionDetail[T]) of.ProviderResolutionDetail {
+	detail := of.ProviderResolutionDetail{
+		Reason:       of.Reason(d.Reason),
+		Variant:      d.Variant,
+		FlagMetadata: of.FlagMetadata(d.FlagMetadata),
+	}
+	if d.ErrorCode != "" {
+		detail.ResolutionError = resolutionError(d.ErrorCode, d.ErrorMessage)
+	}
+	return detail
+}
+
+// resolutionError builds the SDK's error for code. The SDK only exposes
+// constructors per code; codes it doesn't know become general errors.
+func resolutionError(code openfeature.ErrorCode, msg string) of.ResolutionError {
+	switch of.ErrorCode(code) {
+	case of.FlagNotFoundCode:
+		return of.NewFlagNotFoundResolutionError(msg)
+	case of.TypeMismatchCode:
+		return of.NewTypeMismatchResolutionError(msg)
+	case of.ParseErrorCode:
+		return of.NewParseErrorResolutionError(msg)
+	case of.InvalidContextCode:
+		return of.NewInvalidContextResolutionError(msg)
+	case of.TargetingKeyMissingCode:
+		return of.NewTargetingKeyMissingResolutionError(msg)
+	case of.ProviderNotReadyCode:
+		return of.NewProviderNotReadyResolutionError(msg)
+	}
+	return of.NewGeneralResolutionError(msg)
+}
+
+// provider implements our FeatureProvider on top of the SDK's.
+type provider struct {
+	sdk of.FeatureProvider
+}
+
+func (p provider) Metadata() openfeature.Metadata {
+	return openfeature.Metadata{Name: p.sdk.Metadata().Name}
+}
+
+func (p provider) BooleanEvaluation(ctx context.Context, flag string, defaultValue bool, evalCtx openfeature.FlattenedContext) openfeature.ResolutionDetail[bool] {
+	d := p.sdk.BooleanEvaluation(ctx, flag, defaultValue, of.FlattenedContext(evalCtx))
+	return fromSDKDetail(d.Value, d.ProviderResolutionDetail)
+}
+
+func (p provider) StringEvaluation(ctx context.Context, flag string, defaultValue string, evalCtx openfeature.FlattenedContext) openfeature.ResolutionDetail[string] {
+	d := p.sdk.StringEvaluation(ctx, flag, defaultValue, of.FlattenedContext(evalCtx))
+	return fromSDKDetail(d.Value, d.ProviderResolutionDetail)
+}
+
+func (p provider) FloatEvaluation(ctx context.Context, flag string, defaultValue float64, evalCtx openfeature.FlattenedContext) openfeature.ResolutionDetail[float64] {
+	d := p.sdk.FloatEvaluation(ctx, flag, defaultValue, of.FlattenedContext(evalCtx))
+	return fromSDKDetail(d.Value, d.ProviderResolutionDetail)
+}
+
+func (p provider) IntEvaluation(ctx context.Context, flag string, defaultValue int64, evalCtx openfeature.FlattenedContext) openfeature.ResolutionDetail[int64] {
+	d := p.sdk.IntEvaluation(ctx, flag, defaultValue, of.FlattenedContext(evalCtx))
+	return fromSDKDetail(d.Value, d.ProviderResolutionDetail)
+}
+
+func (p provider) ObjectEvaluation(ctx context.Context, flag string, defaultValue any, evalCtx openfeature.FlattenedContext) openfeature.ResolutionDetail[any] {
+	d := p.sdk.ObjectEvaluation(ctx, flag, defaultValue, of.FlattenedContext(evalCtx))
+	return fromSDKDetail(d.Value, d.ProviderResolutionDetail)
+}
+
+func fromSDKDetail[T any](value T, d of.ProviderResolutionDetail) openfeature.ResolutionDetail[T] {
+	detail := d.ResolutionDetail()
+	return openfeature.ResolutionDetail[T]{
+		Value:        value,
+		Variant:      detail.Variant,
+		Reason:       openfeature.Reason(detail.Reason),
+		ErrorCode:    openfeature.ErrorCode(detail.ErrorCode),
+		ErrorMessage: detail.ErrorMessage,
+		FlagMetadata: d.FlagMetadata,
+	}
+}
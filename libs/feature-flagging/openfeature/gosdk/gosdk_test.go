@@ -0,0 +1,87 @@
+package gosdk
+
+import (
+	"context"
+	"testing"
+
+	of "github.com/open-feature/go-sdk/openfeature"
+
+	featureflag "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+	"github.com/JWindy92/obelisk-platform/libs/feature-flagging/openfeature"
+)
+
+func newTestProvider(t *testing.T) *openfeature.Provider {
+	t.Helper()
+	static, err := featureflag.NewStaticProviderFromFlags(
+		featureflag.Flag{
+			Name:    "tenant-beta",
+			Enabled: true,
+			Rules: []featureflag.Rule{
+				{Attribute: "tenant", Operator: featureflag.OpEquals, Values: []string{"acme"}},
+			},
+		},
+		featureflag.Flag{
+			Name:     "algorithm",
+			Enabled:  true,
+			Variants: []featureflag.Variant{{Name: "v2", Value: "v2"}},
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewStaticProviderFromFlags() failed: %v", err)
+	}
+	return openfeature.NewProvider(featureflag.New(static))
+}
+
+func TestToSDK_Client(t *testing.T) {
+	if err := of.SetNamedProviderAndWait("gosdk-test", ToSDK(newTestProvider(t))); err != nil {
+		t.Fatalf("SetNamedProviderAndWait() failed: %v", err)
+	}
+	client := of.NewClient("gosdk-test")
+	ctx := context.Background()
+
+	acme := of.NewEvaluationContext("user-1", map[string]any{"tenant": "acme"})
+	details, err := client.BooleanValueDetails(ctx, "tenant-beta", false, acme)
+	if err != nil || !details.Value || details.Reason != of.TargetingMatchReason {
+		t.Errorf("BooleanValueDetails() = %+v, %v, want true by targeting match", details, err)
+	}
+
+	str, err := client.StringValueDetails(ctx, "algorithm", "v1", of.EvaluationContext{})
+	if err != nil || str.Value != "v2" || str.Variant != "v2" {
+		t.Errorf("StringValueDetails() = %+v, %v, want variant v2", str, err)
+	}
+
+	missing, err := client.BooleanValueDetails(ctx, "missing", true, of.EvaluationContext{})
+	if err == nil || !missing.Value || missing.ErrorCode != of.FlagNotFoundCode {
+		t.Errorf("BooleanValueDetails() of missing flag = %+v, %v, want default with %s", missing, err, of.FlagNotFoundCode)
+	}
+
+	mismatch, err := client.IntValueDetails(ctx, "algorithm", 7, of.EvaluationContext{})
+	if err == nil || mismatch.Value != 7 || mismatch.ErrorCode != of.TypeMismatchCode {
+		t.Errorf("IntValueDetails() of string variant = %+v, %v, want default with %s", mismatch, err, of.TypeMismatchCode)
+	}
+}
+
+func TestFromSDK_RoundTrip(t *testing.T) {
+	p := FromSDK(ToSDK(newTestProvider(t)))
+	ctx := context.Background()
+
+	if got := p.Metadata().Name; got != "obelisk-featureflag" {
+		t.Errorf("Metadata().Name = %q, want obelisk-featureflag", got)
+	}
+
+	got := p.BooleanEvaluation(ctx, "tenant-beta", false, openfeature.FlattenedContext{openfeature.TargetingKey: "user-1", "tenant": "acme"})
+	if !got.Value || got.Reason != openfeature.TargetingMatchReason || got.ErrorCode != "" {
+		t.Errorf("BooleanEvaluation() = %+v, want true by targeting match", got)
+	}
+
+	missing := p.StringEvaluation(ctx, "missing", "fallback", nil)
+	if missing.Value != "fallback" || missing.Reason != openfeature.ErrorReason || missing.ErrorCode != openfeature.FlagNotFoundCode {
+		t.Errorf("StringEvaluation() of missing flag = %+v, want fallback with %s", missing, openfeature.FlagNotFoundCode)
+	}
+
+	// The converted provider backs a featureflag.Provider like any other.
+	flags := openfeature.NewFlagProvider(p, nil)
+	if flag, ok := flags.Flag("algorithm"); !ok || !flag.Enabled {
+		t.Errorf("Flag(algorithm) = %+v, %v, want enabled", flag, ok)
+	}
+}
@@ -0,0 +1,276 @@
+package openfeature
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	featureflag "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+)
+
+func newTestManager(t *testing.T) *featureflag.Manager {
+	t.Helper()
+	provider, err := featureflag.NewStaticProviderFromFlags(
+		featureflag.Flag{Name: "static-on", Enabled: true},
+		featureflag.Flag{Name: "disabled", Enabled: false},
+		featureflag.Flag{
+			Name:    "tenant-beta",
+			Enabled: true,
+			Rules: []featureflag.Rule{
+				{Attribute: "tenant", Operator: featureflag.OpEquals, Values: []string{"acme"}},
+			},
+		},
+		featureflag.Flag{Name: "full-rollout", Enabled: true, Rollout: &featureflag.Rollout{Percentage: 100}},
+		featureflag.Flag{
+			Name:     "algorithm",
+			Enabled:  true,
+			Variants: []featureflag.Variant{{Name: "v2", Value: "v2"}},
+		},
+		featureflag.Flag{
+			Name:     "max-batch",
+			Enabled:  true,
+			Variants: []featureflag.Variant{{Name: "large", Value: float64(500)}},
+		},
+		featureflag.Flag{
+			Name:     "limits",
+			Enabled:  true,
+			Variants: []featureflag.Variant{{Name: "strict", Value: map[string]any{"requests": 10}}},
+		},
+		featureflag.Flag{
+			Name:           "theme",
+			Enabled:        false,
+			DefaultVariant: "light",
+			Variants:       []featureflag.Variant{{Name: "light", Value: "light"}, {Name: "dark", Value: "dark"}},
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewStaticProviderFromFlags() failed: %v", err)
+	}
+	return featureflag.New(provider)
+}
+
+func TestProvider_BooleanEvaluation(t *testing.T) {
+	p := NewProvider(newTestManager(t))
+	ctx := context.Background()
+	acme := FlattenedContext{TargetingKey: "user-1", "tenant": "acme"}
+	other := FlattenedContext{TargetingKey: "user-2", "tenant": "globex"}
+
+	tests := []struct {
+		name       string
+		flag       string
+		evalCtx    FlattenedContext
+		defaultVal bool
+		wantValue  bool
+		wantReason Reason
+		wantCode   ErrorCode
+	}{
+		{name: "static", flag: "static-on", wantValue: true, wantReason: StaticReason},
+		{name: "disabled", flag: "disabled", defaultVal: true, wantValue: false, wantReason: DisabledReason},
+		{name: "targeting match", flag: "tenant-beta", evalCtx: acme, wantValue: true, wantReason: TargetingMatchReason},
+		{name: "targeting miss", flag: "tenant-beta", evalCtx: other, wantValue: false, wantReason: DefaultReason},
+		{name: "split", flag: "full-rollout", evalCtx: acme, wantValue: true, wantReason: SplitReason},
+		{name: "not found", flag: "missing", defaultVal: true, wantValue: true, wantReason: ErrorReason, wantCode: FlagNotFoundCode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := p.BooleanEvaluation(ctx, tt.flag, tt.defaultVal, tt.evalCtx)
+			if got.Value != tt.wantValue || got.Reason != tt.wantReason || got.ErrorCode != tt.wantCode {
+				t.Errorf("BooleanEvaluation() = %+v, want value=%v reason=%s code=%s", got, tt.wantValue, tt.wantReason, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestProvider_TypedEvaluation(t *testing.T) {
+	p := NewProvider(newTestManager(t))
+	ctx := context.Background()
+
+	if got := p.StringEvaluation(ctx, "algorithm", "v1", nil); got.Value != "v2" || got.Variant != "v2" || got.Reason != StaticReason {
+		t.Errorf("StringEvaluation() = %+v", got)
+	}
+	if got := p.StringEvaluation(ctx, "max-batch", "x", nil); got.Value != "x" || got.ErrorCode != TypeMismatchCode {
+		t.Errorf("StringEvaluation() mismatch = %+v", got)
+	}
+	if got := p.StringEvaluation(ctx, "theme", "system", nil); got.Value != "light" || got.Reason != DisabledReason {
+		t.Errorf("StringEvaluation() disabled = %+v, want light default variant", got)
+	}
+	if got := p.StringEvaluation(ctx, "missing", "v1", nil); got.Value != "v1" || got.ErrorCode != FlagNotFoundCode {
+		t.Errorf("StringEvaluation() missing = %+v", got)
+	}
+	if got := p.IntEvaluation(ctx, "max-batch", 100, nil); got.Value != 500 || got.ErrorCode != "" {
+		t.Errorf("IntEvaluation() = %+v", got)
+	}
+	if got := p.FloatEvaluation(ctx, "max-batch", 1, nil); got.Value != 500 {
+		t.Errorf("FloatEvaluation() = %+v", got)
+	}
+	if got := p.IntEvaluation(ctx, "algorithm", 1, nil); got.Value != 1 || got.ErrorCode != TypeMismatchCode {
+		t.Errorf("IntEvaluation() mismatch = %+v", got)
+	}
+
+	got := p.ObjectEvaluation(ctx, "limits", nil, nil)
+	limits, ok := got.Value.(map[string]any)
+	if !ok || limits["requests"] != 10 {
+		t.Errorf("ObjectEvaluation() = %+v", got)
+	}
+}
+
+func TestProvider_Overrides(t *testing.T) {
+	p := NewProvider(newTestManager(t))
+	ctx := featureflag.WithOverrides(context.Background(), map[string]bool{"disabled": true, "missing": true})
+
+	got := p.BooleanEvaluation(ctx, "disabled", false, nil)
+	if !got.Value || got.Reason != StaticReason {
+		t.Errorf("BooleanEvaluation() with override = %+v", got)
+	}
+
+	// Overrides apply to flags the provider doesn't define, as they do for
+	// the Manager.
+	got = p.BooleanEvaluation(ctx, "missing", false, nil)
+	if !got.Value || got.Reason != StaticReason || got.ErrorCode != "" {
+		t.Errorf("BooleanEvaluation() of undefined flag with override = %+v", got)
+	}
+}
+
+func TestProvider_RequestCache(t *testing.T) {
+	static, err := featureflag.NewStaticProviderFromFlags(featureflag.Flag{
+		Name:     "algorithm",
+		Enabled:  true,
+		Variants: []featureflag.Variant{{Name: "v2", Value: "v2"}},
+	})
+	if err != nil {
+		t.Fatalf("NewStaticProviderFromFlags() failed: %v", err)
+	}
+	p := NewProvider(featureflag.New(static))
+	ctx := featureflag.WithRequestCache(context.Background())
+
+	p.BooleanEvaluation(ctx, "algorithm", false, nil)
+	static.Set("algorithm", false)
+
+	// The cached evaluation still serves the flag, so the reason must
+	// describe that evaluation rather than the flag's new definition.
+	got := p.BooleanEvaluation(ctx, "algorithm", false, nil)
+	if !got.Value || got.Reason != StaticReason {
+		t.Errorf("BooleanEvaluation() after change = %+v, want value=true reason=%s", got, StaticReason)
+	}
+	str := p.StringEvaluation(ctx, "algorithm", "v1", nil)
+	if str.Value != "v2" || str.Variant != "v2" || str.Reason != StaticReason {
+		t.Errorf("StringEvaluation() after change = %+v, want v2 with reason %s", str, StaticReason)
+	}
+}
+
+func TestFlagProvider_RoundTrip(t *testing.T) {
+	// Expose a Manager over OpenFeature and consume it back as a Provider,
+	// as if it were a vendor's provider.
+	vendor := NewProvider(newTestManager(t))
+	m := featureflag.New(NewFlagProvider(vendor, FlattenedContext{"tenant": "acme"}))
+
+	tests := []struct {
+		flag        string
+		wantEnabled bool
+		wantExists  bool
+	}{
+		{flag: "static-on", wantEnabled: true, wantExists: true},
+		{flag: "disabled", wantEnabled: false, wantExists: true},
+		{flag: "tenant-beta", wantEnabled: true, wantExists: true},
+		{flag: "missing", wantEnabled: false, wantExists: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.flag, func(t *testing.T) {
+			enabled, exists := m.Provider().Lookup(tt.flag)
+			if enabled != tt.wantEnabled || exists != tt.wantExists {
+				t.Errorf("Lookup() = (%v, %v), want (%v, %v)", enabled, exists, tt.wantEnabled, tt.wantExists)
+			}
+		})
+	}
+
+}
+
+// vendorProvider is a minimal OpenFeature provider serving fixed values,
+// reporting TYPE_MISMATCH like SDK providers do.
+type vendorProvider struct {
+	values map[string]any
+}
+
+func resolveFixed[T any](v *vendorProvider, flag string, defaultValue T) ResolutionDetail[T] {
+	raw, ok := v.values[flag]
+	if !ok {
+		return ResolutionDetail[T]{Value: defaultValue, Reason: ErrorReason, ErrorCode: FlagNotFoundCode}
+	}
+	value, ok := raw.(T)
+	if !ok {
+		return ResolutionDetail[T]{Value: defaultValue, Reason: ErrorReason, ErrorCode: TypeMismatchCode}
+	}
+	return ResolutionDetail[T]{Value: value, Reason: StaticReason}
+}
+
+func (v *vendorProvider) Metadata() Metadata { return Metadata{Name: "vendor"} }
+
+func (v *vendorProvider) BooleanEvaluation(_ context.Context, flag string, defaultValue bool, _ FlattenedContext) ResolutionDetail[bool] {
+	return resolveFixed(v, flag, defaultValue)
+}
+
+func (v *vendorProvider) StringEvaluation(_ context.Context, flag string, defaultValue string, _ FlattenedContext) ResolutionDetail[string] {
+	return resolveFixed(v, flag, defaultValue)
+}
+
+func (v *vendorProvider) FloatEvaluation(_ context.Context, flag string, defaultValue float64, _ FlattenedContext) ResolutionDetail[float64] {
+	return resolveFixed(v, flag, defaultValue)
+}
+
+func (v *vendorProvider) IntEvaluation(_ context.Context, flag string, defaultValue int64, _ FlattenedContext) ResolutionDetail[int64] {
+	return resolveFixed(v, flag, defaultValue)
+}
+
+func (v *vendorProvider) ObjectEvaluation(_ context.Context, flag string, defaultValue any, _ FlattenedContext) ResolutionDetail[any] {
+	raw, ok := v.values[flag]
+	if !ok {
+		return ResolutionDetail[any]{Value: defaultValue, Reason: ErrorReason, ErrorCode: FlagNotFoundCode}
+	}
+	return ResolutionDetail[any]{Value: raw, Reason: StaticReason}
+}
+
+func TestFlagProvider_Variants(t *testing.T) {
+	vendor := &vendorProvider{values: map[string]any{
+		"new-checkout": true,
+		"algorithm":    "v2",
+		"max-batch":    float64(500),
+	}}
+	m := featureflag.New(NewFlagProvider(vendor, nil))
+	ctx := context.Background()
+
+	if !m.IsEnabled("new-checkout") {
+		t.Error("IsEnabled(new-checkout) = false, want true")
+	}
+	if got := m.StringVariant(ctx, "algorithm", featureflag.EvalContext{}, "v1"); got != "v2" {
+		t.Errorf("StringVariant() = %q, want v2", got)
+	}
+	if got := m.IntVariant(ctx, "max-batch", featureflag.EvalContext{}, 100); got != 500 {
+		t.Errorf("IntVariant() = %d, want 500", got)
+	}
+	if got := m.StringVariant(ctx, "missing", featureflag.EvalContext{}, "v1"); got != "v1" {
+		t.Errorf("StringVariant() missing = %q, want v1", got)
+	}
+}
+
+func TestProvider_StatsCallSite(t *testing.T) {
+	flags, err := featureflag.NewStaticProviderFromFlags(featureflag.Flag{Name: "static-on", Enabled: true})
+	if err != nil {
+		t.Fatalf("NewStaticProviderFromFlags() failed: %v", err)
+	}
+	stats := featureflag.NewStats()
+	p := NewProvider(featureflag.New(flags, featureflag.WithHook(stats)))
+
+	p.BooleanEvaluation(context.Background(), "static-on", false, nil)
+
+	// The evaluation is attributed to the caller, not to the adapter.
+	snapshot := stats.Snapshot()
+	if len(snapshot) != 1 || len(snapshot[0].CallSites) != 1 {
+		t.Fatalf("Snapshot() = %+v, want one call site", snapshot)
+	}
+	for site := range snapshot[0].CallSites {
+		if !strings.Contains(site, "openfeature_test.go:") {
+			t.Errorf("call site = %s, want openfeature_test.go", site)
+		}
+	}
+}
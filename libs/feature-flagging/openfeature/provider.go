@@ -0,0 +1,175 @@
+package openfeature
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	featureflag "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+)
+
+// Provider exposes a featureflag.Manager as an OpenFeature provider.
+// Evaluations go through the Manager, so its hooks and context overrides
+// apply.
+type Provider struct {
+	manager *featureflag.Manager
+}
+
+var _ FeatureProvider = (*Provider)(nil)
+
+// NewProvider creates an OpenFeature provider backed by m.
+func NewProvider(m *featureflag.Manager) *Provider {
+	return &Provider{manager: m}
+}
+
+// Metadata returns the provider's name.
+func (p *Provider) Metadata() Metadata {
+	return Metadata{Name: "obelisk-featureflag"}
+}
+
+// BooleanEvaluation resolves whether the flag is enabled for evalCtx.
+// An override on ctx resolves the flag even if the provider doesn't define
+// it, as it does for the Manager.
+func (p *Provider) BooleanEvaluation(ctx context.Context, flagName string, defaultValue bool, evalCtx FlattenedContext) ResolutionDetail[bool] {
+	eval := p.manager.Evaluate(ctx, flagName, toEvalContext(evalCtx))
+	if !eval.Exists && !eval.Overridden {
+		return notFound(flagName, defaultValue)
+	}
+	return ResolutionDetail[bool]{
+		Value:  eval.Enabled,
+		Reason: reasonFor(eval),
+	}
+}
+
+// StringEvaluation resolves the flag's variant to a string.
+func (p *Provider) StringEvaluation(ctx context.Context, flagName string, defaultValue string, evalCtx FlattenedContext) ResolutionDetail[string] {
+	return resolveVariant(ctx, p.manager, flagName, defaultValue, evalCtx, func(v any) (string, bool) {
+		s, ok := v.(string)
+		return s, ok
+	})
+}
+
+// FloatEvaluation resolves the flag's variant to a number.
+func (p *Provider) FloatEvaluation(ctx context.Context, flagName string, defaultValue float64, evalCtx FlattenedContext) ResolutionDetail[float64] {
+	return resolveVariant(ctx, p.manager, flagName, defaultValue, evalCtx, toFloat)
+}
+
+// IntEvaluation resolves the flag's variant to a whole number.
+func (p *Provider) IntEvaluation(ctx context.Context, flagName string, defaultValue int64, evalCtx FlattenedContext) ResolutionDetail[int64] {
+	return resolveVariant(ctx, p.manager, flagName, defaultValue, evalCtx, func(v any) (int64, bool) {
+		f, ok := toFloat(v)
+		if !ok || f != math.Trunc(f) || f >= math.MaxInt64 || f < math.MinInt64 {
+			return 0, false
+		}
+		return int64(f), true
+	})
+}
+
+// ObjectEvaluation resolves the flag's variant to its raw value.
+func (p *Provider) ObjectEvaluation(ctx context.Context, flagName string, defaultValue any, evalCtx FlattenedContext) ResolutionDetail[any] {
+	return resolveVariant(ctx, p.manager, flagName, defaultValue, evalCtx, func(v any) (any, bool) {
+		return v, true
+	})
+}
+
+// resolveVariant serves the flag's variant converted by convert, or
+// defaultValue with the appropriate reason or error code. The variant and
+// reason both come from a single evaluation, so they agree even if the flag
+// changes concurrently.
+func resolveVariant[T any](ctx context.Context, m *featureflag.Manager, flagName string, defaultValue T, evalCtx FlattenedContext, convert func(any) (T, bool)) ResolutionDetail[T] {
+	eval := m.Evaluate(ctx, flagName, toEvalContext(evalCtx))
+	if !eval.Exists {
+		return notFound(flagName, defaultValue)
+	}
+
+	variant, served := eval.Flag.Variant(eval.Variant)
+	if !served {
+		return ResolutionDetail[T]{Value: defaultValue, Reason: reasonFor(eval)}
+	}
+
+	value, ok := convert(variant.Value)
+	if !ok {
+		return ResolutionDetail[T]{
+			Value:        defaultValue,
+			Reason:       ErrorReason,
+			ErrorCode:    TypeMismatchCode,
+			ErrorMessage: fmt.Sprintf("variant %q of flag %q is %T, not %T", variant.Name, flagName, variant.Value, defaultValue),
+		}
+	}
+	return ResolutionDetail[T]{
+		Value:   value,
+		Variant: variant.Name,
+		Reason:  reasonFor(eval),
+	}
+}
+
+// reasonFor explains the resolution of a flag from its evaluation.
+func reasonFor(eval featureflag.Evaluation) Reason {
+	flag := eval.Flag
+	switch {
+	case eval.Overridden:
+		return StaticReason
+	case !flag.Enabled:
+		return DisabledReason
+	case !eval.Enabled:
+		return DefaultReason
+	case flag.Rollout != nil || hasWeights(flag.Variants):
+		return SplitReason
+	case len(flag.Rules) > 0:
+		return TargetingMatchReason
+	}
+	return StaticReason
+}
+
+func hasWeights(variants []featureflag.Variant) bool {
+	for _, v := range variants {
+		if v.Weight > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func notFound[T any](flagName string, defaultValue T) ResolutionDetail[T] {
+	return ResolutionDetail[T]{
+		Value:        defaultValue,
+		Reason:       ErrorReason,
+		ErrorCode:    FlagNotFoundCode,
+		ErrorMessage: fmt.Sprintf("flag %q not found", flagName),
+	}
+}
+
+// toEvalContext maps the targeting key to the user ID and every other
+// scalar attribute to its string form.
+func toEvalContext(evalCtx FlattenedContext) featureflag.EvalContext {
+	ec := featureflag.EvalContext{Attributes: make(map[string]string, len(evalCtx))}
+	for key, value := range evalCtx {
+		if key == TargetingKey {
+			ec.UserID = fmt.Sprint(value)
+			continue
+		}
+		switch value.(type) {
+		case string, bool, int, int64, float64:
+			ec.Attributes[key] = fmt.Sprint(value)
+		}
+	}
+	return ec
+}
+
+func toFloat(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,83 @@
+// Package openfeature bridges featureflag and the OpenFeature specification
+// (https://openfeature.dev/specification/sections/providers).
+//
+// Provider exposes a featureflag.Manager as an OpenFeature provider, with
+// boolean, string, number and object resolution reporting reasons, variants
+// and error codes. FlagProvider goes the other way, backing a
+// featureflag.Provider with any OpenFeature provider.
+//
+// The types here are local look-alikes of the provider contract in the
+// OpenFeature Go SDK (github.com/open-feature/go-sdk/openfeature), so this
+// package does not depend on the SDK. Reason and error code values are the
+// spec's strings. The gosdk subpackage converts in both directions, to
+// register Provider with the SDK or to wrap an SDK provider for
+// FlagProvider.
+package openfeature
+
+import "context"
+
+// TargetingKey is the evaluation context key holding the subject's ID,
+// which maps to featureflag.EvalContext.UserID.
+const TargetingKey = "targetingKey"
+
+// FlattenedContext is an evaluation context: the targeting key plus
+// arbitrary attributes.
+type FlattenedContext map[string]any
+
+// Reason explains how a flag value was resolved.
+type Reason string
+
+const (
+	// TargetingMatchReason means the value was chosen by targeting rules.
+	TargetingMatchReason Reason = "TARGETING_MATCH"
+	// SplitReason means the value was chosen by a percentage rollout or
+	// weighted variant split.
+	SplitReason Reason = "SPLIT"
+	// DisabledReason means the flag is disabled.
+	DisabledReason Reason = "DISABLED"
+	// DefaultReason means the flag is off for the context and the default
+	// value or variant was served.
+	DefaultReason Reason = "DEFAULT"
+	// StaticReason means the flag serves the same value to everyone.
+	StaticReason Reason = "STATIC"
+	// ErrorReason means resolution failed; see the error code.
+	ErrorReason Reason = "ERROR"
+)
+
+// ErrorCode classifies resolution failures.
+type ErrorCode string
+
+const (
+	FlagNotFoundCode   ErrorCode = "FLAG_NOT_FOUND"
+	TypeMismatchCode   ErrorCode = "TYPE_MISMATCH"
+	ParseErrorCode     ErrorCode = "PARSE_ERROR"
+	InvalidContextCode ErrorCode = "INVALID_CONTEXT"
+	GeneralCode        ErrorCode = "GENERAL"
+)
+
+// ResolutionDetail is the result of resolving a flag to a value of type T.
+// On error, Value holds the caller's default.
+type ResolutionDetail[T any] struct {
+	Value        T
+	Variant      string
+	Reason       Reason
+	ErrorCode    ErrorCode
+	ErrorMessage string
+	FlagMetadata map[string]any
+}
+
+// Metadata describes a provider.
+type Metadata struct {
+	Name string
+}
+
+// FeatureProvider is this package's version of the OpenFeature provider
+// contract; the gosdk subpackage converts it to and from the SDK's.
+type FeatureProvider interface {
+	Metadata() Metadata
+	BooleanEvaluation(ctx context.Context, flag string, defaultValue bool, evalCtx FlattenedContext) ResolutionDetail[bool]
+	StringEvaluation(ctx context.Context, flag string, defaultValue string, evalCtx FlattenedContext) ResolutionDetail[string]
+	FloatEvaluation(ctx context.Context, flag string, defaultValue float64, evalCtx FlattenedContext) ResolutionDetail[float64]
+	IntEvaluation(ctx context.Context, flag string, defaultValue int64, evalCtx FlattenedContext) ResolutionDetail[int64]
+	ObjectEvaluation(ctx context.Context, flag string, defaultValue any, evalCtx FlattenedContext) ResolutionDetail[any]
+}
@@ -0,0 +1,126 @@
+package featureflag
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the encoding of a flag definition file.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+// FormatFromPath infers the format from a file extension
+// (.json, .yaml, .yml or .toml).
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON, nil
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".toml":
+		return FormatTOML, nil
+	}
+	return "", fmt.Errorf("cannot infer flag file format from %q", path)
+}
+
+// definitionFile is the schema of a flag definition file. Flags are keyed
+// by name, so the name field inside each definition is optional.
+type definitionFile struct {
+	Flags map[string]Flag `json:"flags"`
+}
+
+// ParseFlags parses a flag definition document:
+//
+//	flags:
+//	  new-checkout:
+//	    enabled: true
+//	    rules:
+//	      - attribute: tenant
+//	        operator: in
+//	        values: [acme]
+//	    rollout:
+//	      percentage: 10
+//
+// Every format uses the same field names as the JSON encoding of Flag.
+// Unknown fields are rejected and the flags are validated with
+// ValidateFlags, so typos and dependency cycles fail loudly instead of
+// silently disabling a flag. Flags are returned sorted by name.
+func ParseFlags(data []byte, format Format) ([]Flag, error) {
+	jsonData, err := toJSON(data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(jsonData))
+	dec.DisallowUnknownFields()
+	var file definitionFile
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse flag definitions: %w", err)
+	}
+
+	flags := make([]Flag, 0, len(file.Flags))
+	for name, flag := range file.Flags {
+		if flag.Name != "" && flag.Name != name {
+			return nil, fmt.Errorf("flag %q declares mismatched name %q", name, flag.Name)
+		}
+		flag.Name = name
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	if err := ValidateFlags(flags); err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+// MarshalFlags encodes flags as a JSON definition document, the inverse of
+// ParseFlags with FormatJSON.
+func MarshalFlags(flags []Flag) ([]byte, error) {
+	file := definitionFile{Flags: make(map[string]Flag, len(flags))}
+	for _, flag := range flags {
+		file.Flags[flag.Name] = flag
+	}
+	data, err := json.Marshal(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode flag definitions: %w", err)
+	}
+	return data, nil
+}
+
+// toJSON converts YAML and TOML documents to JSON so all formats share the
+// strict JSON decoding of Flag.
+func toJSON(data []byte, format Format) ([]byte, error) {
+	var doc map[string]any
+	switch format {
+	case FormatJSON:
+		return data, nil
+	case FormatYAML:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML flag definitions: %w", err)
+		}
+	case FormatTOML:
+		if err := toml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse TOML flag definitions: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported flag file format %q", format)
+	}
+
+	jsonData, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert flag definitions: %w", err)
+	}
+	return jsonData, nil
+}
@@ -1,8 +1,23 @@
 package featureflag
 
+import "errors"
+
+// ErrFlagNotFound is returned by providers that read or change flags in a
+// backing store when the flag doesn't exist.
+var ErrFlagNotFound = errors.New("feature flag not found")
+
 // Provider defines the interface for retrieving feature flag states.
 // This allows different backends (static config, database, remote service, etc.)
 type Provider interface {
 	// IsEnabled checks if a feature flag is enabled.
 	IsEnabled(flagName string) bool
+
+	// Lookup reports whether a feature flag is enabled and whether the
+	// provider defines it at all, so a flag that is explicitly off can be
+	// told apart from a missing one.
+	Lookup(flagName string) (enabled, exists bool)
+
+	// Flag returns the full definition of a flag and whether it exists.
+	// The Manager evaluates targeting rules, rollouts and variants from it.
+	Flag(flagName string) (Flag, bool)
 }
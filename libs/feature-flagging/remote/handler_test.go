@@ -0,0 +1,50 @@
+package remote
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	featureflag "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+)
+
+func TestHandler(t *testing.T) {
+	h := NewHandler(featureflag.NewStaticProvider(map[string]bool{"f": true}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/flags", nil))
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" {
+		t.Fatalf("GET = %d with ETag %q", rec.Code, etag)
+	}
+	flags, err := featureflag.ParseFlags(rec.Body.Bytes(), featureflag.FormatJSON)
+	if err != nil || len(flags) != 1 || !flags[0].Enabled {
+		t.Errorf("ParseFlags(body) = %+v, %v", flags, err)
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		ifNoneMatch string
+		wantStatus  int
+	}{
+		{name: "matching etag", method: "GET", ifNoneMatch: etag, wantStatus: http.StatusNotModified},
+		{name: "weak etag in list", method: "GET", ifNoneMatch: `"other", W/` + etag, wantStatus: http.StatusNotModified},
+		{name: "stale etag", method: "GET", ifNoneMatch: `"other"`, wantStatus: http.StatusOK},
+		{name: "head", method: "HEAD", wantStatus: http.StatusOK},
+		{name: "post", method: "POST", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/flags", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
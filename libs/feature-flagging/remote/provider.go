@@ -0,0 +1,247 @@
+// Package remote shares one flag source between services over HTTP.
+//
+// A Provider polls a flag endpoint and serves the flags it returns; Handler
+// is the matching endpoint, serving any featureflag.Lister such as a
+// FileProvider or storeprovider.Provider. The wire format is the JSON flag
+// definition document read by featureflag.ParseFlags, and responses carry
+// an ETag so unchanged flags cost a 304 rather than a full transfer.
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	featureflag "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+)
+
+// Config holds configuration options for a Provider.
+type Config struct {
+	// URL is the flag endpoint, e.g. "http://flagserver:8080/flags".
+	URL string
+
+	// PollInterval is how often RunPoller fetches the flags.
+	// Defaults to 30 seconds if not specified.
+	PollInterval time.Duration
+
+	// Client makes the requests.
+	// Defaults to a client with a 10 second timeout if not specified.
+	Client *http.Client
+
+	// Header is added to every request, e.g. for an Authorization token.
+	Header http.Header
+
+	// SnapshotPath, if set, is where the last fetched flags are saved.
+	// A snapshot left by a previous run is loaded by NewProvider, so the
+	// service starts with its last known flags even if the endpoint is down.
+	// The snapshot is a JSON flag definition file.
+	SnapshotPath string
+}
+
+// Provider serves flags fetched from a remote endpoint. Lookups are served
+// from memory; Refresh and RunPoller update them with conditional requests.
+// When the endpoint is unreachable or returns invalid flags, the last known
+// flags stay in effect. Changes picked up by a refresh are reported to
+// watchers registered with Watch.
+type Provider struct {
+	featureflag.Broadcaster
+
+	config Config
+	flags  atomic.Pointer[map[string]featureflag.Flag]
+
+	// mu serializes refreshes; lookups never take it.
+	mu   sync.Mutex
+	etag string
+}
+
+var (
+	_ featureflag.Provider  = (*Provider)(nil)
+	_ featureflag.Watchable = (*Provider)(nil)
+	_ featureflag.Lister    = (*Provider)(nil)
+)
+
+// NewProvider creates a provider for the endpoint at config.URL, loading
+// the snapshot at config.SnapshotPath if one exists. It does not contact
+// the endpoint; call Refresh for the first fetch. Until flags are loaded
+// from either source every flag is reported as disabled.
+func NewProvider(config Config) (*Provider, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("remote flag URL is required")
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 30 * time.Second
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	p := &Provider{config: config}
+	p.flags.Store(&map[string]featureflag.Flag{})
+
+	if config.SnapshotPath != "" {
+		data, err := os.ReadFile(config.SnapshotPath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("failed to read flag snapshot: %w", err)
+		default:
+			flags, err := featureflag.ParseFlags(data, featureflag.FormatJSON)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load flag snapshot %s: %w", config.SnapshotPath, err)
+			}
+			p.swap(flags)
+		}
+	}
+	return p, nil
+}
+
+// IsEnabled checks if a feature flag is enabled.
+// Returns false if the flag doesn't exist (fail-safe default).
+func (p *Provider) IsEnabled(flagName string) bool {
+	flag, _ := p.Flag(flagName)
+	return flag.Enabled
+}
+
+// Lookup reports whether a feature flag is enabled and whether it exists.
+func (p *Provider) Lookup(flagName string) (enabled, exists bool) {
+	flag, exists := p.Flag(flagName)
+	return flag.Enabled, exists
+}
+
+// Flag returns the definition of a flag and whether it exists.
+func (p *Provider) Flag(flagName string) (featureflag.Flag, bool) {
+	flag, exists := (*p.flags.Load())[flagName]
+	return flag, exists
+}
+
+// Flags returns every known flag, sorted by name.
+func (p *Provider) Flags() []featureflag.Flag {
+	flags := *p.flags.Load()
+	list := make([]featureflag.Flag, 0, len(flags))
+	for _, flag := range flags {
+		list = append(list, flag)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Refresh fetches the flags if they changed since the last fetch and
+// swaps them in. On error the current flags are kept.
+func (p *Provider) Refresh(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.config.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create flag request: %w", err)
+	}
+	for name, values := range p.config.Header {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.etag != "" {
+		req.Header.Set("If-None-Match", p.etag)
+	}
+
+	resp, err := p.config.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch flags: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil
+	case http.StatusOK:
+	default:
+		return fmt.Errorf("failed to fetch flags: unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read flags: %w", err)
+	}
+	flags, err := featureflag.ParseFlags(data, featureflag.FormatJSON)
+	if err != nil {
+		return fmt.Errorf("failed to load flags from %s: %w", p.config.URL, err)
+	}
+
+	p.etag = resp.Header.Get("ETag")
+	p.Publish(p.swap(flags)...)
+
+	if p.config.SnapshotPath != "" {
+		if err := writeSnapshot(p.config.SnapshotPath, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunPoller calls Refresh every PollInterval until ctx is cancelled.
+// Errors are passed to onError, which may be nil. Run it in its own
+// goroutine:
+//
+//	go flags.RunPoller(ctx, func(err error) { log.Print(err) })
+func (p *Provider) RunPoller(ctx context.Context, onError func(error)) {
+	ticker := time.NewTicker(p.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.Refresh(ctx); err != nil && onError != nil && ctx.Err() == nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// swap replaces the flag set and returns what changed.
+func (p *Provider) swap(flags []featureflag.Flag) []featureflag.FlagChange {
+	next := make(map[string]featureflag.Flag, len(flags))
+	for _, flag := range flags {
+		next[flag.Name] = flag
+	}
+	previous := p.flags.Swap(&next)
+	if previous == nil {
+		return nil
+	}
+	return featureflag.Diff(*previous, next)
+}
+
+// maxBodyBytes caps flag responses.
+const maxBodyBytes = 10 << 20
+
+// writeSnapshot replaces the snapshot atomically, so a crash mid-write
+// never leaves a truncated file behind.
+func writeSnapshot(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write flag snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write flag snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write flag snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write flag snapshot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	featureflag "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+)
+
+// newTestServer serves source and counts the full responses it sends.
+func newTestServer(t *testing.T, source featureflag.Lister) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var full atomic.Int64
+	handler := NewHandler(source)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		if rec.Code == http.StatusOK {
+			full.Add(1)
+		}
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.Code)
+		w.Write(rec.Body.Bytes())
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &full
+}
+
+func TestProvider_Refresh(t *testing.T) {
+	source, err := featureflag.NewStaticProviderFromFlags(
+		featureflag.Flag{Name: "new-checkout", Enabled: true, Rollout: &featureflag.Rollout{Percentage: 25}},
+		featureflag.Flag{Name: "beta-api", Enabled: false},
+	)
+	if err != nil {
+		t.Fatalf("NewStaticProviderFromFlags() failed: %v", err)
+	}
+	srv, full := newTestServer(t, source)
+	ctx := context.Background()
+
+	p, err := NewProvider(Config{URL: srv.URL})
+	if err != nil {
+		t.Fatalf("NewProvider() unexpected error: %v", err)
+	}
+	if _, exists := p.Lookup("new-checkout"); exists {
+		t.Error("Lookup() found a flag before the first refresh")
+	}
+
+	if err := p.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+	flag, ok := p.Flag("new-checkout")
+	if !ok || !flag.Enabled || flag.Rollout == nil || flag.Rollout.Percentage != 25 {
+		t.Errorf("Flag(new-checkout) = %+v, %v", flag, ok)
+	}
+	if len(p.Flags()) != 2 {
+		t.Errorf("Flags() = %+v, want 2 flags", p.Flags())
+	}
+
+	// Unchanged flags are answered with 304 Not Modified.
+	if err := p.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+	if got := full.Load(); got != 1 {
+		t.Errorf("server sent %d full responses, want 1", got)
+	}
+
+	var changes []featureflag.FlagChange
+	p.Watch(func(c featureflag.FlagChange) { changes = append(changes, c) })
+	source.Set("beta-api", true)
+	if err := p.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+	if !p.IsEnabled("beta-api") {
+		t.Error("IsEnabled(beta-api) = false after change")
+	}
+	if len(changes) != 1 || changes[0].Name != "beta-api" {
+		t.Errorf("changes = %+v, want beta-api", changes)
+	}
+	if got := full.Load(); got != 2 {
+		t.Errorf("server sent %d full responses, want 2", got)
+	}
+}
+
+func TestProvider_KeepsLastKnownFlags(t *testing.T) {
+	source := featureflag.NewStaticProvider(map[string]bool{"f": true})
+	srv, _ := newTestServer(t, source)
+	ctx := context.Background()
+
+	p, err := NewProvider(Config{URL: srv.URL})
+	if err != nil {
+		t.Fatalf("NewProvider() unexpected error: %v", err)
+	}
+	if err := p.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+
+	srv.Close()
+	if err := p.Refresh(ctx); err == nil {
+		t.Error("Refresh() with endpoint down succeeded")
+	}
+	if !p.IsEnabled("f") {
+		t.Error("IsEnabled(f) = false, want last known flags kept")
+	}
+}
+
+func TestProvider_RejectsInvalidFlags(t *testing.T) {
+	body := `{"flags": {"f": {"enabled": true}}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	p, _ := NewProvider(Config{URL: srv.URL})
+	if err := p.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+
+	body = `{"flags": {"f": {"enabeld": false}}}`
+	if err := p.Refresh(context.Background()); err == nil {
+		t.Error("Refresh() accepted invalid flags")
+	}
+	if !p.IsEnabled("f") {
+		t.Error("IsEnabled(f) = false, want last good flags kept")
+	}
+}
+
+func TestProvider_Snapshot(t *testing.T) {
+	source := featureflag.NewStaticProvider(map[string]bool{"f": true})
+	srv, _ := newTestServer(t, source)
+	snapshot := filepath.Join(t.TempDir(), "flags.json")
+
+	p, err := NewProvider(Config{URL: srv.URL, SnapshotPath: snapshot})
+	if err != nil {
+		t.Fatalf("NewProvider() unexpected error: %v", err)
+	}
+	if err := p.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+	srv.Close()
+
+	// A restarted service starts from the snapshot while the endpoint is down.
+	restarted, err := NewProvider(Config{URL: srv.URL, SnapshotPath: snapshot})
+	if err != nil {
+		t.Fatalf("NewProvider() unexpected error: %v", err)
+	}
+	if err := restarted.Refresh(context.Background()); err == nil {
+		t.Error("Refresh() with endpoint down succeeded")
+	}
+	if !restarted.IsEnabled("f") {
+		t.Error("IsEnabled(f) = false, want flags from snapshot")
+	}
+
+	// The snapshot is a plain definition file.
+	if _, err := featureflag.NewFileProvider(featureflag.FileConfig{Path: snapshot}); err != nil {
+		t.Errorf("NewFileProvider() on snapshot unexpected error: %v", err)
+	}
+
+	if err := os.WriteFile(snapshot, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewProvider(Config{URL: srv.URL, SnapshotPath: snapshot}); err == nil {
+		t.Error("NewProvider() accepted a corrupt snapshot")
+	}
+}
+
+func TestNewProvider_RequiresURL(t *testing.T) {
+	if _, err := NewProvider(Config{}); err == nil {
+		t.Error("NewProvider() without URL succeeded")
+	}
+}
+
+func TestProvider_Header(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.Write([]byte(`{"flags": {}}`))
+	}))
+	defer srv.Close()
+
+	p, _ := NewProvider(Config{URL: srv.URL, Header: http.Header{"Authorization": {"Bearer token"}}})
+	if err := p.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+	if auth != "Bearer token" {
+		t.Errorf("Authorization = %q, want Bearer token", auth)
+	}
+}
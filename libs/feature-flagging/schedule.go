@@ -0,0 +1,168 @@
+package featureflag
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Schedule limits when a flag can be on. Outside its schedule a flag is
+// off for everyone, as if disabled; inside it, the flag is evaluated as
+// usual. Schedules are evaluated against the Manager's clock (see
+// WithClock), so flags turn on and off without their definition changing.
+type Schedule struct {
+	// Start is when the flag turns on. Unset means no lower bound.
+	Start *time.Time `json:"start,omitempty"`
+
+	// End is when the flag turns off again. Unset means no upper bound.
+	End *time.Time `json:"end,omitempty"`
+
+	// Windows restrict the flag to recurring times of the week, such as
+	// business hours. The flag is on during any of them. With no windows
+	// it is on for the whole time between Start and End.
+	Windows []Window `json:"windows,omitempty"`
+
+	// Location is the IANA time zone Windows are interpreted in, such as
+	// "Europe/Berlin". Defaults to UTC if not specified.
+	Location string `json:"location,omitempty"`
+}
+
+// Window is a recurring daily time range, e.g. 09:00 to 17:00 on weekdays.
+// A window whose End is before its Start runs past midnight into the next
+// day, so 22:00 to 02:00 on "fri" covers Friday night until 2am Saturday.
+type Window struct {
+	// Days the window starts on, as lowercase three-letter names ("mon",
+	// "tue", ...). Empty means every day.
+	Days []string `json:"days,omitempty"`
+
+	// Start is the time of day the window opens, as "HH:MM".
+	Start string `json:"start"`
+
+	// End is the time of day the window closes, as "HH:MM", or "24:00"
+	// for midnight at the end of the day.
+	End string `json:"end"`
+}
+
+// dayNames are the accepted Window.Days, indexed by time.Weekday.
+var dayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// Active reports whether t falls within the schedule. A nil schedule is
+// always active.
+func (s *Schedule) Active(t time.Time) bool {
+	if s == nil {
+		return true
+	}
+	if s.Start != nil && t.Before(*s.Start) {
+		return false
+	}
+	if s.End != nil && !t.Before(*s.End) {
+		return false
+	}
+	if len(s.Windows) == 0 {
+		return true
+	}
+
+	loc, err := loadLocationCached(s.Location)
+	if err != nil {
+		return false
+	}
+	t = t.In(loc)
+	for _, w := range s.Windows {
+		if w.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schedule) validate() error {
+	if s.Start != nil && s.End != nil && !s.Start.Before(*s.End) {
+		return fmt.Errorf("schedule start must be before end")
+	}
+	if _, err := loadLocationCached(s.Location); err != nil {
+		return fmt.Errorf("invalid schedule location %q: %w", s.Location, err)
+	}
+	for i, w := range s.Windows {
+		if err := w.validate(); err != nil {
+			return fmt.Errorf("schedule window %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// contains reports whether t, already in the schedule's location, falls
+// within the window.
+func (w Window) contains(t time.Time) bool {
+	start, _ := parseClock(w.Start)
+	end, _ := parseClock(w.End)
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+
+	if start < end {
+		return w.onDay(day) && minute >= start && minute < end
+	}
+	// Overnight: the evening part belongs to today's window, the early
+	// morning part to yesterday's.
+	yesterday := (day + 6) % 7
+	return (w.onDay(day) && minute >= start) || (w.onDay(yesterday) && minute < end)
+}
+
+func (w Window) onDay(day time.Weekday) bool {
+	return len(w.Days) == 0 || slices.Contains(w.Days, dayNames[day])
+}
+
+func (w Window) validate() error {
+	for _, d := range w.Days {
+		if !slices.Contains(dayNames, d) {
+			return fmt.Errorf("unknown day %q, want one of %s", d, strings.Join(dayNames, ", "))
+		}
+	}
+	start, err := parseClock(w.Start)
+	if err != nil || start == 24*60 {
+		return fmt.Errorf("invalid start time %q, want HH:MM", w.Start)
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return fmt.Errorf("invalid end time %q, want HH:MM", w.End)
+	}
+	if start == end {
+		return fmt.Errorf("window start and end must differ")
+	}
+	return nil
+}
+
+// parseClock parses "HH:MM" into minutes since midnight. "24:00" is
+// accepted as the end of the day.
+func parseClock(s string) (int, error) {
+	var h, m int
+	if len(s) != 5 || s[2] != ':' {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	if _, err := fmt.Sscanf(s, "%02d:%02d", &h, &m); err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: %w", s, err)
+	}
+	if h == 24 && m == 0 {
+		return 24 * 60, nil
+	}
+	if h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return h*60 + m, nil
+}
+
+// locationCache avoids reloading time zone data on every evaluation.
+var locationCache sync.Map // map[string]*time.Location
+
+func loadLocationCached(name string) (*time.Location, error) {
+	if loc, ok := locationCache.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locationCache.Store(name, loc)
+	return loc, nil
+}
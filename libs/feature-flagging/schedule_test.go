@@ -0,0 +1,122 @@
+package featureflag
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSchedule_Active(t *testing.T) {
+	launch := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	sunset := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	berlin := "Europe/Berlin"
+
+	businessHours := []Window{{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "17:00"}}
+	lateNight := []Window{{Days: []string{"fri"}, Start: "22:00", End: "02:00"}}
+
+	tests := []struct {
+		name     string
+		schedule *Schedule
+		at       time.Time
+		want     bool
+	}{
+		{name: "nil schedule", schedule: nil, at: launch, want: true},
+		{name: "before start", schedule: &Schedule{Start: &launch}, at: launch.Add(-time.Second), want: false},
+		{name: "at start", schedule: &Schedule{Start: &launch}, at: launch, want: true},
+		{name: "before end", schedule: &Schedule{End: &sunset}, at: sunset.Add(-time.Second), want: true},
+		{name: "at end", schedule: &Schedule{End: &sunset}, at: sunset, want: false},
+		// 2025-03-03 is a Monday.
+		{name: "inside business hours", schedule: &Schedule{Windows: businessHours}, at: time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC), want: true},
+		{name: "after business hours", schedule: &Schedule{Windows: businessHours}, at: time.Date(2025, 3, 3, 17, 0, 0, 0, time.UTC), want: false},
+		{name: "weekend", schedule: &Schedule{Windows: businessHours}, at: time.Date(2025, 3, 2, 12, 0, 0, 0, time.UTC), want: false},
+		{
+			// 08:30 UTC is 09:30 in Berlin (CET, UTC+1).
+			name:     "window in location",
+			schedule: &Schedule{Windows: businessHours, Location: berlin},
+			at:       time.Date(2025, 3, 3, 8, 30, 0, 0, time.UTC),
+			want:     true,
+		},
+		{name: "overnight evening", schedule: &Schedule{Windows: lateNight}, at: time.Date(2025, 3, 7, 23, 0, 0, 0, time.UTC), want: true},
+		{name: "overnight next morning", schedule: &Schedule{Windows: lateNight}, at: time.Date(2025, 3, 8, 1, 59, 0, 0, time.UTC), want: true},
+		{name: "overnight wrong morning", schedule: &Schedule{Windows: lateNight}, at: time.Date(2025, 3, 7, 1, 0, 0, 0, time.UTC), want: false},
+		{
+			name:     "window outside start and end",
+			schedule: &Schedule{Start: &launch, Windows: []Window{{Start: "00:00", End: "24:00"}}},
+			at:       launch.Add(-time.Hour),
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.Active(tt.at); got != tt.want {
+				t.Errorf("Active(%v) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_Validate(t *testing.T) {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		wantErr  string
+	}{
+		{name: "end before start", schedule: Schedule{Start: &start, End: &end}, wantErr: "before end"},
+		{name: "unknown location", schedule: Schedule{Location: "Mars/Olympus"}, wantErr: "location"},
+		{name: "unknown day", schedule: Schedule{Windows: []Window{{Days: []string{"monday"}, Start: "09:00", End: "17:00"}}}, wantErr: "unknown day"},
+		{name: "bad time", schedule: Schedule{Windows: []Window{{Start: "9am", End: "17:00"}}}, wantErr: "invalid start time"},
+		{name: "out of range time", schedule: Schedule{Windows: []Window{{Start: "09:00", End: "25:00"}}}, wantErr: "invalid end time"},
+		{name: "empty window", schedule: Schedule{Windows: []Window{{Start: "09:00", End: "09:00"}}}, wantErr: "must differ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flag := Flag{Name: "f", Schedule: &tt.schedule}
+			err := flag.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestManager_Schedule(t *testing.T) {
+	flags, err := ParseFlags([]byte(`
+flags:
+  spring-sale:
+    enabled: true
+    schedule:
+      start: 2025-03-01T00:00:00Z
+      end: 2025-04-01T00:00:00Z
+      location: America/New_York
+      windows:
+        - {days: [mon, tue, wed, thu, fri], start: "09:00", end: "17:00"}
+`), FormatYAML)
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+
+	clock := time.Date(2025, 2, 28, 15, 0, 0, 0, time.UTC) // Friday, before start
+	m := New(newStaticProvider(t, flags...), WithClock(func() time.Time { return clock }))
+
+	steps := []struct {
+		at   time.Time
+		want bool
+	}{
+		{at: clock, want: false},
+		{at: time.Date(2025, 3, 3, 15, 0, 0, 0, time.UTC), want: true},  // Monday 10:00 in New York
+		{at: time.Date(2025, 3, 3, 23, 0, 0, 0, time.UTC), want: false}, // Monday 18:00 in New York
+		{at: time.Date(2025, 4, 1, 15, 0, 0, 0, time.UTC), want: false}, // after end
+	}
+	for _, step := range steps {
+		clock = step.at
+		if got := m.IsEnabledFor(context.Background(), "spring-sale", EvalContext{}); got != step.want {
+			t.Errorf("IsEnabledFor() at %v = %v, want %v", step.at, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package featureflag
+
+import (
+	"strconv"
+	"strings"
+)
+
+// semver is a parsed semantic version (https://semver.org).
+// Build metadata is ignored, as it does not affect precedence.
+type semver struct {
+	major, minor, patch uint64
+	pre                 []string
+}
+
+// parseSemver parses versions such as "1.2.3", "v1.2.3-beta.1" or "1.2".
+// Missing minor and patch components default to zero.
+func parseSemver(s string) (semver, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+
+	var v semver
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		if i == len(s)-1 {
+			return semver{}, false
+		}
+		v.pre = strings.Split(s[i+1:], ".")
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return semver{}, false
+	}
+	nums := [3]uint64{}
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return semver{}, false
+		}
+		nums[i] = n
+	}
+	v.major, v.minor, v.patch = nums[0], nums[1], nums[2]
+	return v, true
+}
+
+// compare returns -1, 0 or 1 as v is lower than, equal to or higher than o.
+func (v semver) compare(o semver) int {
+	for _, pair := range [][2]uint64{{v.major, o.major}, {v.minor, o.minor}, {v.patch, o.patch}} {
+		if pair[0] != pair[1] {
+			if pair[0] < pair[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	// A version without a pre-release has higher precedence.
+	switch {
+	case len(v.pre) == 0 && len(o.pre) == 0:
+		return 0
+	case len(v.pre) == 0:
+		return 1
+	case len(o.pre) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.pre) && i < len(o.pre); i++ {
+		if c := comparePrerelease(v.pre[i], o.pre[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(v.pre) < len(o.pre):
+		return -1
+	case len(v.pre) > len(o.pre):
+		return 1
+	}
+	return 0
+}
+
+// comparePrerelease compares one dot-separated pre-release identifier.
+// Numeric identifiers sort numerically and below alphanumeric ones.
+func comparePrerelease(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		if an == bn {
+			return 0
+		}
+		if an < bn {
+			return -1
+		}
+		return 1
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// matchSemver applies a semver operator. Unparseable versions never match.
+func matchSemver(op Operator, value, target string) bool {
+	v, ok := parseSemver(value)
+	if !ok {
+		return false
+	}
+	t, ok := parseSemver(target)
+	if !ok {
+		return false
+	}
+
+	c := v.compare(t)
+	switch op {
+	case OpSemverEq:
+		return c == 0
+	case OpSemverGt:
+		return c > 0
+	case OpSemverGte:
+		return c >= 0
+	case OpSemverLt:
+		return c < 0
+	case OpSemverLte:
+		return c <= 0
+	}
+	return false
+}
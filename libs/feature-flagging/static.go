@@ -1,32 +1,148 @@
 package featureflag
 
+import (
+	"context"
+	"maps"
+	"reflect"
+	"sync"
+)
+
 // StaticProvider is a simple in-memory provider backed by a map.
 // Useful for configuration files or simple use cases.
+// It is safe for concurrent use, so flags can be flipped at runtime while
+// requests are being served. Changes are reported to watchers registered
+// with Watch.
 type StaticProvider struct {
-	flags map[string]bool
+	Broadcaster
+
+	mu    sync.RWMutex
+	flags map[string]Flag
 }
 
+var (
+	_ Watchable = (*StaticProvider)(nil)
+	_ Lister    = (*StaticProvider)(nil)
+)
+
 // NewStaticProvider creates a provider with the given flag states.
 func NewStaticProvider(flags map[string]bool) *StaticProvider {
-	if flags == nil {
-		flags = make(map[string]bool)
+	s := &StaticProvider{
+		flags: make(map[string]Flag, len(flags)),
+	}
+	for name, enabled := range flags {
+		s.flags[name] = Flag{Name: name, Enabled: enabled}
+	}
+	return s
+}
+
+// NewStaticProviderFromFlags creates a provider from full flag definitions,
+// including targeting rules and rollouts. The flags must pass ValidateFlags.
+func NewStaticProviderFromFlags(flags ...Flag) (*StaticProvider, error) {
+	if err := ValidateFlags(flags); err != nil {
+		return nil, err
 	}
-	return &StaticProvider{
-		flags: flags,
+
+	s := &StaticProvider{
+		flags: make(map[string]Flag, len(flags)),
+	}
+	for _, flag := range flags {
+		s.flags[flag.Name] = flag
 	}
+	return s, nil
 }
 
 // IsEnabled checks if a feature flag is enabled.
 // Returns false if the flag doesn't exist (fail-safe default).
+// Only the master switch is considered; targeting is applied by the Manager.
 func (s *StaticProvider) IsEnabled(flagName string) bool {
-	enabled, exists := s.flags[flagName]
-	if !exists {
-		return false // Fail-safe: unknown flags are disabled
-	}
-	return enabled
+	flag, _ := s.Flag(flagName)
+	return flag.Enabled
+}
+
+// Lookup reports whether a feature flag is enabled and whether it exists.
+func (s *StaticProvider) Lookup(flagName string) (enabled, exists bool) {
+	flag, exists := s.Flag(flagName)
+	return flag.Enabled, exists
+}
+
+// Flag returns the definition of a flag and whether it exists.
+func (s *StaticProvider) Flag(flagName string) (Flag, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	flag, exists := s.flags[flagName]
+	return flag, exists
+}
+
+// Flags returns every defined flag, sorted by name.
+func (s *StaticProvider) Flags() []Flag {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return sortedFlags(s.flags)
 }
 
 // Set updates a flag's state (useful for testing or runtime changes).
+// Targeting rules and rollouts of an existing flag are kept.
 func (s *StaticProvider) Set(flagName string, enabled bool) {
-	s.flags[flagName] = enabled
+	s.mu.Lock()
+	flag := s.flags[flagName]
+	flag.Name = flagName
+	flag.Enabled = enabled
+	changes := s.put(flag)
+	s.mu.Unlock()
+	s.Publish(changes...)
+}
+
+// SetFlag adds or replaces a full flag definition. The flag is validated
+// together with the other flags, and rejected without changing anything if
+// it is invalid or would close a dependency cycle.
+func (s *StaticProvider) SetFlag(flag Flag) error {
+	s.mu.Lock()
+	candidates := make(map[string]Flag, len(s.flags)+1)
+	maps.Copy(candidates, s.flags)
+	candidates[flag.Name] = flag
+	if err := ValidateFlags(sortedFlags(candidates)); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	changes := s.put(flag)
+	s.mu.Unlock()
+	s.Publish(changes...)
+	return nil
+}
+
+// SaveFlag adds or replaces a full flag definition like SetFlag. It
+// implements the writable provider interface used by admin tools such as
+// httpapi.
+func (s *StaticProvider) SaveFlag(ctx context.Context, flag Flag) error {
+	return s.SetFlag(flag)
+}
+
+// Replace swaps the whole flag set in one step, so concurrent readers see
+// either the old set or the new one and never a mix. Flags missing from
+// flags are removed; targeting rules and rollouts of flags that remain are
+// kept.
+func (s *StaticProvider) Replace(flags map[string]bool) {
+	s.mu.Lock()
+	next := make(map[string]Flag, len(flags))
+	for name, enabled := range flags {
+		flag := s.flags[name]
+		flag.Name = name
+		flag.Enabled = enabled
+		next[name] = flag
+	}
+	changes := Diff(s.flags, next)
+	s.flags = next
+	s.mu.Unlock()
+	s.Publish(changes...)
+}
+
+// put stores flag and returns the resulting change, if any.
+// The caller must hold s.mu.
+func (s *StaticProvider) put(flag Flag) []FlagChange {
+	old, exists := s.flags[flag.Name]
+	s.flags[flag.Name] = flag
+	if exists && reflect.DeepEqual(old, flag) {
+		return nil
+	}
+	return []FlagChange{{Name: flag.Name, Old: old, New: flag}}
 }
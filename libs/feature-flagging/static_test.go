@@ -0,0 +1,108 @@
+package featureflag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func newStaticProvider(t *testing.T, flags ...Flag) *StaticProvider {
+	t.Helper()
+	p, err := NewStaticProviderFromFlags(flags...)
+	if err != nil {
+		t.Fatalf("NewStaticProviderFromFlags() failed: %v", err)
+	}
+	return p
+}
+
+func TestStaticProvider_Replace(t *testing.T) {
+	rule := Rule{Attribute: "tenant", Operator: OpEquals, Values: []string{"acme"}}
+	p := newStaticProvider(t,
+		Flag{Name: "targeted", Enabled: false, Rules: []Rule{rule}},
+		Flag{Name: "removed", Enabled: true},
+	)
+
+	p.Replace(map[string]bool{"targeted": true, "added": true})
+
+	if _, ok := p.Flag("removed"); ok {
+		t.Error("Replace() kept a flag missing from the new set")
+	}
+	if !p.IsEnabled("added") {
+		t.Error("IsEnabled(added) = false, want true")
+	}
+	flag, _ := p.Flag("targeted")
+	if !flag.Enabled || len(flag.Rules) != 1 {
+		t.Errorf("Flag(targeted) = %+v, want enabled with rules kept", flag)
+	}
+}
+
+func TestStaticProvider_Validates(t *testing.T) {
+	invalid := []struct {
+		name  string
+		flags []Flag
+	}{
+		{name: "missing name", flags: []Flag{{Enabled: true}}},
+		{name: "unknown operator", flags: []Flag{{Name: "f", Rules: []Rule{{Attribute: "tenant", Operator: "bogus"}}}}},
+		{name: "duplicate", flags: []Flag{{Name: "f"}, {Name: "f"}}},
+		{name: "cycle", flags: []Flag{{Name: "a", Prerequisites: []string{"b"}}, {Name: "b", KillSwitch: "a"}}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewStaticProviderFromFlags(tt.flags...); err == nil {
+				t.Error("NewStaticProviderFromFlags() expected error but got nil")
+			}
+		})
+	}
+
+	p := newStaticProvider(t, Flag{Name: "a", Enabled: true, Prerequisites: []string{"b"}})
+	if err := p.SetFlag(Flag{Name: "b", Prerequisites: []string{"a"}}); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("SetFlag() error = %v, want ErrDependencyCycle", err)
+	}
+	if err := p.SaveFlag(context.Background(), Flag{Name: "a", Rollout: &Rollout{Percentage: 150}}); err == nil {
+		t.Error("SaveFlag() expected error but got nil")
+	}
+	if _, ok := p.Flag("b"); ok {
+		t.Error("SetFlag() stored a flag that closes a cycle")
+	}
+	if flag, _ := p.Flag("a"); !flag.Enabled || flag.Rollout != nil {
+		t.Errorf("Flag(a) = %+v, want unchanged after rejected SaveFlag()", flag)
+	}
+	if err := p.SetFlag(Flag{Name: "b", Enabled: true}); err != nil {
+		t.Errorf("SetFlag() unexpected error: %v", err)
+	}
+}
+
+// TestStaticProvider_Concurrent exercises readers and writers together;
+// run with -race to detect unsynchronized access.
+func TestStaticProvider_Concurrent(t *testing.T) {
+	p := NewStaticProvider(map[string]bool{"a": true, "b": false})
+	m := New(p)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				p.Set("a", j%2 == 0)
+				p.SetFlag(Flag{Name: fmt.Sprintf("f%d", i), Enabled: true})
+				if j%50 == 0 {
+					p.Replace(map[string]bool{"a": true, "b": j%100 == 0})
+				}
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				m.IsEnabled("a")
+				m.IsEnabledFor(ctx, "b", EvalContext{UserID: "u1"})
+				m.StringVariant(ctx, "a", EvalContext{}, "")
+				p.Flag("f0")
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,246 @@
+package featureflag
+
+import (
+	"context"
+	"maps"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Lister is implemented by providers that can enumerate the flags they
+// define. It is used to find flags that are defined but never evaluated.
+type Lister interface {
+	// Flags returns every defined flag.
+	Flags() []Flag
+}
+
+// StaleReason explains why a flag is reported as stale.
+type StaleReason string
+
+const (
+	// StaleNeverEvaluated means the flag is defined but was not evaluated
+	// during the window.
+	StaleNeverEvaluated StaleReason = "never_evaluated"
+
+	// StaleConstantResult means every evaluation during the window
+	// returned the same result and, for variant reads, the same variant.
+	StaleConstantResult StaleReason = "constant_result"
+)
+
+// StaleFlag is a flag that is a candidate for cleanup.
+type StaleFlag struct {
+	Name   string
+	Reason StaleReason
+
+	// Result is the constant result for StaleConstantResult, "true" or
+	// "false".
+	Result string
+
+	// Variant is the constant variant for StaleConstantResult, if the flag
+	// was read as a variant.
+	Variant string
+
+	// Since is when the flag last changed result, or when recording
+	// started for flags that were never evaluated.
+	Since time.Time
+}
+
+// FlagStats summarizes the evaluations of one flag.
+type FlagStats struct {
+	Name        string
+	Evaluations int64
+
+	// Results counts evaluations by whether the flag was on: "true" or
+	// "false". Every evaluation counts, however the flag was read.
+	Results map[string]int64
+
+	// Variants counts evaluations of multivariate flags by the name of the
+	// variant served.
+	Variants map[string]int64
+
+	// CallSites counts evaluations by the "file:line" that made them.
+	CallSites map[string]int64
+
+	FirstEvaluated time.Time
+	LastEvaluated  time.Time
+
+	// LastResult is the most recent result and LastVariant the most recent
+	// variant served. ResultSince is when the flag started returning them.
+	LastResult  string
+	LastVariant string
+	ResultSince time.Time
+}
+
+// Stats is a Hook that records per-flag evaluation counts, results, call
+// sites and timestamps, and uses them to report stale flags. Register it
+// with WithHook:
+//
+//	stats := featureflag.NewStats()
+//	ff := featureflag.New(provider, featureflag.WithHook(stats))
+//
+// Stats are kept in memory, so the stale-flag report only covers the time
+// since the process started. It is safe for concurrent use.
+type Stats struct {
+	now     func() time.Time
+	started time.Time
+
+	mu    sync.Mutex
+	flags map[string]*FlagStats
+}
+
+var _ Hook = (*Stats)(nil)
+
+// NewStats creates an empty evaluation recorder.
+func NewStats() *Stats {
+	return newStats(time.Now)
+}
+
+func newStats(now func() time.Time) *Stats {
+	return &Stats{
+		now:     now,
+		started: now(),
+		flags:   make(map[string]*FlagStats),
+	}
+}
+
+// AfterEvaluation records an evaluation.
+func (s *Stats) AfterEvaluation(ctx context.Context, eval Evaluation) {
+	site := callSite()
+	now := s.now()
+	result := strconv.FormatBool(eval.Enabled)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fs, ok := s.flags[eval.FlagName]
+	if !ok {
+		fs = &FlagStats{
+			Name:           eval.FlagName,
+			Results:        make(map[string]int64),
+			Variants:       make(map[string]int64),
+			CallSites:      make(map[string]int64),
+			FirstEvaluated: now,
+			LastResult:     result,
+			LastVariant:    eval.Variant,
+			ResultSince:    now,
+		}
+		s.flags[eval.FlagName] = fs
+	}
+
+	fs.Evaluations++
+	fs.Results[result]++
+	fs.LastEvaluated = now
+	changed := result != fs.LastResult
+	fs.LastResult = result
+	// Flags without variants serve none, so only multivariate flags track
+	// one.
+	if eval.Variant != "" {
+		fs.Variants[eval.Variant]++
+		changed = changed || (fs.LastVariant != "" && eval.Variant != fs.LastVariant)
+		fs.LastVariant = eval.Variant
+	}
+	if changed {
+		fs.ResultSince = now
+	}
+	if site != "" {
+		fs.CallSites[site]++
+	}
+}
+
+// Snapshot returns a copy of the recorded stats, sorted by flag name.
+func (s *Stats) Snapshot() []FlagStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := make([]FlagStats, 0, len(s.flags))
+	for _, fs := range s.flags {
+		c := *fs
+		c.Results = maps.Clone(fs.Results)
+		c.Variants = maps.Clone(fs.Variants)
+		c.CallSites = maps.Clone(fs.CallSites)
+		snapshot = append(snapshot, c)
+	}
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].Name < snapshot[j].Name })
+	return snapshot
+}
+
+// Stale reports flags that have returned the same result for at least
+// window, and, if the provider implements Lister and recording has run for
+// at least window, flags it defines that were never evaluated and no other
+// flag depends on. Results are sorted by flag name.
+func (s *Stats) Stale(provider Provider, window time.Duration) []StaleFlag {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stale []StaleFlag
+	for _, fs := range s.flags {
+		if now.Sub(fs.ResultSince) >= window {
+			stale = append(stale, StaleFlag{
+				Name:    fs.Name,
+				Reason:  StaleConstantResult,
+				Result:  fs.LastResult,
+				Variant: fs.LastVariant,
+				Since:   fs.ResultSince,
+			})
+		}
+	}
+
+	if lister, ok := provider.(Lister); ok && now.Sub(s.started) >= window {
+		flags := lister.Flags()
+		// Prerequisites and kill switches are evaluated on behalf of the
+		// flags that depend on them, so they are in use without being
+		// evaluated directly.
+		dependedOn := make(map[string]bool)
+		for _, flag := range flags {
+			for _, dep := range flag.Dependencies() {
+				dependedOn[dep] = true
+			}
+		}
+		for _, flag := range flags {
+			if _, evaluated := s.flags[flag.Name]; !evaluated && !dependedOn[flag.Name] {
+				stale = append(stale, StaleFlag{
+					Name:   flag.Name,
+					Reason: StaleNeverEvaluated,
+					Since:  s.started,
+				})
+			}
+		}
+	}
+
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Name < stale[j].Name })
+	return stale
+}
+
+// packageDir is the directory of this package's source. Frames in it and
+// its subpackages, such as the httpapi and openfeature adapters, are
+// skipped when looking for the code that evaluated a flag.
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// callSite returns the "file:line" of the first caller outside this
+// package and its subpackages, or "" if there is none.
+func callSite() string {
+	var pcs [32]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		dir := filepath.Dir(frame.File)
+		internal := dir == packageDir || strings.HasPrefix(dir, packageDir+string(filepath.Separator))
+		if !internal || strings.HasSuffix(frame.File, "_test.go") {
+			return frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
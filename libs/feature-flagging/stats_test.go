@@ -0,0 +1,170 @@
+package featureflag
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithHook(t *testing.T) {
+	var evals []Evaluation
+	hook := HookFunc(func(ctx context.Context, eval Evaluation) { evals = append(evals, eval) })
+
+	m := New(newStaticProvider(t,
+		Flag{Name: "on", Enabled: true},
+		Flag{Name: "algo", Enabled: true, Variants: []Variant{{Name: "v2", Value: "v2"}}},
+	), WithHook(hook))
+
+	ctx := context.Background()
+	m.IsEnabled("on")
+	m.IsEnabledFor(ctx, "missing", EvalContext{UserID: "u1"})
+	m.StringVariant(ctx, "algo", EvalContext{UserID: "u2"}, "v1")
+
+	if len(evals) != 3 {
+		t.Fatalf("hook saw %d evaluations, want 3", len(evals))
+	}
+	if e := evals[0]; e.FlagName != "on" || !e.Exists || !e.Enabled || e.Variant != "" {
+		t.Errorf("evals[0] = %+v", e)
+	}
+	if e := evals[1]; e.FlagName != "missing" || e.Exists || e.Enabled || e.EvalContext.UserID != "u1" {
+		t.Errorf("evals[1] = %+v", e)
+	}
+	if e := evals[2]; e.FlagName != "algo" || !e.Enabled || e.Variant != "v2" {
+		t.Errorf("evals[2] = %+v", e)
+	}
+}
+
+func TestStats_Snapshot(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	stats := newStats(func() time.Time { return clock })
+	p := NewStaticProvider(map[string]bool{"a": true})
+	m := New(p, WithHook(stats))
+
+	m.IsEnabled("a")
+	m.IsEnabled("a")
+	clock = clock.Add(time.Hour)
+	p.Set("a", false)
+	m.IsEnabled("a")
+
+	snapshot := stats.Snapshot()
+	if len(snapshot) != 1 {
+		t.Fatalf("Snapshot() returned %d flags, want 1", len(snapshot))
+	}
+	fs := snapshot[0]
+	if fs.Name != "a" || fs.Evaluations != 3 || fs.Results["true"] != 2 || fs.Results["false"] != 1 {
+		t.Errorf("Snapshot() = %+v", fs)
+	}
+	if fs.LastResult != "false" || !fs.ResultSince.Equal(clock) || !fs.LastEvaluated.Equal(clock) {
+		t.Errorf("Snapshot() result tracking = %+v", fs)
+	}
+	if fs.FirstEvaluated.Equal(clock) {
+		t.Error("Snapshot() FirstEvaluated moved with later evaluations")
+	}
+
+	if len(fs.CallSites) != 3 {
+		t.Fatalf("Snapshot() call sites = %v, want the three test call sites", fs.CallSites)
+	}
+	for site, count := range fs.CallSites {
+		if !strings.Contains(site, "stats_test.go:") || count != 1 {
+			t.Errorf("call site = %s (%d), want stats_test.go", site, count)
+		}
+	}
+
+	// Snapshots are copies.
+	snapshot[0].Results["true"] = 100
+	if stats.Snapshot()[0].Results["true"] != 2 {
+		t.Error("Snapshot() shares maps with the recorder")
+	}
+}
+
+func TestStats_Stale(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	stats := newStats(func() time.Time { return clock })
+	p := NewStaticProvider(map[string]bool{
+		"constant": true,
+		"flipping": true,
+		"unused":   true,
+		"kill":     false,
+	})
+	// A kill switch is used by the flags depending on it, not evaluated directly.
+	p.SetFlag(Flag{Name: "flipping", Enabled: true, KillSwitch: "kill"})
+	m := New(p, WithHook(stats))
+	window := 7 * 24 * time.Hour
+
+	m.IsEnabled("constant")
+	m.IsEnabled("flipping")
+	if stale := stats.Stale(p, window); len(stale) != 0 {
+		t.Errorf("Stale() before window elapsed = %+v, want none", stale)
+	}
+
+	clock = clock.Add(6 * 24 * time.Hour)
+	p.Set("flipping", false)
+	m.IsEnabled("flipping")
+	m.IsEnabled("constant")
+
+	clock = clock.Add(2 * 24 * time.Hour)
+	stale := stats.Stale(p, window)
+	if len(stale) != 2 {
+		t.Fatalf("Stale() = %+v, want constant and unused", stale)
+	}
+	if stale[0].Name != "constant" || stale[0].Reason != StaleConstantResult || stale[0].Result != "true" {
+		t.Errorf("stale[0] = %+v", stale[0])
+	}
+	if stale[1].Name != "unused" || stale[1].Reason != StaleNeverEvaluated {
+		t.Errorf("stale[1] = %+v", stale[1])
+	}
+
+	// Providers that cannot list flags only yield constant results.
+	if stale := stats.Stale(NewEnvProvider(EnvConfig{}), window); len(stale) != 1 {
+		t.Errorf("Stale() with non-listing provider = %+v, want only constant", stale)
+	}
+}
+
+func TestStats_VariantsTrackedSeparately(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	stats := newStats(func() time.Time { return clock })
+	p := newStaticProvider(t,
+		Flag{Name: "theme", Enabled: true, Variants: []Variant{{Name: "dark", Value: "dark"}}},
+		Flag{Name: "experiment", Enabled: true, Variants: []Variant{
+			{Name: "control", Value: "a", Weight: 50},
+			{Name: "treatment", Value: "b", Weight: 50},
+		}},
+	)
+	m := New(p, WithHook(stats))
+	ctx := context.Background()
+	window := 7 * 24 * time.Hour
+
+	// A flag read both as on/off and as a variant reports the same variant
+	// either way, and still looks constant.
+	m.IsEnabled("theme")
+	m.StringVariant(ctx, "theme", EvalContext{UserID: "u1"}, "light")
+	m.IsEnabled("theme")
+
+	// Users split across variants are not constant, even though the flag
+	// is on for all of them.
+	m.StringVariant(ctx, "experiment", EvalContext{UserID: "user-0"}, "")
+	clock = clock.Add(24 * time.Hour)
+	for i := 1; i <= 20; i++ {
+		m.StringVariant(ctx, "experiment", EvalContext{UserID: fmt.Sprintf("user-%d", i)}, "")
+	}
+
+	snapshot := stats.Snapshot()
+	if len(snapshot) != 2 {
+		t.Fatalf("Snapshot() returned %d flags, want 2", len(snapshot))
+	}
+	theme := snapshot[1]
+	if theme.Results["true"] != 3 || theme.Variants["dark"] != 3 || theme.LastResult != "true" || theme.LastVariant != "dark" {
+		t.Errorf("Snapshot() theme = %+v", theme)
+	}
+	if experiment := snapshot[0]; experiment.Results["true"] != 21 || len(experiment.Variants) != 2 {
+		t.Errorf("Snapshot() experiment = %+v, want both variants counted", experiment)
+	}
+
+	clock = clock.Add(window - 24*time.Hour)
+	stale := stats.Stale(p, window)
+	if len(stale) != 1 || stale[0].Name != "theme" || stale[0].Result != "true" || stale[0].Variant != "dark" {
+		t.Errorf("Stale() = %+v, want only theme constant on dark", stale)
+	}
+}
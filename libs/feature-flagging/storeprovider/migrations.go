@@ -0,0 +1,27 @@
+package storeprovider
+
+import (
+	"fmt"
+
+	"github.com/JWindy92/obelisk-platform/libs/store"
+	"github.com/JWindy92/obelisk-platform/libs/store/migrate"
+)
+
+// Migrations returns the migration set that creates the flags table named
+// by config.TableName, for registration with a migrate.Migrator.
+func Migrations(dialect store.Dialect, config Config) migrate.Set {
+	tableName := config.TableName
+	if tableName == "" {
+		tableName = "feature_flags"
+	}
+
+	return migrate.CreateTable("featureflag", tableName, fmt.Sprintf(`
+		CREATE TABLE %[1]s (
+			name TEXT PRIMARY KEY,
+			enabled BOOLEAN NOT NULL DEFAULT FALSE,
+			definition TEXT NOT NULL DEFAULT '{}',
+			created_at %[2]s NOT NULL,
+			updated_at %[2]s NOT NULL
+		)
+	`, tableName, dialect.TimestampType()))
+}
@@ -0,0 +1,528 @@
+// Package storeprovider provides a featureflag.Provider that persists flags
+// in a database table through store.Store, so flags can be changed at
+// runtime without a redeploy.
+//
+// Lookups are served from an in-memory cache. Call Refresh to load it and
+// RunRefresher to keep it in sync with changes made by other instances;
+// changes made through this Provider's CRUD methods update the cache
+// immediately. Either way, changes are reported to watchers registered with
+// Watch.
+//
+// The CRUD methods join a transaction started with store.WithTx. Since the
+// transaction may still roll back, their changes then reach the cache, and
+// watchers, only with the next Refresh after it commits.
+package storeprovider
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	featureflag "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+	"github.com/JWindy92/obelisk-platform/libs/store"
+)
+
+var (
+	// ErrFlagNotFound is returned when a flag does not exist in the table.
+	// It is featureflag.ErrFlagNotFound, so generic callers can match it.
+	ErrFlagNotFound = featureflag.ErrFlagNotFound
+
+	// ErrFlagExists is returned by Create when a flag with the same name exists.
+	ErrFlagExists = errors.New("feature flag already exists")
+)
+
+// Config holds configuration options for the store provider.
+type Config struct {
+	// TableName specifies the database table name for flags.
+	// Defaults to "feature_flags" if not specified.
+	TableName string
+
+	// RefreshInterval is how often RunRefresher reloads the cache.
+	// Defaults to 30 seconds if not specified.
+	RefreshInterval time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		TableName:       "feature_flags",
+		RefreshInterval: 30 * time.Second,
+	}
+}
+
+// Provider implements featureflag.Provider over a database table.
+type Provider struct {
+	featureflag.Broadcaster
+
+	store     store.Store
+	tableName string
+	config    Config
+	now       func() time.Time
+
+	mu    sync.RWMutex
+	flags map[string]featureflag.Flag
+
+	// gen counts writes to the cache. writes holds the generation of the
+	// latest write to each flag made since the last Refresh started, and
+	// loaded the generation the cached snapshot was read at, so Refresh
+	// never replaces a newer write with an older snapshot.
+	gen    uint64
+	writes map[string]uint64
+	loaded uint64
+}
+
+var (
+	_ featureflag.Provider  = (*Provider)(nil)
+	_ featureflag.Watchable = (*Provider)(nil)
+	_ featureflag.Lister    = (*Provider)(nil)
+)
+
+// NewProvider creates a provider that persists flags through st.
+// Create the table with the set returned by Migrations, then call Refresh
+// to load the cache; until then every flag is reported as disabled.
+func NewProvider(st store.Store, config Config) *Provider {
+	if config.TableName == "" {
+		config.TableName = "feature_flags"
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = DefaultConfig().RefreshInterval
+	}
+
+	return &Provider{
+		store:     st,
+		tableName: config.TableName,
+		config:    config,
+		now:       time.Now,
+		flags:     make(map[string]featureflag.Flag),
+		writes:    make(map[string]uint64),
+	}
+}
+
+// IsEnabled checks if a feature flag is enabled in the cache.
+// Returns false if the flag doesn't exist (fail-safe default).
+func (p *Provider) IsEnabled(flagName string) bool {
+	flag, _ := p.Flag(flagName)
+	return flag.Enabled
+}
+
+// Lookup reports whether a cached feature flag is enabled and whether it exists.
+func (p *Provider) Lookup(flagName string) (enabled, exists bool) {
+	flag, exists := p.Flag(flagName)
+	return flag.Enabled, exists
+}
+
+// Flag returns the cached definition of a flag and whether it exists.
+func (p *Provider) Flag(flagName string) (featureflag.Flag, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	flag, exists := p.flags[flagName]
+	return flag, exists
+}
+
+// Flags returns every cached flag, sorted by name.
+func (p *Provider) Flags() []featureflag.Flag {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	flags := make([]featureflag.Flag, 0, len(p.flags))
+	for _, flag := range p.flags {
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	return flags
+}
+
+// Refresh reloads every flag from the table and replaces the cache.
+// Flags written through this Provider while the table was being read keep
+// their newer cached definitions. On error, including a table whose flags
+// fail ValidateFlags, the previous cache is kept.
+func (p *Provider) Refresh(ctx context.Context) error {
+	p.mu.RLock()
+	start := p.gen
+	p.mu.RUnlock()
+
+	flags, err := p.List(ctx)
+	if err != nil {
+		return err
+	}
+	if err := featureflag.ValidateFlags(flags); err != nil {
+		return fmt.Errorf("invalid feature flags in %s: %w", p.tableName, err)
+	}
+
+	p.replace(start, flags)
+	return nil
+}
+
+// replace swaps in flags, read from the table at generation start, as the
+// cache. Flags written after start keep their cached state, and a snapshot
+// older than the one already loaded is discarded.
+func (p *Provider) replace(start uint64, flags []featureflag.Flag) {
+	next := make(map[string]featureflag.Flag, len(flags))
+	for _, flag := range flags {
+		next[flag.Name] = flag
+	}
+
+	p.mu.Lock()
+	if start < p.loaded {
+		p.mu.Unlock()
+		return
+	}
+	for name, gen := range p.writes {
+		if gen <= start {
+			delete(p.writes, name)
+			continue
+		}
+		if flag, ok := p.flags[name]; ok {
+			next[name] = flag
+		} else {
+			delete(next, name)
+		}
+	}
+	changes := featureflag.Diff(p.flags, next)
+	p.flags = next
+	p.loaded = start
+	p.mu.Unlock()
+
+	p.Publish(changes...)
+}
+
+// wrote records a write to the named flag's cache entry. p.mu must be held.
+func (p *Provider) wrote(flagName string) {
+	p.gen++
+	p.writes[flagName] = p.gen
+}
+
+// RunRefresher calls Refresh every RefreshInterval until ctx is cancelled.
+// Errors are passed to onError, which may be nil. Run it in its own goroutine:
+//
+//	go flags.RunRefresher(ctx, func(err error) { log.Print(err) })
+func (p *Provider) RunRefresher(ctx context.Context, onError func(error)) {
+	ticker := time.NewTicker(p.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.Refresh(ctx); err != nil && onError != nil && ctx.Err() == nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Create inserts a new flag. Returns ErrFlagExists if the name is taken.
+// The flag is validated together with the stored ones, so it cannot close a
+// dependency cycle.
+func (p *Provider) Create(ctx context.Context, flag featureflag.Flag) error {
+	if err := p.validate(ctx, flag); err != nil {
+		return err
+	}
+	definition, err := json.Marshal(flag)
+	if err != nil {
+		return fmt.Errorf("failed to encode feature flag: %w", err)
+	}
+
+	now := p.now().UTC()
+	query := p.rebind(fmt.Sprintf(
+		"INSERT INTO %s (name, enabled, definition, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		p.tableName,
+	))
+	_, err = p.querier(ctx).ExecContext(ctx, query, flag.Name, flag.Enabled, string(definition), now, now)
+	if err != nil {
+		if p.store.IsUniqueViolation(err) {
+			return ErrFlagExists
+		}
+		return fmt.Errorf("failed to create feature flag: %w", err)
+	}
+
+	p.cache(ctx, flag)
+	return nil
+}
+
+// Get loads a flag from the table, bypassing the cache.
+// Returns ErrFlagNotFound if it doesn't exist.
+func (p *Provider) Get(ctx context.Context, flagName string) (featureflag.Flag, error) {
+	query := p.rebind(fmt.Sprintf(
+		"SELECT name, enabled, definition FROM %s WHERE name = ?", p.tableName,
+	))
+
+	flag, err := scanFlag(p.querier(ctx).QueryRowContext(ctx, query, flagName))
+	if errors.Is(err, sql.ErrNoRows) {
+		return featureflag.Flag{}, ErrFlagNotFound
+	}
+	if err != nil {
+		return featureflag.Flag{}, fmt.Errorf("failed to get feature flag: %w", err)
+	}
+	return flag, nil
+}
+
+// List loads every flag from the table, ordered by name.
+func (p *Provider) List(ctx context.Context) ([]featureflag.Flag, error) {
+	query := fmt.Sprintf("SELECT name, enabled, definition FROM %s ORDER BY name", p.tableName)
+
+	rows, err := p.querier(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+	defer rows.Close()
+
+	var flags []featureflag.Flag
+	for rows.Next() {
+		flag, err := scanFlag(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list feature flags: %w", err)
+		}
+		flags = append(flags, flag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+	return flags, nil
+}
+
+// Update replaces a flag's full definition, validated as for Create.
+// Returns ErrFlagNotFound if it doesn't exist.
+func (p *Provider) Update(ctx context.Context, flag featureflag.Flag) error {
+	if err := p.validate(ctx, flag); err != nil {
+		return err
+	}
+	definition, err := json.Marshal(flag)
+	if err != nil {
+		return fmt.Errorf("failed to encode feature flag: %w", err)
+	}
+
+	query := p.rebind(fmt.Sprintf(
+		"UPDATE %s SET enabled = ?, definition = ?, updated_at = ? WHERE name = ?", p.tableName,
+	))
+	result, err := p.querier(ctx).ExecContext(ctx, query, flag.Enabled, string(definition), p.now().UTC(), flag.Name)
+	if err != nil {
+		return fmt.Errorf("failed to update feature flag: %w", err)
+	}
+	if err := requireAffected(result); err != nil {
+		return err
+	}
+
+	p.cache(ctx, flag)
+	return nil
+}
+
+// SaveFlag creates the flag or, if it exists, replaces its definition,
+// validated as for Create. It is a single upsert, so concurrent saves of a
+// new flag don't race each other into ErrFlagExists.
+func (p *Provider) SaveFlag(ctx context.Context, flag featureflag.Flag) error {
+	if err := p.validate(ctx, flag); err != nil {
+		return err
+	}
+	definition, err := json.Marshal(flag)
+	if err != nil {
+		return fmt.Errorf("failed to encode feature flag: %w", err)
+	}
+
+	// ON CONFLICT ... DO UPDATE is supported by both SQLite and Postgres.
+	now := p.now().UTC()
+	query := p.rebind(fmt.Sprintf(
+		"INSERT INTO %s (name, enabled, definition, created_at, updated_at) VALUES (?, ?, ?, ?, ?) "+
+			"ON CONFLICT (name) DO UPDATE SET enabled = excluded.enabled, definition = excluded.definition, updated_at = excluded.updated_at",
+		p.tableName,
+	))
+	_, err = p.querier(ctx).ExecContext(ctx, query, flag.Name, flag.Enabled, string(definition), now, now)
+	if err != nil {
+		return fmt.Errorf("failed to save feature flag: %w", err)
+	}
+
+	p.cache(ctx, flag)
+	return nil
+}
+
+// Toggle switches a flag on or off, keeping its targeting and variants.
+// Returns ErrFlagNotFound if it doesn't exist.
+func (p *Provider) Toggle(ctx context.Context, flagName string, enabled bool) error {
+	query := p.rebind(fmt.Sprintf(
+		"UPDATE %s SET enabled = ?, updated_at = ? WHERE name = ?", p.tableName,
+	))
+	result, err := p.querier(ctx).ExecContext(ctx, query, enabled, p.now().UTC(), flagName)
+	if err != nil {
+		return fmt.Errorf("failed to toggle feature flag: %w", err)
+	}
+	if err := requireAffected(result); err != nil {
+		return err
+	}
+	if store.InTx(ctx, p.store) {
+		return nil
+	}
+
+	p.mu.Lock()
+	old, ok := p.flags[flagName]
+	flag := old
+	flag.Enabled = enabled
+	if ok {
+		p.flags[flagName] = flag
+		p.wrote(flagName)
+	}
+	p.mu.Unlock()
+
+	if ok && old.Enabled != enabled {
+		p.Publish(featureflag.FlagChange{Name: flagName, Old: old, New: flag})
+	}
+	return nil
+}
+
+// Delete removes a flag. Returns ErrFlagNotFound if it doesn't exist.
+func (p *Provider) Delete(ctx context.Context, flagName string) error {
+	query := p.rebind(fmt.Sprintf("DELETE FROM %s WHERE name = ?", p.tableName))
+	result, err := p.querier(ctx).ExecContext(ctx, query, flagName)
+	if err != nil {
+		return fmt.Errorf("failed to delete feature flag: %w", err)
+	}
+	if err := requireAffected(result); err != nil {
+		return err
+	}
+	if store.InTx(ctx, p.store) {
+		return nil
+	}
+
+	p.mu.Lock()
+	old, ok := p.flags[flagName]
+	delete(p.flags, flagName)
+	p.wrote(flagName)
+	p.mu.Unlock()
+
+	if ok {
+		p.Publish(featureflag.FlagChange{Name: flagName, Old: old})
+	}
+	return nil
+}
+
+// cache stores flag in the cache and publishes the change, unless ctx
+// carries a transaction that may still roll back.
+func (p *Provider) cache(ctx context.Context, flag featureflag.Flag) {
+	if store.InTx(ctx, p.store) {
+		return
+	}
+
+	p.mu.Lock()
+	old := p.flags[flag.Name]
+	p.flags[flag.Name] = flag
+	p.wrote(flag.Name)
+	p.mu.Unlock()
+
+	p.Publish(featureflag.Diff(
+		map[string]featureflag.Flag{flag.Name: old},
+		map[string]featureflag.Flag{flag.Name: flag},
+	)...)
+}
+
+// validate checks flag together with the stored flags it depends on,
+// directly or transitively, read through ctx's transaction if any, so a
+// definition that would close a dependency cycle is rejected.
+func (p *Provider) validate(ctx context.Context, flag featureflag.Flag) error {
+	if err := flag.Validate(); err != nil {
+		return err
+	}
+
+	flags := []featureflag.Flag{flag}
+	seen := map[string]bool{flag.Name: true}
+	next := flag.Dependencies()
+	for len(next) > 0 {
+		var names []string
+		for _, name := range next {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		deps, err := p.listNamed(ctx, names)
+		if err != nil {
+			return err
+		}
+		next = nil
+		for _, dep := range deps {
+			flags = append(flags, dep)
+			next = append(next, dep.Dependencies()...)
+		}
+	}
+	return featureflag.ValidateFlags(flags)
+}
+
+// listNamed loads the flags with the given names that exist in the table.
+func (p *Provider) listNamed(ctx context.Context, names []string) ([]featureflag.Flag, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	placeholders := strings.Repeat("?, ", len(names)-1) + "?"
+	query := p.rebind(fmt.Sprintf(
+		"SELECT name, enabled, definition FROM %s WHERE name IN (%s)", p.tableName, placeholders,
+	))
+	args := make([]any, len(names))
+	for i, name := range names {
+		args[i] = name
+	}
+
+	rows, err := p.querier(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+	defer rows.Close()
+
+	var flags []featureflag.Flag
+	for rows.Next() {
+		flag, err := scanFlag(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list feature flags: %w", err)
+		}
+		flags = append(flags, flag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+	return flags, nil
+}
+
+func (p *Provider) querier(ctx context.Context) store.Querier {
+	return store.QuerierFrom(ctx, p.store)
+}
+
+func (p *Provider) rebind(query string) string {
+	return p.store.Dialect().Rebind(query)
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanFlag decodes a row of (name, enabled, definition). The name and
+// enabled columns are authoritative over the copies inside definition.
+func scanFlag(row scanner) (featureflag.Flag, error) {
+	var name, definition string
+	var enabled bool
+	if err := row.Scan(&name, &enabled, &definition); err != nil {
+		return featureflag.Flag{}, err
+	}
+
+	var flag featureflag.Flag
+	if err := json.Unmarshal([]byte(definition), &flag); err != nil {
+		return featureflag.Flag{}, fmt.Errorf("failed to decode definition of %q: %w", name, err)
+	}
+	flag.Name = name
+	flag.Enabled = enabled
+	return flag, nil
+}
+
+func requireAffected(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if n == 0 {
+		return ErrFlagNotFound
+	}
+	return nil
+}
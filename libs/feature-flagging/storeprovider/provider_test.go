@@ -0,0 +1,454 @@
+package storeprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	featureflag "github.com/JWindy92/obelisk-platform/libs/feature-flagging"
+	"github.com/JWindy92/obelisk-platform/libs/internal/storetest"
+	"github.com/JWindy92/obelisk-platform/libs/store"
+)
+
+func newTestStore(t *testing.T, config Config) store.Store {
+	t.Helper()
+
+	return storetest.SQLite(t, Migrations(store.DialectSQLite, config))
+}
+
+func TestProvider_CRUD(t *testing.T) {
+	ctx := context.Background()
+	p := NewProvider(newTestStore(t, DefaultConfig()), DefaultConfig())
+
+	flag := featureflag.Flag{
+		Name:    "new-checkout",
+		Enabled: true,
+		Rules: []featureflag.Rule{
+			{Attribute: "tenant", Operator: featureflag.OpIn, Values: []string{"acme"}},
+		},
+		Rollout:  &featureflag.Rollout{Percentage: 25},
+		Variants: []featureflag.Variant{{Name: "v2", Value: "v2"}},
+	}
+	if err := p.Create(ctx, flag); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if err := p.Create(ctx, flag); !errors.Is(err, ErrFlagExists) {
+		t.Errorf("Create() duplicate error = %v, want ErrFlagExists", err)
+	}
+
+	got, err := p.Get(ctx, "new-checkout")
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if !got.Enabled || len(got.Rules) != 1 || got.Rollout == nil || got.Rollout.Percentage != 25 || len(got.Variants) != 1 {
+		t.Errorf("Get() = %+v, want stored definition", got)
+	}
+
+	if err := p.Toggle(ctx, "new-checkout", false); err != nil {
+		t.Fatalf("Toggle() unexpected error: %v", err)
+	}
+	got, _ = p.Get(ctx, "new-checkout")
+	if got.Enabled || len(got.Rules) != 1 {
+		t.Errorf("Get() after Toggle() = %+v, want disabled with rules kept", got)
+	}
+
+	flag.Rollout = &featureflag.Rollout{Percentage: 50}
+	if err := p.Update(ctx, flag); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	got, _ = p.Get(ctx, "new-checkout")
+	if !got.Enabled || got.Rollout.Percentage != 50 {
+		t.Errorf("Get() after Update() = %+v", got)
+	}
+
+	if err := p.Create(ctx, featureflag.Flag{Name: "beta-api"}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	flags, err := p.List(ctx)
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	if len(flags) != 2 || flags[0].Name != "beta-api" || flags[1].Name != "new-checkout" {
+		t.Errorf("List() = %+v, want beta-api and new-checkout", flags)
+	}
+
+	if err := p.Delete(ctx, "beta-api"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if _, err := p.Get(ctx, "beta-api"); !errors.Is(err, ErrFlagNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrFlagNotFound", err)
+	}
+}
+
+func TestProvider_NotFound(t *testing.T) {
+	ctx := context.Background()
+	p := NewProvider(newTestStore(t, DefaultConfig()), DefaultConfig())
+
+	tests := []struct {
+		name string
+		fn   func() error
+	}{
+		{name: "toggle", fn: func() error { return p.Toggle(ctx, "missing", true) }},
+		{name: "update", fn: func() error { return p.Update(ctx, featureflag.Flag{Name: "missing"}) }},
+		{name: "delete", fn: func() error { return p.Delete(ctx, "missing") }},
+		{name: "get", fn: func() error { _, err := p.Get(ctx, "missing"); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fn(); !errors.Is(err, ErrFlagNotFound) {
+				t.Errorf("error = %v, want ErrFlagNotFound", err)
+			}
+		})
+	}
+}
+
+func TestProvider_Cache(t *testing.T) {
+	ctx := context.Background()
+	config := Config{TableName: "flags"}
+	st := newTestStore(t, config)
+
+	admin := NewProvider(st, config)
+	reader := NewProvider(st, config)
+
+	if err := admin.Create(ctx, featureflag.Flag{Name: "new-checkout", Enabled: true}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	// The writing instance sees its own change immediately.
+	if !admin.IsEnabled("new-checkout") {
+		t.Error("IsEnabled() on writer = false, want true")
+	}
+
+	// Other instances only see it after a refresh.
+	if reader.IsEnabled("new-checkout") {
+		t.Error("IsEnabled() on reader before Refresh() = true, want false")
+	}
+	if err := reader.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+	if !reader.IsEnabled("new-checkout") {
+		t.Error("IsEnabled() on reader after Refresh() = false, want true")
+	}
+
+	if err := admin.Toggle(ctx, "new-checkout", false); err != nil {
+		t.Fatalf("Toggle() unexpected error: %v", err)
+	}
+	if admin.IsEnabled("new-checkout") {
+		t.Error("IsEnabled() on writer after Toggle() = true, want false")
+	}
+
+	if err := admin.Delete(ctx, "new-checkout"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if err := reader.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+	if _, ok := reader.Flag("new-checkout"); ok {
+		t.Error("Flag() after Delete() and Refresh() still exists")
+	}
+}
+
+func TestProvider_WithManager(t *testing.T) {
+	ctx := context.Background()
+	p := NewProvider(newTestStore(t, DefaultConfig()), DefaultConfig())
+
+	err := p.Create(ctx, featureflag.Flag{
+		Name:     "max-batch",
+		Enabled:  true,
+		Variants: []featureflag.Variant{{Name: "large", Value: 500}},
+	})
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if err := p.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+
+	// Values round-trip through JSON, so the integer comes back as float64.
+	ff := featureflag.New(p)
+	if got := ff.IntVariant(ctx, "max-batch", featureflag.EvalContext{}, 100); got != 500 {
+		t.Errorf("IntVariant() = %d, want 500", got)
+	}
+}
+
+func TestProvider_RunRefresher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	config := Config{RefreshInterval: 10 * time.Millisecond}
+	st := newTestStore(t, config)
+
+	admin := NewProvider(st, config)
+	reader := NewProvider(st, config)
+	if err := admin.Create(ctx, featureflag.Flag{Name: "f", Enabled: true}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		reader.RunRefresher(ctx, func(err error) { t.Errorf("refresh error: %v", err) })
+		close(done)
+	}()
+
+	for i := 0; i < 200 && !reader.IsEnabled("f"); i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if !reader.IsEnabled("f") {
+		t.Error("RunRefresher() never loaded the flag")
+	}
+}
+
+func TestProvider_Watch(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultConfig()
+	st := newTestStore(t, config)
+
+	admin := NewProvider(st, config)
+	reader := NewProvider(st, config)
+
+	var adminChanges, readerChanges []featureflag.FlagChange
+	admin.Watch(func(c featureflag.FlagChange) { adminChanges = append(adminChanges, c) })
+	reader.Watch(func(c featureflag.FlagChange) { readerChanges = append(readerChanges, c) })
+
+	if err := admin.Create(ctx, featureflag.Flag{Name: "f"}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if err := admin.Toggle(ctx, "f", true); err != nil {
+		t.Fatalf("Toggle() unexpected error: %v", err)
+	}
+	if err := admin.Delete(ctx, "f"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if len(adminChanges) != 3 {
+		t.Errorf("writer saw %d changes, want 3: %+v", len(adminChanges), adminChanges)
+	}
+
+	if err := admin.Create(ctx, featureflag.Flag{Name: "g", Enabled: true}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if err := reader.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+	if err := reader.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+	if len(readerChanges) != 1 || readerChanges[0].Name != "g" || !readerChanges[0].New.Enabled {
+		t.Errorf("reader saw %+v, want one change adding g", readerChanges)
+	}
+}
+
+func TestProvider_SaveFlag(t *testing.T) {
+	ctx := context.Background()
+	p := NewProvider(newTestStore(t, DefaultConfig()), DefaultConfig())
+
+	if err := p.SaveFlag(ctx, featureflag.Flag{Name: "f", Enabled: true}); err != nil {
+		t.Fatalf("SaveFlag() create unexpected error: %v", err)
+	}
+	if err := p.SaveFlag(ctx, featureflag.Flag{Name: "f", Enabled: false}); err != nil {
+		t.Fatalf("SaveFlag() replace unexpected error: %v", err)
+	}
+
+	got, err := p.Get(ctx, "f")
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if got.Enabled {
+		t.Error("SaveFlag() did not replace the existing flag")
+	}
+}
+
+func TestProvider_RefreshKeepsNewerWrites(t *testing.T) {
+	ctx := context.Background()
+	p := NewProvider(newTestStore(t, DefaultConfig()), DefaultConfig())
+
+	for _, name := range []string{"toggled", "deleted"} {
+		if err := p.Create(ctx, featureflag.Flag{Name: name, Enabled: true}); err != nil {
+			t.Fatalf("Create() unexpected error: %v", err)
+		}
+	}
+
+	// Read a snapshot as Refresh does, then write before it is swapped in.
+	p.mu.RLock()
+	start := p.gen
+	p.mu.RUnlock()
+	stale, err := p.List(ctx)
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	if err := p.Toggle(ctx, "toggled", false); err != nil {
+		t.Fatalf("Toggle() unexpected error: %v", err)
+	}
+	if err := p.Delete(ctx, "deleted"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if err := p.Create(ctx, featureflag.Flag{Name: "created", Enabled: true}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	p.replace(start, stale)
+
+	if p.IsEnabled("toggled") {
+		t.Error("IsEnabled(toggled) = true, want the newer Toggle() kept")
+	}
+	if _, ok := p.Flag("deleted"); ok {
+		t.Error("Flag(deleted) exists, want the newer Delete() kept")
+	}
+	if !p.IsEnabled("created") {
+		t.Error("IsEnabled(created) = false, want the newer Create() kept")
+	}
+
+	// A later Refresh sees the writes in the table.
+	if err := p.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+	if flags := p.Flags(); len(flags) != 2 || flags[0].Name != "created" || flags[1].Enabled {
+		t.Errorf("Flags() = %+v, want created and disabled toggled", flags)
+	}
+
+	// An older snapshot than the one loaded is discarded.
+	p.replace(start, stale)
+	if _, ok := p.Flag("deleted"); ok {
+		t.Error("Flag(deleted) exists after replacing with an older snapshot")
+	}
+}
+
+func TestProvider_Transaction(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t, DefaultConfig())
+	p := NewProvider(st, DefaultConfig())
+
+	var changes []featureflag.FlagChange
+	p.Watch(func(c featureflag.FlagChange) { changes = append(changes, c) })
+
+	errRollback := errors.New("rollback")
+	err := st.WithTx(ctx, func(ctx context.Context) error {
+		if err := p.Create(ctx, featureflag.Flag{Name: "tx", Enabled: true}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("WithTx() error = %v, want rollback", err)
+	}
+	if _, ok := p.Flag("tx"); ok {
+		t.Error("Flag() after rolled back Create() still exists")
+	}
+	if len(changes) != 0 {
+		t.Errorf("watchers saw %+v for a rolled back Create()", changes)
+	}
+
+	err = st.WithTx(ctx, func(ctx context.Context) error {
+		return p.Create(ctx, featureflag.Flag{Name: "tx", Enabled: true})
+	})
+	if err != nil {
+		t.Fatalf("WithTx() unexpected error: %v", err)
+	}
+	if _, ok := p.Flag("tx"); ok {
+		t.Error("Flag() before Refresh() exists, want committed changes to wait for Refresh()")
+	}
+	if err := p.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+	if !p.IsEnabled("tx") || len(changes) != 1 {
+		t.Errorf("after Refresh() IsEnabled() = %v with changes %+v, want committed flag", p.IsEnabled("tx"), changes)
+	}
+}
+
+func TestProvider_RejectsInvalidFlags(t *testing.T) {
+	ctx := context.Background()
+	p := NewProvider(newTestStore(t, DefaultConfig()), DefaultConfig())
+	if err := p.Create(ctx, featureflag.Flag{Name: "f"}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	invalid := featureflag.Flag{
+		Name:  "f",
+		Rules: []featureflag.Rule{{Attribute: "tenant", Operator: "bogus", Values: []string{"acme"}}},
+	}
+	tests := []struct {
+		name string
+		fn   func() error
+	}{
+		{name: "create without name", fn: func() error { return p.Create(ctx, featureflag.Flag{}) }},
+		{name: "create", fn: func() error { invalid := invalid; invalid.Name = "g"; return p.Create(ctx, invalid) }},
+		{name: "update", fn: func() error { return p.Update(ctx, invalid) }},
+		{name: "save", fn: func() error { return p.SaveFlag(ctx, invalid) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fn(); err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+
+	if got, err := p.Get(ctx, "f"); err != nil || len(got.Rules) != 0 {
+		t.Errorf("Get() = %+v, %v, want flag unchanged", got, err)
+	}
+}
+
+func TestProvider_RejectsDependencyCycles(t *testing.T) {
+	ctx := context.Background()
+	p := NewProvider(newTestStore(t, DefaultConfig()), DefaultConfig())
+
+	if err := p.Create(ctx, featureflag.Flag{Name: "a", Prerequisites: []string{"b"}}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if err := p.Create(ctx, featureflag.Flag{Name: "b"}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if err := p.Create(ctx, featureflag.Flag{Name: "d", Prerequisites: []string{"a"}}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		fn   func() error
+	}{
+		{name: "create", fn: func() error { return p.Create(ctx, featureflag.Flag{Name: "c", KillSwitch: "c"}) }},
+		{name: "update", fn: func() error { return p.Update(ctx, featureflag.Flag{Name: "b", KillSwitch: "a"}) }},
+		{name: "save", fn: func() error { return p.SaveFlag(ctx, featureflag.Flag{Name: "b", Prerequisites: []string{"a"}}) }},
+		{name: "transitive", fn: func() error { return p.Update(ctx, featureflag.Flag{Name: "b", Prerequisites: []string{"d"}}) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fn(); !errors.Is(err, featureflag.ErrDependencyCycle) {
+				t.Errorf("error = %v, want ErrDependencyCycle", err)
+			}
+		})
+	}
+
+	// Replacing the flag that closes the cycle with one that doesn't is fine.
+	if err := p.Update(ctx, featureflag.Flag{Name: "a"}); err != nil {
+		t.Errorf("Update() unexpected error: %v", err)
+	}
+}
+
+func TestProvider_RefreshKeepsCacheOnInvalidFlags(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t, DefaultConfig())
+	p := NewProvider(st, DefaultConfig())
+
+	if err := p.Create(ctx, featureflag.Flag{Name: "a", Enabled: true}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	// Another writer bypassed validation and stored a cycle.
+	_, err := st.DB().ExecContext(ctx, st.Dialect().Rebind(
+		"UPDATE feature_flags SET definition = ? WHERE name = ?",
+	), `{"name":"a","enabled":true,"prerequisites":["a"]}`, "a")
+	if err != nil {
+		t.Fatalf("ExecContext() failed: %v", err)
+	}
+
+	if err := p.Refresh(ctx); !errors.Is(err, featureflag.ErrDependencyCycle) {
+		t.Errorf("Refresh() error = %v, want ErrDependencyCycle", err)
+	}
+	if flag, _ := p.Flag("a"); !flag.Enabled || len(flag.Prerequisites) != 0 {
+		t.Errorf("Flag() after failed Refresh() = %+v, want previous definition", flag)
+	}
+}
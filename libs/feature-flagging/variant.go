@@ -0,0 +1,190 @@
+package featureflag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Variant returns the variant of a multivariate flag served to evalCtx.
+// It returns false if the flag doesn't exist or has no variant to serve.
+func (m *Manager) Variant(ctx context.Context, flagName string, evalCtx EvalContext) (Variant, bool) {
+	_, variant, served := m.evaluateVariant(ctx, flagName, evalCtx)
+	return variant, served
+}
+
+// VariantCtx is Variant for the evaluation context attached to ctx.
+func (m *Manager) VariantCtx(ctx context.Context, flagName string) (Variant, bool) {
+	evalCtx, _ := FromContext(ctx)
+	return m.Variant(ctx, flagName, evalCtx)
+}
+
+// Evaluate evaluates a flag for evalCtx and reports the full result:
+// whether the flag exists, whether it is enabled and which variant, if any,
+// it serves. Use it when the outcome itself is of interest, e.g. to report
+// why a value was served.
+func (m *Manager) Evaluate(ctx context.Context, flagName string, evalCtx EvalContext) Evaluation {
+	eval, _, _ := m.evaluateVariant(ctx, flagName, evalCtx)
+	return eval
+}
+
+// EvaluateCtx is Evaluate for the evaluation context attached to ctx.
+func (m *Manager) EvaluateCtx(ctx context.Context, flagName string) Evaluation {
+	evalCtx, _ := FromContext(ctx)
+	return m.Evaluate(ctx, flagName, evalCtx)
+}
+
+func (m *Manager) evaluateVariant(ctx context.Context, flagName string, evalCtx EvalContext) (Evaluation, Variant, bool) {
+	var variant Variant
+	var served bool
+	result := m.evaluate(ctx, flagName, evalCtx)
+	if result.exists {
+		variant, served = resolveVariant(result.flag, result.enabled, evalCtx)
+	}
+
+	eval := Evaluation{
+		FlagName:    flagName,
+		Exists:      result.exists,
+		Enabled:     result.enabled,
+		Variant:     variant.Name,
+		Overridden:  result.overridden,
+		Flag:        result.flag,
+		EvalContext: evalCtx,
+	}
+	m.runHooks(ctx, eval)
+	return eval, variant, served
+}
+
+// StringVariant returns the string value of the variant served to evalCtx,
+// or defaultValue if the flag is missing, serves no variant, or the variant
+// is not a string.
+func (m *Manager) StringVariant(ctx context.Context, flagName string, evalCtx EvalContext, defaultValue string) string {
+	v, ok := m.Variant(ctx, flagName, evalCtx)
+	if !ok {
+		return defaultValue
+	}
+	s, ok := v.Value.(string)
+	if !ok {
+		return defaultValue
+	}
+	return s
+}
+
+// StringVariantCtx is StringVariant for the evaluation context attached to ctx.
+func (m *Manager) StringVariantCtx(ctx context.Context, flagName, defaultValue string) string {
+	evalCtx, _ := FromContext(ctx)
+	return m.StringVariant(ctx, flagName, evalCtx, defaultValue)
+}
+
+// IntVariant returns the integer value of the variant served to evalCtx,
+// or defaultValue if the flag is missing, serves no variant, or the variant
+// is not a whole number. Numeric strings are accepted, so values read from
+// text formats work as expected.
+func (m *Manager) IntVariant(ctx context.Context, flagName string, evalCtx EvalContext, defaultValue int) int {
+	v, ok := m.Variant(ctx, flagName, evalCtx)
+	if !ok {
+		return defaultValue
+	}
+	n, ok := toInt(v.Value)
+	if !ok {
+		return defaultValue
+	}
+	return n
+}
+
+// IntVariantCtx is IntVariant for the evaluation context attached to ctx.
+func (m *Manager) IntVariantCtx(ctx context.Context, flagName string, defaultValue int) int {
+	evalCtx, _ := FromContext(ctx)
+	return m.IntVariant(ctx, flagName, evalCtx, defaultValue)
+}
+
+// FloatVariant returns the numeric value of the variant served to evalCtx,
+// or defaultValue if the flag is missing, serves no variant, or the variant
+// is not a number.
+func (m *Manager) FloatVariant(ctx context.Context, flagName string, evalCtx EvalContext, defaultValue float64) float64 {
+	v, ok := m.Variant(ctx, flagName, evalCtx)
+	if !ok {
+		return defaultValue
+	}
+	f, ok := toFloat(v.Value)
+	if !ok {
+		return defaultValue
+	}
+	return f
+}
+
+// FloatVariantCtx is FloatVariant for the evaluation context attached to ctx.
+func (m *Manager) FloatVariantCtx(ctx context.Context, flagName string, defaultValue float64) float64 {
+	evalCtx, _ := FromContext(ctx)
+	return m.FloatVariant(ctx, flagName, evalCtx, defaultValue)
+}
+
+// JSONVariant decodes the value of the variant served to evalCtx into into,
+// which must be a pointer. If the flag is missing or serves no variant, into
+// is left untouched, so pre-populate it with the default. An error is
+// returned only when the value cannot be decoded into into.
+func (m *Manager) JSONVariant(ctx context.Context, flagName string, evalCtx EvalContext, into any) error {
+	v, ok := m.Variant(ctx, flagName, evalCtx)
+	if !ok {
+		return nil
+	}
+
+	data, err := json.Marshal(v.Value)
+	if err != nil {
+		return fmt.Errorf("failed to encode variant %q of flag %q: %w", v.Name, flagName, err)
+	}
+	if err := json.Unmarshal(data, into); err != nil {
+		return fmt.Errorf("failed to decode variant %q of flag %q: %w", v.Name, flagName, err)
+	}
+	return nil
+}
+
+// JSONVariantCtx is JSONVariant for the evaluation context attached to ctx.
+func (m *Manager) JSONVariantCtx(ctx context.Context, flagName string, into any) error {
+	evalCtx, _ := FromContext(ctx)
+	return m.JSONVariant(ctx, flagName, evalCtx, into)
+}
+
+func toInt(value any) (int, bool) {
+	switch n := value.(type) {
+	case int:
+		return n, true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	case string:
+		i, err := strconv.Atoi(n)
+		return i, err == nil
+	}
+
+	f, ok := toFloat(value)
+	if !ok || f != math.Trunc(f) || f >= math.MaxInt || f < math.MinInt {
+		return 0, false
+	}
+	return int(f), true
+}
+
+func toFloat(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
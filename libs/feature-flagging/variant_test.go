@@ -0,0 +1,178 @@
+package featureflag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestManager_TypedVariants(t *testing.T) {
+	ctx := context.Background()
+	m := New(newStaticProvider(t,
+		Flag{
+			Name:     "checkout-algorithm",
+			Enabled:  true,
+			Variants: []Variant{{Name: "v2", Value: "v2"}},
+		},
+		Flag{
+			Name:     "max-batch",
+			Enabled:  true,
+			Variants: []Variant{{Name: "large", Value: 500}},
+		},
+		Flag{
+			Name:    "max-batch-json",
+			Enabled: true,
+			// Numbers decoded from JSON arrive as float64.
+			Variants: []Variant{{Name: "large", Value: float64(750)}},
+		},
+		Flag{
+			Name:     "sample-rate",
+			Enabled:  true,
+			Variants: []Variant{{Name: "low", Value: 0.25}},
+		},
+		Flag{
+			Name:           "theme",
+			Enabled:        false,
+			DefaultVariant: "light",
+			Variants: []Variant{
+				{Name: "light", Value: "light"},
+				{Name: "dark", Value: "dark"},
+			},
+		},
+		Flag{
+			Name:     "off-no-default",
+			Enabled:  false,
+			Variants: []Variant{{Name: "on", Value: "on"}},
+		},
+	))
+	evalCtx := EvalContext{UserID: "u1"}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{name: "string", got: m.StringVariant(ctx, "checkout-algorithm", evalCtx, "v1"), want: "v2"},
+		{name: "string missing flag", got: m.StringVariant(ctx, "missing", evalCtx, "v1"), want: "v1"},
+		{name: "string type mismatch", got: m.StringVariant(ctx, "max-batch", evalCtx, "x"), want: "x"},
+		{name: "int", got: m.IntVariant(ctx, "max-batch", evalCtx, 100), want: 500},
+		{name: "int from float64", got: m.IntVariant(ctx, "max-batch-json", evalCtx, 100), want: 750},
+		{name: "int from fraction", got: m.IntVariant(ctx, "sample-rate", evalCtx, 1), want: 1},
+		{name: "int missing flag", got: m.IntVariant(ctx, "missing", evalCtx, 100), want: 100},
+		{name: "float", got: m.FloatVariant(ctx, "sample-rate", evalCtx, 1), want: 0.25},
+		{name: "float from int", got: m.FloatVariant(ctx, "max-batch", evalCtx, 1), want: float64(500)},
+		{name: "disabled serves default variant", got: m.StringVariant(ctx, "theme", evalCtx, "system"), want: "light"},
+		{name: "disabled without default variant", got: m.StringVariant(ctx, "off-no-default", evalCtx, "off"), want: "off"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v (%T), want %v (%T)", tt.got, tt.got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_JSONVariant(t *testing.T) {
+	type limits struct {
+		Requests int    `json:"requests"`
+		Window   string `json:"window"`
+	}
+
+	ctx := context.Background()
+	m := New(newStaticProvider(t,
+		Flag{
+			Name:    "rate-limits",
+			Enabled: true,
+			Variants: []Variant{{
+				Name:  "strict",
+				Value: map[string]any{"requests": 10, "window": "1m"},
+			}},
+		},
+		Flag{
+			Name:     "raw-limits",
+			Enabled:  true,
+			Variants: []Variant{{Name: "raw", Value: json.RawMessage(`{"requests":5,"window":"1s"}`)}},
+		},
+		Flag{
+			Name:     "bad-limits",
+			Enabled:  true,
+			Variants: []Variant{{Name: "bad", Value: "not an object"}},
+		},
+	))
+
+	got := limits{Requests: 100, Window: "1h"}
+	if err := m.JSONVariant(ctx, "rate-limits", EvalContext{}, &got); err != nil {
+		t.Fatalf("JSONVariant() unexpected error: %v", err)
+	}
+	if got != (limits{Requests: 10, Window: "1m"}) {
+		t.Errorf("JSONVariant() = %+v", got)
+	}
+
+	if err := m.JSONVariant(ctx, "raw-limits", EvalContext{}, &got); err != nil {
+		t.Fatalf("JSONVariant() unexpected error: %v", err)
+	}
+	if got != (limits{Requests: 5, Window: "1s"}) {
+		t.Errorf("JSONVariant() = %+v", got)
+	}
+
+	def := limits{Requests: 100, Window: "1h"}
+	got = def
+	if err := m.JSONVariant(ctx, "missing", EvalContext{}, &got); err != nil {
+		t.Fatalf("JSONVariant() unexpected error: %v", err)
+	}
+	if got != def {
+		t.Errorf("JSONVariant() on missing flag changed value to %+v", got)
+	}
+
+	if err := m.JSONVariant(ctx, "bad-limits", EvalContext{}, &got); err == nil {
+		t.Error("JSONVariant() expected error for mismatched value, got nil")
+	}
+}
+
+func TestManager_WeightedVariants(t *testing.T) {
+	ctx := context.Background()
+	m := New(newStaticProvider(t, Flag{
+		Name:           "checkout-experiment",
+		Enabled:        true,
+		DefaultVariant: "control",
+		Variants: []Variant{
+			{Name: "control", Value: "v1", Weight: 50},
+			{Name: "treatment-a", Value: "v2", Weight: 30},
+			{Name: "treatment-b", Value: "v3", Weight: 20},
+			{Name: "holdout", Value: "v0"},
+		},
+	}))
+
+	counts := make(map[string]int)
+	const users = 10000
+	for i := 0; i < users; i++ {
+		evalCtx := EvalContext{UserID: fmt.Sprintf("user-%d", i)}
+		v, ok := m.Variant(ctx, "checkout-experiment", evalCtx)
+		if !ok {
+			t.Fatalf("Variant() returned no variant for %s", evalCtx.UserID)
+		}
+		if again, _ := m.Variant(ctx, "checkout-experiment", evalCtx); again.Name != v.Name {
+			t.Fatalf("Variant() is not deterministic for %s", evalCtx.UserID)
+		}
+		counts[v.Name]++
+	}
+
+	want := map[string]int{"control": 50, "treatment-a": 30, "treatment-b": 20}
+	for name, pct := range want {
+		got := counts[name] * 100 / users
+		if got < pct-3 || got > pct+3 {
+			t.Errorf("variant %s served to %d%% of users, want about %d%%", name, got, pct)
+		}
+	}
+	if counts["holdout"] != 0 {
+		t.Errorf("zero-weight variant served to %d users", counts["holdout"])
+	}
+
+	// Without a bucketing key the default variant is served.
+	if v, _ := m.Variant(ctx, "checkout-experiment", EvalContext{}); v.Name != "control" {
+		t.Errorf("Variant() without user = %s, want control", v.Name)
+	}
+}
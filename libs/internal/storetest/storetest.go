@@ -0,0 +1,35 @@
+// Package storetest provides database fixtures for the libraries' tests.
+package storetest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/JWindy92/obelisk-platform/libs/store"
+	"github.com/JWindy92/obelisk-platform/libs/store/migrate"
+	"github.com/JWindy92/obelisk-platform/libs/store/sqlite"
+)
+
+// SQLite returns a connected SQLite store in a temporary directory with
+// sets migrated up. The sets are built for store.DialectSQLite. The store
+// is closed when the test ends.
+func SQLite(t testing.TB, sets ...migrate.Set) store.Store {
+	t.Helper()
+
+	ctx := context.Background()
+	st := sqlite.New(filepath.Join(t.TempDir(), "test.db"), store.Config{})
+	if err := st.Connect(ctx); err != nil {
+		t.Fatalf("Connect() failed: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+
+	m := migrate.New(st, migrate.Config{})
+	if err := m.Register(sets...); err != nil {
+		t.Fatalf("Register() failed: %v", err)
+	}
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() failed: %v", err)
+	}
+	return st
+}
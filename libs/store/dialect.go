@@ -0,0 +1,64 @@
+package store
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect identifies the SQL flavour spoken by a Store implementation.
+// Libraries that write raw SQL against a Store use it to pick the right
+// placeholder syntax and, where needed, dialect-specific statements.
+type Dialect string
+
+const (
+	// DialectSQLite uses "?" placeholders.
+	DialectSQLite Dialect = "sqlite"
+
+	// DialectPostgres uses "$1", "$2", ... placeholders.
+	DialectPostgres Dialect = "postgres"
+)
+
+// Placeholder returns the bind parameter for the n-th (1-based) argument.
+func (d Dialect) Placeholder(n int) string {
+	if d == DialectPostgres {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// Rebind rewrites a query written with "?" placeholders into the form
+// expected by the dialect. Question marks inside single-quoted string
+// literals are left untouched.
+func (d Dialect) Rebind(query string) string {
+	if d != DialectPostgres {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+
+	n := 0
+	inString := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			inString = !inString
+			b.WriteRune(r)
+		case r == '?' && !inString:
+			n++
+			b.WriteString(d.Placeholder(n))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// TimestampType returns the column type for timestamps that keep their time
+// zone: TIMESTAMPTZ on PostgreSQL and TIMESTAMP elsewhere.
+func (d Dialect) TimestampType() string {
+	if d == DialectPostgres {
+		return "TIMESTAMPTZ"
+	}
+	return "TIMESTAMP"
+}
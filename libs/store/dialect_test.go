@@ -0,0 +1,63 @@
+package store
+
+import "testing"
+
+func TestDialect_Rebind(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		query   string
+		want    string
+	}{
+		{
+			name:    "sqlite leaves placeholders untouched",
+			dialect: DialectSQLite,
+			query:   "SELECT * FROM users WHERE id = ? AND email = ?",
+			want:    "SELECT * FROM users WHERE id = ? AND email = ?",
+		},
+		{
+			name:    "postgres numbers placeholders",
+			dialect: DialectPostgres,
+			query:   "SELECT * FROM users WHERE id = ? AND email = ?",
+			want:    "SELECT * FROM users WHERE id = $1 AND email = $2",
+		},
+		{
+			name:    "postgres skips question marks in string literals",
+			dialect: DialectPostgres,
+			query:   "SELECT '?' FROM users WHERE id = ?",
+			want:    "SELECT '?' FROM users WHERE id = $1",
+		},
+		{
+			name:    "query without placeholders",
+			dialect: DialectPostgres,
+			query:   "SELECT 1",
+			want:    "SELECT 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dialect.Rebind(tt.query); got != tt.want {
+				t.Errorf("Rebind() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDialect_Placeholder(t *testing.T) {
+	if got := DialectSQLite.Placeholder(3); got != "?" {
+		t.Errorf("SQLite Placeholder(3) = %q, want %q", got, "?")
+	}
+	if got := DialectPostgres.Placeholder(3); got != "$3" {
+		t.Errorf("Postgres Placeholder(3) = %q, want %q", got, "$3")
+	}
+}
+
+func TestDialect_TimestampType(t *testing.T) {
+	if got := DialectSQLite.TimestampType(); got != "TIMESTAMP" {
+		t.Errorf("SQLite TimestampType() = %q, want %q", got, "TIMESTAMP")
+	}
+	if got := DialectPostgres.TimestampType(); got != "TIMESTAMPTZ" {
+		t.Errorf("Postgres TimestampType() = %q, want %q", got, "TIMESTAMPTZ")
+	}
+}
@@ -0,0 +1,376 @@
+// Package migrate applies ordered, versioned schema migrations to a store.Store.
+//
+// Each library registers its own Set of migrations; applied versions are
+// recorded per set in a tracking table, so sets evolve independently. Runs are
+// serialized across processes with a PostgreSQL advisory lock or, on SQLite,
+// an exclusive transaction.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"github.com/JWindy92/obelisk-platform/libs/store"
+)
+
+// DefaultTableName is the tracking table used when Config.TableName is empty.
+const DefaultTableName = "schema_migrations"
+
+// ErrIrreversible is returned by Down when a migration has no Down function.
+var ErrIrreversible = errors.New("migration is irreversible")
+
+// Config holds configuration options for a Migrator.
+type Config struct {
+	// TableName is the table that records applied migrations.
+	// Defaults to "schema_migrations" if not specified.
+	TableName string
+}
+
+// Status describes a registered migration and whether it has been applied.
+type Status struct {
+	Set       string
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator applies registered migration sets to a store.
+type Migrator struct {
+	store store.Store
+	table string
+	sets  []Set
+}
+
+// New creates a Migrator for the given store.
+// Register migration sets before calling Up.
+func New(st store.Store, config Config) *Migrator {
+	table := config.TableName
+	if table == "" {
+		table = DefaultTableName
+	}
+
+	return &Migrator{
+		store: st,
+		table: table,
+	}
+}
+
+// Register adds migration sets to the Migrator. Sets are applied in the
+// order they are registered. Registering two sets with the same name, or a
+// set with invalid migrations, returns an error.
+func (m *Migrator) Register(sets ...Set) error {
+	for _, set := range sets {
+		valid, err := set.validate()
+		if err != nil {
+			return err
+		}
+		if _, ok := m.set(valid.Name); ok {
+			return fmt.Errorf("migration set %s is already registered", valid.Name)
+		}
+		m.sets = append(m.sets, valid)
+	}
+	return nil
+}
+
+// Up applies every pending migration of every registered set.
+//
+// On PostgreSQL each migration runs in its own transaction, so a failure
+// leaves earlier migrations applied. On SQLite the whole run happens inside
+// one exclusive transaction and a failure rolls everything back.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(q store.Querier, step stepFunc) error {
+		for _, set := range m.sets {
+			applied, err := m.applied(ctx, q, set.Name)
+			if err != nil {
+				return err
+			}
+
+			for _, mig := range set.Migrations {
+				if _, ok := applied[mig.Version]; ok {
+					continue
+				}
+				err := step(ctx, func(q store.Querier) error {
+					if err := mig.Up(ctx, q); err != nil {
+						return err
+					}
+					return m.record(ctx, q, set.Name, mig)
+				})
+				if err != nil {
+					return fmt.Errorf("failed to apply migration %s/%d_%s: %w", set.Name, mig.Version, mig.Name, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Down reverts the most recently applied migrations of the named set,
+// newest first. A steps value of zero or less reverts all of them.
+// Returns ErrIrreversible if a migration to revert has no Down function.
+func (m *Migrator) Down(ctx context.Context, setName string, steps int) error {
+	set, ok := m.set(setName)
+	if !ok {
+		return fmt.Errorf("migration set %s is not registered", setName)
+	}
+
+	return m.withLock(ctx, func(q store.Querier, step stepFunc) error {
+		applied, err := m.applied(ctx, q, set.Name)
+		if err != nil {
+			return err
+		}
+
+		versions := make([]int64, 0, len(applied))
+		for v := range applied {
+			versions = append(versions, v)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+		if steps > 0 && steps < len(versions) {
+			versions = versions[:steps]
+		}
+
+		for _, version := range versions {
+			mig, ok := set.migration(version)
+			if !ok {
+				return fmt.Errorf("applied migration %s/%d is not registered", set.Name, version)
+			}
+			if mig.Down == nil {
+				return fmt.Errorf("cannot revert migration %s/%d_%s: %w", set.Name, mig.Version, mig.Name, ErrIrreversible)
+			}
+			err := step(ctx, func(q store.Querier) error {
+				if err := mig.Down(ctx, q); err != nil {
+					return err
+				}
+				return m.forget(ctx, q, set.Name, mig.Version)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to revert migration %s/%d_%s: %w", set.Name, mig.Version, mig.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Status reports every registered migration and whether it has been applied.
+// It only reads: it takes no lock and doesn't create the tracking table, so
+// before the first Up every migration is reported as pending.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	db := m.store.DB()
+	exists, err := m.tableExists(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	for _, set := range m.sets {
+		applied := map[int64]time.Time{}
+		if exists {
+			applied, err = m.applied(ctx, db, set.Name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, mig := range set.Migrations {
+			appliedAt, ok := applied[mig.Version]
+			statuses = append(statuses, Status{
+				Set:       set.Name,
+				Version:   mig.Version,
+				Name:      mig.Name,
+				Applied:   ok,
+				AppliedAt: appliedAt,
+			})
+		}
+	}
+	return statuses, nil
+}
+
+// stepFunc runs a single migration step atomically.
+type stepFunc func(ctx context.Context, fn func(q store.Querier) error) error
+
+// withLock acquires the migration lock for the store's dialect, makes sure
+// the tracking table exists and calls fn. The Querier passed to fn may be
+// used for reads; writes must go through step.
+func (m *Migrator) withLock(ctx context.Context, fn func(q store.Querier, step stepFunc) error) error {
+	conn, err := m.store.DB().Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if m.store.Dialect() == store.DialectPostgres {
+		return m.withAdvisoryLock(ctx, conn, fn)
+	}
+	return m.withExclusiveTx(ctx, conn, fn)
+}
+
+// withAdvisoryLock serializes runs with a session-level PostgreSQL advisory
+// lock and executes each step in its own transaction.
+func (m *Migrator) withAdvisoryLock(ctx context.Context, conn *sql.Conn, fn func(q store.Querier, step stepFunc) error) (err error) {
+	key := m.lockKey()
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		// Use a fresh context so the lock is released even if ctx was cancelled.
+		if _, unlockErr := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key); unlockErr != nil && err == nil {
+			err = fmt.Errorf("failed to release migration lock: %w", unlockErr)
+		}
+	}()
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+
+	step := func(ctx context.Context, apply func(q store.Querier) error) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if p := recover(); p != nil {
+				tx.Rollback()
+				panic(p)
+			}
+		}()
+		if err := apply(tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	}
+	return fn(conn, step)
+}
+
+// withExclusiveTx serializes runs by holding an exclusive SQLite transaction
+// for the whole run. Steps execute directly inside that transaction.
+func (m *Migrator) withExclusiveTx(ctx context.Context, conn *sql.Conn, fn func(q store.Querier, step stepFunc) error) error {
+	if _, err := conn.ExecContext(ctx, "BEGIN EXCLUSIVE"); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+
+	// A panicking migration must not return the connection to the pool with
+	// the exclusive transaction, and so the database lock, still held.
+	defer func() {
+		if p := recover(); p != nil {
+			conn.ExecContext(context.Background(), "ROLLBACK")
+			panic(p)
+		}
+	}()
+
+	err := m.ensureTable(ctx, conn)
+	if err == nil {
+		step := func(ctx context.Context, apply func(q store.Querier) error) error {
+			return apply(conn)
+		}
+		err = fn(conn, step)
+	}
+
+	if err != nil {
+		if _, rbErr := conn.ExecContext(context.Background(), "ROLLBACK"); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to roll back migrations: %w", rbErr))
+		}
+		return err
+	}
+
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		return fmt.Errorf("failed to commit migrations: %w", err)
+	}
+	return nil
+}
+
+// ensureTable creates the tracking table if it does not exist.
+func (m *Migrator) ensureTable(ctx context.Context, q store.Querier) error {
+	_, err := q.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			set_name TEXT NOT NULL,
+			version BIGINT NOT NULL,
+			name TEXT NOT NULL,
+			applied_at %s NOT NULL,
+			PRIMARY KEY (set_name, version)
+		)
+	`, m.table, m.store.Dialect().TimestampType()))
+	if err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+	return nil
+}
+
+// tableExists reports whether the tracking table has been created.
+func (m *Migrator) tableExists(ctx context.Context, q store.Querier) (bool, error) {
+	query := "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+	if m.store.Dialect() == store.DialectPostgres {
+		query = "SELECT COUNT(to_regclass($1))"
+	}
+	var count int
+	if err := q.QueryRowContext(ctx, query, m.table).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to look up migrations table: %w", err)
+	}
+	return count > 0, nil
+}
+
+// applied returns the applied versions of a set and when they were applied.
+func (m *Migrator) applied(ctx context.Context, q store.Querier, setName string) (map[int64]time.Time, error) {
+	query := m.store.Dialect().Rebind(fmt.Sprintf(
+		"SELECT version, applied_at FROM %s WHERE set_name = ?", m.table,
+	))
+
+	rows, err := q.QueryContext(ctx, query, setName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[version] = appliedAt.UTC()
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// record marks a migration as applied.
+func (m *Migrator) record(ctx context.Context, q store.Querier, setName string, mig Migration) error {
+	query := m.store.Dialect().Rebind(fmt.Sprintf(
+		"INSERT INTO %s (set_name, version, name, applied_at) VALUES (?, ?, ?, ?)", m.table,
+	))
+	_, err := q.ExecContext(ctx, query, setName, mig.Version, mig.Name, time.Now().UTC())
+	return err
+}
+
+// forget removes the record of an applied migration.
+func (m *Migrator) forget(ctx context.Context, q store.Querier, setName string, version int64) error {
+	query := m.store.Dialect().Rebind(fmt.Sprintf(
+		"DELETE FROM %s WHERE set_name = ? AND version = ?", m.table,
+	))
+	_, err := q.ExecContext(ctx, query, setName, version)
+	return err
+}
+
+// lockKey derives the advisory lock key from the tracking table name, so
+// migrators using different tables do not block each other.
+func (m *Migrator) lockKey() int64 {
+	h := fnv.New64a()
+	h.Write([]byte("migrate:" + m.table))
+	return int64(h.Sum64())
+}
+
+func (m *Migrator) set(name string) (Set, bool) {
+	for _, s := range m.sets {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Set{}, false
+}
@@ -0,0 +1,343 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/JWindy92/obelisk-platform/libs/store"
+	"github.com/JWindy92/obelisk-platform/libs/store/sqlite"
+)
+
+func newTestStore(t *testing.T, path string) store.Store {
+	t.Helper()
+
+	st := sqlite.New(path, store.Config{})
+	if err := st.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() failed: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return st
+}
+
+func tableExists(t *testing.T, st store.Store, name string) bool {
+	t.Helper()
+
+	var count int
+	err := st.DB().QueryRow(
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name,
+	).Scan(&count)
+	if err != nil {
+		t.Fatalf("Query sqlite_master failed: %v", err)
+	}
+	return count == 1
+}
+
+var widgetSet = Set{
+	Name: "widgets",
+	Migrations: []Migration{
+		{
+			Version: 2,
+			Name:    "create_gadgets",
+			Up:      SQL("CREATE TABLE gadgets (id INTEGER PRIMARY KEY)"),
+			Down:    SQL("DROP TABLE gadgets"),
+		},
+		{
+			Version: 1,
+			Name:    "create_widgets",
+			Up:      SQL("CREATE TABLE widgets (id INTEGER PRIMARY KEY)"),
+			Down:    SQL("DROP TABLE widgets"),
+		},
+	},
+}
+
+func TestMigrator_UpAndDown(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t, filepath.Join(t.TempDir(), "test.db"))
+
+	m := New(st, Config{})
+	if err := m.Register(widgetSet); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() unexpected error: %v", err)
+	}
+	if !tableExists(t, st, "widgets") || !tableExists(t, st, "gadgets") {
+		t.Fatal("Up() did not create tables")
+	}
+
+	// Running again is a no-op.
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("second Up() unexpected error: %v", err)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() unexpected error: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].Version != 1 || !statuses[0].Applied || !statuses[1].Applied {
+		t.Errorf("Status() = %+v, want both migrations applied in order", statuses)
+	}
+
+	if err := m.Down(ctx, "widgets", 1); err != nil {
+		t.Fatalf("Down() unexpected error: %v", err)
+	}
+	if tableExists(t, st, "gadgets") {
+		t.Error("Down(1) did not revert newest migration")
+	}
+	if !tableExists(t, st, "widgets") {
+		t.Error("Down(1) reverted too many migrations")
+	}
+
+	if err := m.Down(ctx, "widgets", 0); err != nil {
+		t.Fatalf("Down(0) unexpected error: %v", err)
+	}
+	if tableExists(t, st, "widgets") {
+		t.Error("Down(0) did not revert all migrations")
+	}
+}
+
+func TestMigrator_StatusBeforeUp(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t, filepath.Join(t.TempDir(), "test.db"))
+
+	m := New(st, Config{})
+	if err := m.Register(widgetSet); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() unexpected error: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].Applied || statuses[1].Applied {
+		t.Errorf("Status() = %+v, want both migrations pending", statuses)
+	}
+	if tableExists(t, st, DefaultTableName) {
+		t.Error("Status() created the migrations table")
+	}
+}
+
+func TestCreateTable(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t, filepath.Join(t.TempDir(), "test.db"))
+
+	set := CreateTable("lib", "things", "CREATE TABLE things (id INTEGER PRIMARY KEY)")
+	if set.Name != "lib_things" || len(set.Migrations) != 1 || set.Migrations[0].Name != "create_things" {
+		t.Errorf("CreateTable() = %+v", set)
+	}
+
+	m := New(st, Config{})
+	if err := m.Register(set); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() unexpected error: %v", err)
+	}
+	if !tableExists(t, st, "things") {
+		t.Fatal("Up() did not create table")
+	}
+	if err := m.Down(ctx, "lib_things", 0); err != nil {
+		t.Fatalf("Down() unexpected error: %v", err)
+	}
+	if tableExists(t, st, "things") {
+		t.Error("Down() did not drop table")
+	}
+}
+
+func TestMigrator_SetsAreTrackedIndependently(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t, filepath.Join(t.TempDir(), "test.db"))
+
+	other := Set{
+		Name: "other",
+		Migrations: []Migration{
+			{Version: 1, Name: "create_other", Up: SQL("CREATE TABLE other (id INTEGER)")},
+		},
+	}
+
+	m := New(st, Config{TableName: "custom_migrations"})
+	if err := m.Register(widgetSet, other); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() unexpected error: %v", err)
+	}
+
+	var count int
+	if err := st.DB().QueryRow("SELECT COUNT(*) FROM custom_migrations").Scan(&count); err != nil {
+		t.Fatalf("Query tracking table failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("tracking table has %d rows, want 3", count)
+	}
+
+	if err := m.Down(ctx, "other", 0); !errors.Is(err, ErrIrreversible) {
+		t.Errorf("Down() error = %v, want ErrIrreversible", err)
+	}
+	if err := m.Down(ctx, "missing", 0); err == nil {
+		t.Error("Down() of unregistered set expected error but got nil")
+	}
+}
+
+func TestMigrator_FailureRollsBack(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t, filepath.Join(t.TempDir(), "test.db"))
+
+	m := New(st, Config{})
+	err := m.Register(Set{
+		Name: "broken",
+		Migrations: []Migration{
+			{Version: 1, Name: "ok", Up: SQL("CREATE TABLE ok (id INTEGER)")},
+			{Version: 2, Name: "bad", Up: SQL("NOT VALID SQL")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+
+	if err := m.Up(ctx); err == nil {
+		t.Fatal("Up() expected error but got nil")
+	}
+	if tableExists(t, st, "ok") {
+		t.Error("failed Up() left earlier migration applied on SQLite")
+	}
+}
+
+func TestMigrator_PanicReleasesLock(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t, filepath.Join(t.TempDir(), "test.db"))
+
+	m := New(st, Config{})
+	err := m.Register(Set{
+		Name: "panicky",
+		Migrations: []Migration{
+			{Version: 1, Name: "ok", Up: SQL("CREATE TABLE ok (id INTEGER)")},
+			{Version: 2, Name: "boom", Up: func(ctx context.Context, q store.Querier) error { panic("boom") }},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Up() did not re-panic")
+			}
+		}()
+		m.Up(ctx)
+	}()
+
+	if tableExists(t, st, "ok") {
+		t.Error("panicking Up() left earlier migration applied on SQLite")
+	}
+	if _, err := st.DB().ExecContext(ctx, "CREATE TABLE after (id INTEGER)"); err != nil {
+		t.Errorf("write after panicking Up() failed, want lock released: %v", err)
+	}
+}
+
+func TestMigrator_Register(t *testing.T) {
+	up := SQL("SELECT 1")
+
+	tests := []struct {
+		name string
+		set  Set
+	}{
+		{name: "missing name", set: Set{Migrations: []Migration{{Version: 1, Up: up}}}},
+		{name: "non-positive version", set: Set{Name: "s", Migrations: []Migration{{Version: 0, Up: up}}}},
+		{name: "duplicate version", set: Set{Name: "s", Migrations: []Migration{{Version: 1, Up: up}, {Version: 1, Up: up}}}},
+		{name: "missing up", set: Set{Name: "s", Migrations: []Migration{{Version: 1}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(nil, Config{})
+			if err := m.Register(tt.set); err == nil {
+				t.Error("Register() expected error but got nil")
+			}
+		})
+	}
+
+	m := New(nil, Config{})
+	if err := m.Register(widgetSet); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+	if err := m.Register(widgetSet); err == nil {
+		t.Error("Register() of duplicate set expected error but got nil")
+	}
+}
+
+func TestMigrator_ConcurrentUp(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		m := New(newTestStore(t, path), Config{})
+		if err := m.Register(widgetSet); err != nil {
+			t.Fatalf("Register() unexpected error: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- m.Up(ctx)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent Up() unexpected error: %v", err)
+		}
+	}
+}
+
+func TestFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0001_create_things.up.sql":   {Data: []byte("CREATE TABLE things (id INTEGER)")},
+		"migrations/0001_create_things.down.sql": {Data: []byte("DROP TABLE things")},
+		"migrations/0002_add_index.up.sql":       {Data: []byte("CREATE INDEX things_id ON things (id)")},
+		"migrations/README.md":                   {Data: []byte("ignored")},
+	}
+
+	migrations, err := FromFS(fsys, "migrations")
+	if err != nil {
+		t.Fatalf("FromFS() unexpected error: %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("FromFS() returned %d migrations, want 2", len(migrations))
+	}
+	if migrations[0].Version != 1 || migrations[0].Name != "create_things" || migrations[0].Down == nil {
+		t.Errorf("FromFS() first migration = %+v", migrations[0])
+	}
+	if migrations[1].Version != 2 || migrations[1].Down != nil {
+		t.Errorf("FromFS() second migration = %+v", migrations[1])
+	}
+
+	ctx := context.Background()
+	st := newTestStore(t, filepath.Join(t.TempDir(), "test.db"))
+	m := New(st, Config{})
+	if err := m.Register(Set{Name: "things", Migrations: migrations}); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() unexpected error: %v", err)
+	}
+	if !tableExists(t, st, "things") {
+		t.Error("Up() did not apply migrations loaded from FS")
+	}
+
+	missingUp := fstest.MapFS{
+		"m/0001_orphan.down.sql": {Data: []byte("SELECT 1")},
+	}
+	if _, err := FromFS(missingUp, "m"); err == nil {
+		t.Error("FromFS() without up file expected error but got nil")
+	}
+}
@@ -0,0 +1,175 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/JWindy92/obelisk-platform/libs/store"
+)
+
+// Func applies or reverts a migration.
+// The Querier is bound to the migration's transaction; use it for every
+// statement so the migration and its tracking record commit together.
+type Func func(ctx context.Context, q store.Querier) error
+
+// Migration is a single versioned schema change.
+type Migration struct {
+	// Version orders migrations within a Set. Must be positive and unique.
+	Version int64
+
+	// Name is a short human-readable description, recorded when applied.
+	Name string
+
+	// Up applies the migration. Required.
+	Up Func
+
+	// Down reverts the migration. Optional; a Set containing migrations
+	// without Down can only be rolled back as far as the first such migration.
+	Down Func
+}
+
+// Set is an ordered group of migrations owned by a single library or
+// application. Sets are tracked independently, so libraries can version
+// their own schema without coordinating version numbers with each other.
+type Set struct {
+	// Name identifies the set in the tracking table, e.g. "usermgmt_users".
+	Name string
+
+	// Migrations may be given in any order; they are applied by Version.
+	Migrations []Migration
+}
+
+// SQL returns a Func that executes the given statements as a single Exec.
+func SQL(statements string) Func {
+	return func(ctx context.Context, q store.Querier) error {
+		_, err := q.ExecContext(ctx, statements)
+		return err
+	}
+}
+
+// CreateTable returns a set with a single migration that runs statements to
+// create tableName and drops the table when rolled back. The set is named
+// prefix + "_" + tableName, so a library can manage several tables of the
+// same kind with one Migrator.
+func CreateTable(prefix, tableName, statements string) Set {
+	return Set{
+		Name: prefix + "_" + tableName,
+		Migrations: []Migration{
+			{
+				Version: 1,
+				Name:    "create_" + tableName,
+				Up:      SQL(statements),
+				Down:    SQL(fmt.Sprintf("DROP TABLE %s", tableName)),
+			},
+		},
+	}
+}
+
+// migrationFile matches names such as "0001_create_users.up.sql".
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// FromFS loads migrations from .sql files in dir, typically an embed.FS.
+//
+// Files must be named <version>_<name>.up.sql and, optionally,
+// <version>_<name>.down.sql. Files that do not follow this pattern are
+// ignored. SQL is executed verbatim, so keep one directory per dialect
+// (e.g. "migrations/sqlite" and "migrations/postgres") when the statements
+// are not portable.
+func FromFS(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+
+		contents, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = SQL(string(contents))
+		} else {
+			m.Down = SQL(string(contents))
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == nil {
+			return nil, fmt.Errorf("migration %d_%s has no .up.sql file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sortMigrations(migrations)
+	return migrations, nil
+}
+
+// validate checks that a set is well formed and returns a copy sorted by version.
+func (s Set) validate() (Set, error) {
+	if s.Name == "" {
+		return Set{}, fmt.Errorf("migration set name is required")
+	}
+
+	seen := make(map[int64]bool, len(s.Migrations))
+	for _, m := range s.Migrations {
+		if m.Version <= 0 {
+			return Set{}, fmt.Errorf("set %s: migration version must be positive, got %d", s.Name, m.Version)
+		}
+		if seen[m.Version] {
+			return Set{}, fmt.Errorf("set %s: duplicate migration version %d", s.Name, m.Version)
+		}
+		if m.Up == nil {
+			return Set{}, fmt.Errorf("set %s: migration %d has no Up function", s.Name, m.Version)
+		}
+		seen[m.Version] = true
+	}
+
+	sorted := Set{Name: s.Name, Migrations: append([]Migration(nil), s.Migrations...)}
+	sortMigrations(sorted.Migrations)
+	return sorted, nil
+}
+
+// migration returns the migration with the given version.
+func (s Set) migration(version int64) (Migration, bool) {
+	for _, mig := range s.Migrations {
+		if mig.Version == version {
+			return mig, true
+		}
+	}
+	return Migration{}, false
+}
+
+func sortMigrations(migrations []Migration) {
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+}
@@ -3,10 +3,12 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 
 	"github.com/JWindy92/obelisk-platform/libs/store"
 )
@@ -83,3 +85,60 @@ func (p *PostgresStore) Close() error {
 func (p *PostgresStore) DB() *sql.DB {
 	return p.db
 }
+
+// Dialect reports that PostgresStore speaks the PostgreSQL dialect.
+func (p *PostgresStore) Dialect() store.Dialect {
+	return store.DialectPostgres
+}
+
+// uniqueViolation is the SQLSTATE code PostgreSQL reports for unique_violation.
+const uniqueViolation = "23505"
+
+// IsUniqueViolation reports whether err is a PostgreSQL unique_violation.
+func (p *PostgresStore) IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == uniqueViolation
+}
+
+// IsUniqueViolationOn reports whether err is a PostgreSQL unique_violation
+// on table whose key is column alone, as named in the error detail
+// ("Key (email)=(a@example.com) already exists."). It doesn't depend on the
+// constraint's name, so named and truncated constraints are matched too.
+func (p *PostgresStore) IsUniqueViolationOn(err error, table, column string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != uniqueViolation {
+		return false
+	}
+	if pqErr.Table != table[strings.LastIndex(table, ".")+1:] {
+		return false
+	}
+	columns, ok := keyColumns(pqErr.Detail)
+	return ok && len(columns) == 1 && columns[0] == column
+}
+
+// keyColumns extracts the column names from a unique_violation detail of
+// the form "Key (a, b)=(1, 2) already exists.".
+func keyColumns(detail string) ([]string, bool) {
+	rest, ok := strings.CutPrefix(detail, "Key (")
+	if !ok {
+		return nil, false
+	}
+	list, _, ok := strings.Cut(rest, ")=(")
+	if !ok {
+		return nil, false
+	}
+	columns := strings.Split(list, ", ")
+	for i, c := range columns {
+		columns[i] = strings.Trim(c, `"`)
+	}
+	return columns, true
+}
+
+// WithTx runs fn inside a transaction. See store.Store for details.
+func (p *PostgresStore) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	opts := &sql.TxOptions{Isolation: store.IsolationFrom(ctx, p.config.IsolationLevel)}
+	return store.RunInTx(ctx, p.db, opts, fn)
+}
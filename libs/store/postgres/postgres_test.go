@@ -2,10 +2,12 @@ package postgres
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/JWindy92/obelisk-platform/libs/store"
+	"github.com/lib/pq"
 )
 
 func TestPostgresStore_Connect(t *testing.T) {
@@ -199,3 +201,57 @@ func TestPostgresStore_ConnectionPoolSettings(t *testing.T) {
 		t.Errorf("MaxOpenConnections = %d, want 20", stats.MaxOpenConnections)
 	}
 }
+
+func TestPostgresStore_IsUniqueViolationOn(t *testing.T) {
+	st := New(Config{}, store.Config{})
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "default constraint name",
+			err:  &pq.Error{Code: "23505", Table: "users", Constraint: "users_email_key", Detail: "Key (email)=(a@example.com) already exists."},
+			want: true,
+		},
+		{
+			name: "named constraint",
+			err:  &pq.Error{Code: "23505", Table: "users", Constraint: "uq_user_email", Detail: "Key (email)=(a@example.com) already exists."},
+			want: true,
+		},
+		{
+			name: "quoted column",
+			err:  &pq.Error{Code: "23505", Table: "users", Detail: `Key ("email")=(a@example.com) already exists.`},
+			want: true,
+		},
+		{
+			name: "wrapped",
+			err:  fmt.Errorf("insert: %w", &pq.Error{Code: "23505", Table: "users", Detail: "Key (email)=(a@example.com) already exists."}),
+			want: true,
+		},
+		{
+			name: "other column",
+			err:  &pq.Error{Code: "23505", Table: "users", Constraint: "users_pkey", Detail: "Key (id)=(1) already exists."},
+		},
+		{
+			name: "composite key",
+			err:  &pq.Error{Code: "23505", Table: "users", Detail: "Key (tenant, email)=(t, a@example.com) already exists."},
+		},
+		{
+			name: "other table",
+			err:  &pq.Error{Code: "23505", Table: "admins", Detail: "Key (email)=(a@example.com) already exists."},
+		},
+		{
+			name: "not a unique violation",
+			err:  &pq.Error{Code: "23502", Table: "users", Column: "email"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := st.IsUniqueViolationOn(tt.err, "public.users", "email"); got != tt.want {
+				t.Errorf("IsUniqueViolationOn() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,10 +3,12 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 
 	"github.com/JWindy92/obelisk-platform/libs/store"
 )
@@ -68,3 +70,44 @@ func (s *SQLiteStore) Close() error {
 func (s *SQLiteStore) DB() *sql.DB {
 	return s.db
 }
+
+// Dialect reports that SQLiteStore speaks the SQLite dialect.
+func (s *SQLiteStore) Dialect() store.Dialect {
+	return store.DialectSQLite
+}
+
+// IsUniqueViolation reports whether err is a SQLite UNIQUE or PRIMARY KEY
+// constraint failure.
+func (s *SQLiteStore) IsUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique ||
+		sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+}
+
+// IsUniqueViolationOn reports whether err is a SQLite UNIQUE constraint
+// failure on table's column, as named in the error message
+// ("UNIQUE constraint failed: users.email").
+func (s *SQLiteStore) IsUniqueViolationOn(err error, table, column string) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) || sqliteErr.ExtendedCode != sqlite3.ErrConstraintUnique {
+		return false
+	}
+	table = table[strings.LastIndex(table, ".")+1:]
+	_, columns, ok := strings.Cut(sqliteErr.Error(), "constraint failed: ")
+	return ok && columns == table+"."+column
+}
+
+// WithTx runs fn inside a transaction. See store.Store for details.
+//
+// SQLite transactions are always serializable, so only sql.LevelDefault and
+// sql.LevelSerializable are accepted as isolation levels.
+func (s *SQLiteStore) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	level := store.IsolationFrom(ctx, s.config.IsolationLevel)
+	if level != sql.LevelDefault && level != sql.LevelSerializable {
+		return fmt.Errorf("sqlite does not support isolation level %s", level)
+	}
+	return store.RunInTx(ctx, s.db, nil, fn)
+}
@@ -0,0 +1,179 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/JWindy92/obelisk-platform/libs/store"
+)
+
+func newTxTestStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+
+	st := New(filepath.Join(t.TempDir(), "tx.db"), store.Config{})
+	ctx := context.Background()
+	if err := st.Connect(ctx); err != nil {
+		t.Fatalf("Connect() failed: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+
+	if _, err := st.DB().ExecContext(ctx, "CREATE TABLE items (name TEXT PRIMARY KEY)"); err != nil {
+		t.Fatalf("Create table failed: %v", err)
+	}
+	return st
+}
+
+func insertItem(ctx context.Context, st store.Store, name string) error {
+	_, err := store.QuerierFrom(ctx, st).ExecContext(ctx, "INSERT INTO items (name) VALUES (?)", name)
+	return err
+}
+
+func countItems(t *testing.T, st store.Store) int {
+	t.Helper()
+
+	var n int
+	if err := st.DB().QueryRow("SELECT COUNT(*) FROM items").Scan(&n); err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	return n
+}
+
+func TestSQLiteStore_WithTx_Commit(t *testing.T) {
+	st := newTxTestStore(t)
+
+	err := st.WithTx(context.Background(), func(ctx context.Context) error {
+		if err := insertItem(ctx, st, "a"); err != nil {
+			return err
+		}
+		return insertItem(ctx, st, "b")
+	})
+	if err != nil {
+		t.Fatalf("WithTx() unexpected error: %v", err)
+	}
+
+	if got := countItems(t, st); got != 2 {
+		t.Errorf("committed %d items, want 2", got)
+	}
+}
+
+func TestSQLiteStore_InTx(t *testing.T) {
+	st := newTxTestStore(t)
+	other := newTxTestStore(t)
+
+	if store.InTx(context.Background(), st) {
+		t.Error("InTx() = true outside WithTx")
+	}
+	err := st.WithTx(context.Background(), func(ctx context.Context) error {
+		if !store.InTx(ctx, st) {
+			t.Error("InTx() = false inside WithTx")
+		}
+		if store.InTx(ctx, other) {
+			t.Error("InTx() = true for a different store")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx() unexpected error: %v", err)
+	}
+}
+
+func TestSQLiteStore_WithTx_RollbackOnError(t *testing.T) {
+	st := newTxTestStore(t)
+	wantErr := errors.New("boom")
+
+	err := st.WithTx(context.Background(), func(ctx context.Context) error {
+		if err := insertItem(ctx, st, "a"); err != nil {
+			return err
+		}
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("WithTx() error = %v, want %v", err, wantErr)
+	}
+
+	if got := countItems(t, st); got != 0 {
+		t.Errorf("rolled back transaction left %d items, want 0", got)
+	}
+}
+
+func TestSQLiteStore_WithTx_RollbackOnPanic(t *testing.T) {
+	st := newTxTestStore(t)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("WithTx() did not re-panic")
+			}
+		}()
+		st.WithTx(context.Background(), func(ctx context.Context) error {
+			insertItem(ctx, st, "a")
+			panic("boom")
+		})
+	}()
+
+	if got := countItems(t, st); got != 0 {
+		t.Errorf("panicking transaction left %d items, want 0", got)
+	}
+}
+
+func TestSQLiteStore_WithTx_NestedSavepoint(t *testing.T) {
+	st := newTxTestStore(t)
+
+	err := st.WithTx(context.Background(), func(ctx context.Context) error {
+		if err := insertItem(ctx, st, "outer"); err != nil {
+			return err
+		}
+
+		// A failing nested call only rolls back its own savepoint.
+		nestedErr := st.WithTx(ctx, func(ctx context.Context) error {
+			if err := insertItem(ctx, st, "inner-discarded"); err != nil {
+				return err
+			}
+			return errors.New("nested failure")
+		})
+		if nestedErr == nil {
+			t.Error("nested WithTx() expected error but got nil")
+		}
+
+		return st.WithTx(ctx, func(ctx context.Context) error {
+			return insertItem(ctx, st, "inner-kept")
+		})
+	})
+	if err != nil {
+		t.Fatalf("WithTx() unexpected error: %v", err)
+	}
+
+	rows, err := st.DB().Query("SELECT name FROM items ORDER BY name")
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		rows.Scan(&name)
+		names = append(names, name)
+	}
+	if len(names) != 2 || names[0] != "inner-kept" || names[1] != "outer" {
+		t.Errorf("committed items = %v, want [inner-kept outer]", names)
+	}
+}
+
+func TestSQLiteStore_WithTx_IsolationLevel(t *testing.T) {
+	st := newTxTestStore(t)
+	noop := func(ctx context.Context) error { return nil }
+
+	ctx := store.WithIsolation(context.Background(), sql.LevelSerializable)
+	if err := st.WithTx(ctx, noop); err != nil {
+		t.Errorf("WithTx() serializable unexpected error: %v", err)
+	}
+
+	ctx = store.WithIsolation(context.Background(), sql.LevelReadCommitted)
+	if err := st.WithTx(ctx, noop); err == nil {
+		t.Error("WithTx() read committed expected error but got nil")
+	}
+}
@@ -19,6 +19,25 @@ type Store interface {
 	// DB returns the underlying *sql.DB for cases where direct access is needed.
 	// Use sparingly - prefer adding methods to the Store interface instead.
 	DB() *sql.DB
+
+	// Dialect reports which SQL dialect the database speaks, so callers
+	// writing raw SQL can use the correct placeholder syntax.
+	Dialect() Dialect
+
+	// IsUniqueViolation reports whether err was raised by the driver
+	// because a unique or primary key constraint was violated.
+	IsUniqueViolation(err error) bool
+
+	// IsUniqueViolationOn reports whether err is a unique violation of the
+	// single-column UNIQUE constraint on table's column, so callers can tell
+	// it apart from other unique and primary key violations.
+	IsUniqueViolationOn(err error, table, column string) bool
+
+	// WithTx runs fn inside a transaction carried by the context passed to fn.
+	// Repositories that obtain their Querier via QuerierFrom join it
+	// automatically. Nested calls use savepoints. The transaction is rolled
+	// back if fn returns an error or panics, and committed otherwise.
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 // Config holds common configuration options for database connections.
@@ -32,4 +51,18 @@ type Config struct {
 	// ConnMaxLifetime sets the maximum time a connection can be reused.
 	// Use time.Duration (e.g., time.Hour)
 	ConnMaxLifetime int64
+
+	// IsolationLevel is the default isolation level for WithTx.
+	// Override it for a single call with WithIsolation.
+	// Defaults to the database's own default if not specified.
+	IsolationLevel sql.IsolationLevel
+}
+
+// Querier is the set of query methods shared by *sql.DB, *sql.Tx and *sql.Conn.
+// Code that only needs to run statements should accept a Querier so it works
+// both inside and outside a transaction.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 }
@@ -0,0 +1,123 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// txKey is the context key under which the active transaction is stored.
+type txKey struct{}
+
+// isolationKey is the context key for a per-call isolation level override.
+type isolationKey struct{}
+
+// txState tracks a transaction bound to a context.
+type txState struct {
+	db         *sql.DB
+	tx         *sql.Tx
+	savepoints int
+}
+
+// WithIsolation returns a context that makes the next top-level WithTx call
+// use the given isolation level instead of the store's configured default.
+// It has no effect on nested calls, which join the outer transaction.
+func WithIsolation(ctx context.Context, level sql.IsolationLevel) context.Context {
+	return context.WithValue(ctx, isolationKey{}, level)
+}
+
+// IsolationFrom returns the isolation level requested with WithIsolation,
+// or fallback if none was requested.
+func IsolationFrom(ctx context.Context, fallback sql.IsolationLevel) sql.IsolationLevel {
+	if level, ok := ctx.Value(isolationKey{}).(sql.IsolationLevel); ok {
+		return level
+	}
+	return fallback
+}
+
+// QuerierFrom returns the transaction that WithTx bound to ctx for st, or
+// st.DB() if ctx carries no transaction for that store. Repositories should
+// run every statement through it so they transparently join transactions.
+func QuerierFrom(ctx context.Context, st Store) Querier {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok && state.db == st.DB() {
+		return state.tx
+	}
+	return st.DB()
+}
+
+// InTx reports whether ctx carries a transaction that WithTx bound for st.
+// Code that keeps state outside the database can use it to hold back
+// updates that a later rollback would invalidate.
+func InTx(ctx context.Context, st Store) bool {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	return ok && state.db == st.DB()
+}
+
+// RunInTx runs fn inside a transaction on db and is the shared implementation
+// of Store.WithTx.
+//
+// The transaction is stored in the context passed to fn. If ctx already
+// carries a transaction for db, fn runs inside a SAVEPOINT of that
+// transaction instead, so nested calls can fail without aborting the outer
+// work. The transaction (or savepoint) is rolled back if fn returns an error
+// or panics, and committed (or released) otherwise.
+func RunInTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(ctx context.Context) error) (err error) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok && state.db == db {
+		return runInSavepoint(ctx, state, fn)
+	}
+
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	txCtx := context.WithValue(ctx, txKey{}, &txState{db: db, tx: tx})
+	if err := fn(txCtx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to roll back transaction: %w", rbErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// runInSavepoint runs fn inside a savepoint of an existing transaction.
+func runInSavepoint(ctx context.Context, state *txState, fn func(ctx context.Context) error) error {
+	state.savepoints++
+	name := fmt.Sprintf("sp_%d", state.savepoints)
+
+	if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			state.tx.ExecContext(context.Background(), "ROLLBACK TO SAVEPOINT "+name)
+			panic(p)
+		}
+	}()
+
+	if err := fn(ctx); err != nil {
+		if _, rbErr := state.tx.ExecContext(context.Background(), "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to roll back savepoint: %w", rbErr))
+		}
+		return err
+	}
+
+	if _, err := state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	return nil
+}
@@ -25,3 +25,23 @@ type PasswordHasher interface {
 	// Compare checks if a plain text password matches a hash
 	Compare(password, hash string) error
 }
+
+// Rehasher is an optional capability of a PasswordHasher.
+// When the configured hasher implements it, Service.Login re-hashes the
+// password of any user whose stored hash NeedsRehash, so raising cost
+// parameters or switching algorithms upgrades hashes transparently.
+type Rehasher interface {
+	// NeedsRehash reports whether hash was produced with an algorithm or
+	// parameters other than the ones the hasher currently uses.
+	NeedsRehash(hash string) bool
+}
+
+// PasswordLimiter is an optional capability of a PasswordHasher that
+// cannot hash arbitrarily long passwords. When the configured hasher
+// implements it, Signup and UpdateUser report longer passwords as a
+// *ValidationError instead of failing to hash them.
+type PasswordLimiter interface {
+	// MaxLength returns the longest password, in bytes, the hasher
+	// accepts, or zero if it has no limit.
+	MaxLength() int
+}
@@ -0,0 +1,65 @@
+package usermgmt
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrUserNotFound is returned when no user matches the requested ID or email.
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrEmailTaken is returned when creating or updating a user would
+	// duplicate an email address that already belongs to another user.
+	ErrEmailTaken = errors.New("email already taken")
+
+	// ErrInvalidCredentials is returned by Login when the email is unknown or
+	// the password does not match. The two cases are deliberately not
+	// distinguished to avoid revealing which emails are registered.
+	ErrInvalidCredentials = errors.New("invalid email or password")
+)
+
+// FieldError describes a problem with a single request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned when a request fails validation.
+// It carries one FieldError per invalid field so callers such as an HTTP
+// layer can render them without parsing the error string.
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Field + ": " + f.Message
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// Add records a problem with the given field.
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// Message returns the message for the given field, or "" if it is valid.
+func (e *ValidationError) Message(field string) string {
+	for _, f := range e.Fields {
+		if f.Field == field {
+			return f.Message
+		}
+	}
+	return ""
+}
+
+// errOrNil returns e as an error if any fields were recorded, otherwise nil.
+func (e *ValidationError) errOrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
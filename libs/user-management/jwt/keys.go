@@ -0,0 +1,228 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"sync"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+)
+
+// Algorithm identifies a JWS signing algorithm supported by the provider.
+type Algorithm string
+
+const (
+	HS256 Algorithm = "HS256"
+	RS256 Algorithm = "RS256"
+	EdDSA Algorithm = "EdDSA"
+)
+
+// Minimum key sizes accepted by the constructors.
+const (
+	minHS256SecretSize = 32
+	minRSAKeyBits      = 2048
+)
+
+// Key is a signing or verification key identified by a key ID ("kid").
+// Build keys with the HS256Key, RS256Key and EdDSAKey constructors, or their
+// public-key variants for keys that should only verify tokens.
+type Key struct {
+	ID        string
+	Algorithm Algorithm
+
+	signKey   any
+	verifyKey any
+}
+
+// HS256Key returns a symmetric HMAC-SHA256 key. The secret must be at least
+// 32 bytes long.
+func HS256Key(id string, secret []byte) (Key, error) {
+	k := Key{ID: id, Algorithm: HS256, signKey: secret, verifyKey: secret}
+	return k, k.validate()
+}
+
+// RS256Key returns an RSA key that can sign and verify tokens. The key must
+// be at least 2048 bits.
+func RS256Key(id string, private *rsa.PrivateKey) (Key, error) {
+	if private == nil {
+		return Key{}, fmt.Errorf("key %s: RSA private key is required", id)
+	}
+	k := Key{ID: id, Algorithm: RS256, signKey: private, verifyKey: &private.PublicKey}
+	return k, k.validate()
+}
+
+// RS256PublicKey returns an RSA key that can only verify tokens.
+func RS256PublicKey(id string, public *rsa.PublicKey) (Key, error) {
+	k := Key{ID: id, Algorithm: RS256, verifyKey: public}
+	return k, k.validate()
+}
+
+// EdDSAKey returns an Ed25519 key that can sign and verify tokens.
+func EdDSAKey(id string, private ed25519.PrivateKey) (Key, error) {
+	if len(private) != ed25519.PrivateKeySize {
+		return Key{}, fmt.Errorf("key %s: Ed25519 private key must be %d bytes, got %d", id, ed25519.PrivateKeySize, len(private))
+	}
+	k := Key{ID: id, Algorithm: EdDSA, signKey: private, verifyKey: private.Public()}
+	return k, k.validate()
+}
+
+// EdDSAPublicKey returns an Ed25519 key that can only verify tokens.
+func EdDSAPublicKey(id string, public ed25519.PublicKey) (Key, error) {
+	k := Key{ID: id, Algorithm: EdDSA, verifyKey: public}
+	return k, k.validate()
+}
+
+// CanSign reports whether the key holds private (or symmetric) material.
+func (k Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// method returns the signing method for the key's algorithm.
+func (k Key) method() (jwtlib.SigningMethod, error) {
+	switch k.Algorithm {
+	case HS256:
+		return jwtlib.SigningMethodHS256, nil
+	case RS256:
+		return jwtlib.SigningMethodRS256, nil
+	case EdDSA:
+		return jwtlib.SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("unsupported algorithm %q", k.Algorithm)
+}
+
+// validate checks that the key is usable.
+func (k Key) validate() error {
+	if k.ID == "" {
+		return errors.New("key id is required")
+	}
+	if _, err := k.method(); err != nil {
+		return fmt.Errorf("key %s: %w", k.ID, err)
+	}
+	if err := k.validateMaterial(); err != nil {
+		return fmt.Errorf("key %s: %w", k.ID, err)
+	}
+	return nil
+}
+
+// validateMaterial checks the concrete key material against the key's
+// algorithm. The fields hold interfaces, so a nil slice or pointer stored in
+// them is not caught by a plain nil check.
+func (k Key) validateMaterial() error {
+	switch k.Algorithm {
+	case HS256:
+		secret, _ := k.verifyKey.([]byte)
+		if len(secret) < minHS256SecretSize {
+			return fmt.Errorf("HS256 secret must be at least %d bytes, got %d", minHS256SecretSize, len(secret))
+		}
+	case RS256:
+		public, _ := k.verifyKey.(*rsa.PublicKey)
+		if public == nil || public.N == nil {
+			return errors.New("RSA public key is required")
+		}
+		if bits := public.N.BitLen(); bits < minRSAKeyBits {
+			return fmt.Errorf("RSA key must be at least %d bits, got %d", minRSAKeyBits, bits)
+		}
+		if k.signKey != nil {
+			if private, _ := k.signKey.(*rsa.PrivateKey); private == nil {
+				return errors.New("RSA private key is required")
+			}
+		}
+	case EdDSA:
+		public, _ := k.verifyKey.(ed25519.PublicKey)
+		if len(public) != ed25519.PublicKeySize {
+			return fmt.Errorf("Ed25519 public key must be %d bytes, got %d", ed25519.PublicKeySize, len(public))
+		}
+		if k.signKey != nil {
+			if private, _ := k.signKey.(ed25519.PrivateKey); len(private) != ed25519.PrivateKeySize {
+				return fmt.Errorf("Ed25519 private key must be %d bytes, got %d", ed25519.PrivateKeySize, len(private))
+			}
+		}
+	}
+	return nil
+}
+
+// KeySet holds the keys used to sign and verify tokens.
+//
+// New tokens are signed with the active key and carry its ID in the "kid"
+// header. Tokens are verified with whichever key their "kid" names, so a key
+// can be rotated by adding a new active key while keeping the old one around
+// until the tokens it signed have expired. KeySet is safe for concurrent use.
+type KeySet struct {
+	mu     sync.RWMutex
+	keys   map[string]Key
+	active string
+}
+
+// NewKeySet creates a KeySet that signs with active and also accepts tokens
+// signed by any of the verification-only keys in others.
+func NewKeySet(active Key, others ...Key) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]Key)}
+	for _, k := range others {
+		if err := ks.Add(k); err != nil {
+			return nil, err
+		}
+	}
+	if err := ks.Rotate(active); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// Add registers a key for verification without making it active.
+func (ks *KeySet) Add(k Key) error {
+	if err := k.validate(); err != nil {
+		return err
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys[k.ID] = k
+	return nil
+}
+
+// Rotate registers k and makes it the active signing key. Previously active
+// keys remain available for verifying tokens they signed.
+func (ks *KeySet) Rotate(k Key) error {
+	if err := k.validate(); err != nil {
+		return err
+	}
+	if !k.CanSign() {
+		return fmt.Errorf("key %s cannot sign tokens", k.ID)
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys[k.ID] = k
+	ks.active = k.ID
+	return nil
+}
+
+// Remove drops a retired key. Tokens it signed no longer validate.
+// The active key cannot be removed.
+func (ks *KeySet) Remove(id string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if id == ks.active {
+		return fmt.Errorf("cannot remove active key %s", id)
+	}
+	delete(ks.keys, id)
+	return nil
+}
+
+// Active returns the key currently used for signing.
+func (ks *KeySet) Active() Key {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.keys[ks.active]
+}
+
+// Lookup returns the key with the given ID.
+func (ks *KeySet) Lookup(id string) (Key, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	k, ok := ks.keys[id]
+	return k, ok
+}
@@ -0,0 +1,215 @@
+// Package jwt provides a usermgmt.AuthProvider that issues stateless JSON
+// Web Tokens signed with HS256, RS256 or EdDSA.
+//
+// Keys are held in a KeySet and selected by the token's "kid" header, so
+// signing keys can be rotated without invalidating tokens that are still
+// live. Because JWTs are stateless, RevokeToken records the token's ID in a
+// RevocationList that ValidateToken consults.
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+
+	usermgmt "github.com/JWindy92/obelisk-platform/libs/user-management"
+)
+
+var (
+	// ErrInvalidToken is returned when a token is malformed, has a bad
+	// signature, is expired or does not match the configured issuer/audience.
+	ErrInvalidToken = errors.New("invalid token")
+
+	// ErrTokenRevoked is returned when a token has been revoked.
+	ErrTokenRevoked = errors.New("token revoked")
+
+	// ErrRevocationUnsupported is returned by RevokeToken when the provider
+	// was created without a RevocationList.
+	ErrRevocationUnsupported = errors.New("token revocation is not configured")
+)
+
+// Config holds configuration options for the JWT provider.
+type Config struct {
+	// Issuer is set as the "iss" claim and required when validating.
+	// Optional.
+	Issuer string
+
+	// Audience is set as the "aud" claim and required when validating.
+	// Optional.
+	Audience string
+
+	// TTL is how long issued tokens remain valid.
+	// Defaults to 1 hour if not specified.
+	TTL time.Duration
+
+	// Leeway allows for clock skew when checking time-based claims.
+	Leeway time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		TTL:    time.Hour,
+		Leeway: 30 * time.Second,
+	}
+}
+
+// claims are the registered claims plus the user's email.
+type claims struct {
+	Email string `json:"email,omitempty"`
+	jwtlib.RegisteredClaims
+}
+
+// Provider implements usermgmt.AuthProvider with signed JWTs.
+type Provider struct {
+	keys        *KeySet
+	revocations RevocationList
+	config      Config
+	now         func() time.Time
+}
+
+var _ usermgmt.AuthProvider = (*Provider)(nil)
+
+// NewProvider creates a JWT provider that signs with keys.
+// revocations may be nil, in which case RevokeToken is unsupported.
+func NewProvider(keys *KeySet, revocations RevocationList, config Config) *Provider {
+	if config.TTL <= 0 {
+		config.TTL = DefaultConfig().TTL
+	}
+
+	return &Provider{
+		keys:        keys,
+		revocations: revocations,
+		config:      config,
+		now:         time.Now,
+	}
+}
+
+// GenerateToken creates a token for the user signed with the active key.
+func (p *Provider) GenerateToken(ctx context.Context, user *usermgmt.User) (string, error) {
+	key := p.keys.Active()
+	method, err := key.method()
+	if err != nil {
+		return "", err
+	}
+
+	id, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	now := p.now()
+	c := claims{
+		Email: user.Email,
+		RegisteredClaims: jwtlib.RegisteredClaims{
+			ID:        id,
+			Subject:   user.ID,
+			Issuer:    p.config.Issuer,
+			IssuedAt:  jwtlib.NewNumericDate(now),
+			NotBefore: jwtlib.NewNumericDate(now),
+			ExpiresAt: jwtlib.NewNumericDate(now.Add(p.config.TTL)),
+		},
+	}
+	if p.config.Audience != "" {
+		c.Audience = jwtlib.ClaimStrings{p.config.Audience}
+	}
+
+	token := jwtlib.NewWithClaims(method, c)
+	token.Header["kid"] = key.ID
+
+	signed, err := token.SignedString(key.signKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, nil
+}
+
+// ValidateToken verifies the token's signature and claims and returns the
+// user it was issued for. Only the ID and Email fields are populated.
+func (p *Provider) ValidateToken(ctx context.Context, token string) (*usermgmt.User, error) {
+	c, err := p.parse(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.revocations != nil {
+		revoked, err := p.revocations.IsRevoked(ctx, c.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check token revocation: %w", err)
+		}
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+
+	return &usermgmt.User{ID: c.Subject, Email: c.Email}, nil
+}
+
+// RevokeToken adds the token's ID to the revocation list until it expires,
+// including the Leeway during which an expired token is still accepted.
+// Revoking an already revoked token is not an error.
+func (p *Provider) RevokeToken(ctx context.Context, token string) error {
+	if p.revocations == nil {
+		return ErrRevocationUnsupported
+	}
+
+	c, err := p.parse(token)
+	if err != nil {
+		return err
+	}
+	return p.revocations.Revoke(ctx, c.ID, c.ExpiresAt.Time.Add(p.config.Leeway))
+}
+
+// parse verifies the token and returns its claims.
+func (p *Provider) parse(token string) (*claims, error) {
+	opts := []jwtlib.ParserOption{
+		jwtlib.WithValidMethods([]string{string(HS256), string(RS256), string(EdDSA)}),
+		jwtlib.WithExpirationRequired(),
+		jwtlib.WithLeeway(p.config.Leeway),
+		jwtlib.WithTimeFunc(p.now),
+	}
+	if p.config.Issuer != "" {
+		opts = append(opts, jwtlib.WithIssuer(p.config.Issuer))
+	}
+	if p.config.Audience != "" {
+		opts = append(opts, jwtlib.WithAudience(p.config.Audience))
+	}
+
+	var c claims
+	_, err := jwtlib.ParseWithClaims(token, &c, p.keyFunc, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if c.ID == "" || c.Subject == "" {
+		return nil, fmt.Errorf("%w: missing jti or sub claim", ErrInvalidToken)
+	}
+	return &c, nil
+}
+
+// keyFunc resolves the verification key named by the token's "kid" header
+// and rejects tokens whose algorithm does not match that key.
+func (p *Provider) keyFunc(token *jwtlib.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := p.keys.Lookup(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if token.Method.Alg() != string(key.Algorithm) {
+		return nil, fmt.Errorf("key %s does not accept algorithm %s", kid, token.Method.Alg())
+	}
+	return key.verifyKey, nil
+}
+
+// newTokenID returns a random token identifier for the "jti" claim.
+func newTokenID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
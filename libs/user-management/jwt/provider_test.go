@@ -0,0 +1,323 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/JWindy92/obelisk-platform/libs/internal/storetest"
+	"github.com/JWindy92/obelisk-platform/libs/store"
+	usermgmt "github.com/JWindy92/obelisk-platform/libs/user-management"
+)
+
+var testUser = &usermgmt.User{ID: "user-1", Email: "user@example.com"}
+
+const testSecret = "secret-secret-secret-secret-secret"
+
+func newKeySet(t *testing.T, active Key, others ...Key) *KeySet {
+	t.Helper()
+	ks, err := NewKeySet(active, others...)
+	if err != nil {
+		t.Fatalf("NewKeySet() failed: %v", err)
+	}
+	return ks
+}
+
+func newHS256Key(t *testing.T, id, secret string) Key {
+	t.Helper()
+	key, err := HS256Key(id, []byte(secret))
+	if err != nil {
+		t.Fatalf("HS256Key() failed: %v", err)
+	}
+	return key
+}
+
+func TestProvider_Algorithms(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() failed: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey() failed: %v", err)
+	}
+
+	rs, err := RS256Key("rs", rsaKey)
+	if err != nil {
+		t.Fatalf("RS256Key() failed: %v", err)
+	}
+	ed, err := EdDSAKey("ed", edKey)
+	if err != nil {
+		t.Fatalf("EdDSAKey() failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		key  Key
+	}{
+		{name: "HS256", key: newHS256Key(t, "hs", testSecret)},
+		{name: "RS256", key: rs},
+		{name: "EdDSA", key: ed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			p := NewProvider(newKeySet(t, tt.key), nil, Config{Issuer: "obelisk", Audience: "api"})
+
+			token, err := p.GenerateToken(ctx, testUser)
+			if err != nil {
+				t.Fatalf("GenerateToken() unexpected error: %v", err)
+			}
+
+			user, err := p.ValidateToken(ctx, token)
+			if err != nil {
+				t.Fatalf("ValidateToken() unexpected error: %v", err)
+			}
+			if user.ID != testUser.ID || user.Email != testUser.Email {
+				t.Errorf("ValidateToken() = %+v, want %+v", user, testUser)
+			}
+		})
+	}
+}
+
+func TestKeys_RejectInvalidMaterial(t *testing.T) {
+	smallRSA, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		key  func() (Key, error)
+	}{
+		{name: "HS256 nil secret", key: func() (Key, error) { return HS256Key("k", nil) }},
+		{name: "HS256 empty secret", key: func() (Key, error) { return HS256Key("k", []byte{}) }},
+		{name: "HS256 short secret", key: func() (Key, error) { return HS256Key("k", []byte("too-short")) }},
+		{name: "HS256 missing id", key: func() (Key, error) { return HS256Key("", []byte(testSecret)) }},
+		{name: "RS256 nil private key", key: func() (Key, error) { return RS256Key("k", nil) }},
+		{name: "RS256 nil public key", key: func() (Key, error) { return RS256PublicKey("k", nil) }},
+		{name: "RS256 small key", key: func() (Key, error) { return RS256Key("k", smallRSA) }},
+		{name: "RS256 small public key", key: func() (Key, error) { return RS256PublicKey("k", &smallRSA.PublicKey) }},
+		{name: "EdDSA nil private key", key: func() (Key, error) { return EdDSAKey("k", nil) }},
+		{name: "EdDSA short private key", key: func() (Key, error) { return EdDSAKey("k", make(ed25519.PrivateKey, 16)) }},
+		{name: "EdDSA nil public key", key: func() (Key, error) { return EdDSAPublicKey("k", nil) }},
+		{name: "EdDSA short public key", key: func() (Key, error) { return EdDSAPublicKey("k", make(ed25519.PublicKey, 16)) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.key(); err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+
+	t.Run("zero key", func(t *testing.T) {
+		if _, err := NewKeySet(Key{ID: "k", Algorithm: HS256}); err == nil {
+			t.Error("NewKeySet() expected error but got nil")
+		}
+	})
+}
+
+func TestProvider_RejectsInvalidTokens(t *testing.T) {
+	ctx := context.Background()
+	key := newHS256Key(t, "k1", testSecret)
+	issuer := NewProvider(newKeySet(t, key), nil, Config{Issuer: "obelisk", Audience: "api", TTL: time.Minute})
+
+	token, err := issuer.GenerateToken(ctx, testUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		provider *Provider
+		token    string
+	}{
+		{
+			name:     "wrong issuer",
+			provider: NewProvider(newKeySet(t, key), nil, Config{Issuer: "other", Audience: "api"}),
+			token:    token,
+		},
+		{
+			name:     "wrong audience",
+			provider: NewProvider(newKeySet(t, key), nil, Config{Issuer: "obelisk", Audience: "other"}),
+			token:    token,
+		},
+		{
+			name:     "unknown key id",
+			provider: NewProvider(newKeySet(t, newHS256Key(t, "k2", testSecret)), nil, Config{}),
+			token:    token,
+		},
+		{
+			name:     "tampered token",
+			provider: issuer,
+			token:    token[:len(token)-2] + "xx",
+		},
+		{
+			name:     "garbage",
+			provider: issuer,
+			token:    "not-a-token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.provider.ValidateToken(ctx, tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("ValidateToken() error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		expired := NewProvider(newKeySet(t, key), nil, Config{TTL: time.Minute})
+		expired.now = func() time.Time { return time.Now().Add(time.Hour) }
+		if _, err := expired.ValidateToken(ctx, token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("ValidateToken() error = %v, want ErrInvalidToken", err)
+		}
+	})
+}
+
+func TestProvider_KeyRotation(t *testing.T) {
+	ctx := context.Background()
+	oldKey := newHS256Key(t, "2024-01", "old-secret-old-secret-old-secret")
+	newKey := newHS256Key(t, "2024-02", "new-secret-new-secret-new-secret")
+
+	keys := newKeySet(t, oldKey)
+	p := NewProvider(keys, nil, DefaultConfig())
+
+	oldToken, err := p.GenerateToken(ctx, testUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() unexpected error: %v", err)
+	}
+
+	if err := keys.Rotate(newKey); err != nil {
+		t.Fatalf("Rotate() unexpected error: %v", err)
+	}
+	if keys.Active().ID != newKey.ID {
+		t.Errorf("Active() = %q, want %q", keys.Active().ID, newKey.ID)
+	}
+
+	if _, err := p.ValidateToken(ctx, oldToken); err != nil {
+		t.Errorf("ValidateToken() of token signed before rotation unexpected error: %v", err)
+	}
+
+	if err := keys.Remove(newKey.ID); err == nil {
+		t.Error("Remove() of active key expected error but got nil")
+	}
+	if err := keys.Remove(oldKey.ID); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+	if _, err := p.ValidateToken(ctx, oldToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("ValidateToken() after removing key error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestProvider_AlgorithmMustMatchKey(t *testing.T) {
+	ctx := context.Background()
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey() failed: %v", err)
+	}
+
+	// A verifier that only knows key "shared" as EdDSA must not accept an
+	// HS256 token that claims the same kid.
+	signer := NewProvider(newKeySet(t, newHS256Key(t, "shared", testSecret)), nil, Config{})
+	edShared, err := EdDSAKey("shared", edKey)
+	if err != nil {
+		t.Fatalf("EdDSAKey() failed: %v", err)
+	}
+	verifier := NewProvider(newKeySet(t, edShared), nil, Config{})
+
+	token, err := signer.GenerateToken(ctx, testUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() unexpected error: %v", err)
+	}
+	if _, err := verifier.ValidateToken(ctx, token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("ValidateToken() error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestProvider_Revocation(t *testing.T) {
+	ctx := context.Background()
+
+	st := storetest.SQLite(t, RevocationMigrations(store.DialectSQLite, ""))
+
+	key := newHS256Key(t, "k1", testSecret)
+	p := NewProvider(newKeySet(t, key), NewStoreRevocationList(st, ""), DefaultConfig())
+
+	token, err := p.GenerateToken(ctx, testUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() unexpected error: %v", err)
+	}
+	other, err := p.GenerateToken(ctx, testUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() unexpected error: %v", err)
+	}
+
+	if err := p.RevokeToken(ctx, token); err != nil {
+		t.Fatalf("RevokeToken() unexpected error: %v", err)
+	}
+	if err := p.RevokeToken(ctx, token); err != nil {
+		t.Errorf("second RevokeToken() unexpected error: %v", err)
+	}
+
+	// A duplicate revoke must not abort the caller's transaction.
+	err = st.WithTx(ctx, func(ctx context.Context) error {
+		if err := p.RevokeToken(ctx, token); err != nil {
+			return err
+		}
+		_, err := p.ValidateToken(ctx, token)
+		if !errors.Is(err, ErrTokenRevoked) {
+			t.Errorf("ValidateToken() in transaction error = %v, want ErrTokenRevoked", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("WithTx() unexpected error: %v", err)
+	}
+
+	if _, err := p.ValidateToken(ctx, token); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("ValidateToken() revoked error = %v, want ErrTokenRevoked", err)
+	}
+	if _, err := p.ValidateToken(ctx, other); err != nil {
+		t.Errorf("ValidateToken() of unrelated token unexpected error: %v", err)
+	}
+
+	noList := NewProvider(newKeySet(t, key), nil, DefaultConfig())
+	if err := noList.RevokeToken(ctx, token); !errors.Is(err, ErrRevocationUnsupported) {
+		t.Errorf("RevokeToken() without list error = %v, want ErrRevocationUnsupported", err)
+	}
+}
+
+func TestProvider_RevocationOutlivesLeeway(t *testing.T) {
+	ctx := context.Background()
+	st := storetest.SQLite(t, RevocationMigrations(store.DialectSQLite, ""))
+	revocations := NewStoreRevocationList(st, "")
+	keys := newKeySet(t, newHS256Key(t, "k1", testSecret))
+	config := Config{TTL: time.Minute, Leeway: time.Minute}
+
+	// The token expired 10 seconds ago but is still within the leeway.
+	issuer := NewProvider(keys, revocations, config)
+	issuer.now = func() time.Time { return time.Now().Add(-70 * time.Second) }
+	token, err := issuer.GenerateToken(ctx, testUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() unexpected error: %v", err)
+	}
+
+	p := NewProvider(keys, revocations, config)
+	if err := p.RevokeToken(ctx, token); err != nil {
+		t.Fatalf("RevokeToken() unexpected error: %v", err)
+	}
+	if n, err := revocations.Purge(ctx); err != nil || n != 0 {
+		t.Fatalf("Purge() = %d, %v; want the record kept through the leeway", n, err)
+	}
+	if _, err := p.ValidateToken(ctx, token); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("ValidateToken() after Purge() error = %v, want ErrTokenRevoked", err)
+	}
+}
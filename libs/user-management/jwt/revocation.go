@@ -0,0 +1,98 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/JWindy92/obelisk-platform/libs/store"
+	"github.com/JWindy92/obelisk-platform/libs/store/migrate"
+)
+
+// RevocationList records revoked token IDs.
+type RevocationList interface {
+	// Revoke marks a token ID as revoked. expiresAt is when the token stops
+	// validating, its own expiry plus any leeway, after which the record is
+	// no longer needed.
+	Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error
+
+	// IsRevoked reports whether a token ID has been revoked.
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+}
+
+// StoreRevocationList is a RevocationList persisted through store.Store, so
+// revocations are shared by every instance using the same database.
+type StoreRevocationList struct {
+	store     store.Store
+	tableName string
+}
+
+// NewStoreRevocationList creates a revocation list backed by a table.
+// The table name defaults to "revoked_tokens" if empty; create it with the
+// set returned by RevocationMigrations.
+func NewStoreRevocationList(st store.Store, tableName string) *StoreRevocationList {
+	if tableName == "" {
+		tableName = "revoked_tokens"
+	}
+
+	return &StoreRevocationList{
+		store:     st,
+		tableName: tableName,
+	}
+}
+
+// Revoke marks a token ID as revoked. Revoking the same ID twice is a no-op.
+func (r *StoreRevocationList) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	query := r.store.Dialect().Rebind(fmt.Sprintf(
+		"INSERT INTO %s (token_id, expires_at) VALUES (?, ?) ON CONFLICT (token_id) DO NOTHING", r.tableName,
+	))
+
+	// ON CONFLICT rather than ignoring the unique violation, which would
+	// abort a surrounding PostgreSQL transaction.
+	if _, err := store.QuerierFrom(ctx, r.store).ExecContext(ctx, query, tokenID, expiresAt.UTC()); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+// IsRevoked reports whether a token ID has been revoked.
+func (r *StoreRevocationList) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	query := r.store.Dialect().Rebind(fmt.Sprintf(
+		"SELECT COUNT(*) FROM %s WHERE token_id = ?", r.tableName,
+	))
+
+	var count int
+	if err := store.QuerierFrom(ctx, r.store).QueryRowContext(ctx, query, tokenID).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check revoked token: %w", err)
+	}
+	return count > 0, nil
+}
+
+// Purge deletes records for tokens past the expiresAt they were revoked
+// with, since they can no longer validate anyway. It returns the number of records removed.
+func (r *StoreRevocationList) Purge(ctx context.Context) (int64, error) {
+	query := r.store.Dialect().Rebind(fmt.Sprintf(
+		"DELETE FROM %s WHERE expires_at < ?", r.tableName,
+	))
+
+	result, err := store.QuerierFrom(ctx, r.store).ExecContext(ctx, query, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge revoked tokens: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// RevocationMigrations returns the migration set that creates the table used
+// by StoreRevocationList. tableName defaults to "revoked_tokens" if empty.
+func RevocationMigrations(dialect store.Dialect, tableName string) migrate.Set {
+	if tableName == "" {
+		tableName = "revoked_tokens"
+	}
+
+	return migrate.CreateTable("jwt", tableName, fmt.Sprintf(`
+		CREATE TABLE %s (
+			token_id TEXT PRIMARY KEY,
+			expires_at %s NOT NULL
+		)
+	`, tableName, dialect.TimestampType()))
+}
@@ -0,0 +1,35 @@
+package usermgmt
+
+import (
+	"fmt"
+
+	"github.com/JWindy92/obelisk-platform/libs/store"
+	"github.com/JWindy92/obelisk-platform/libs/store/migrate"
+)
+
+// Migrations returns the migration set that creates and evolves the users
+// table named by config.TableName. Register it with a migrate.Migrator
+// before running Up:
+//
+//	m := migrate.New(st, migrate.Config{})
+//	m.Register(usermgmt.Migrations(st.Dialect(), config))
+//	err := m.Up(ctx)
+//
+// The set is named after the table, so several user tables can be managed
+// by the same Migrator.
+func Migrations(dialect store.Dialect, config Config) migrate.Set {
+	tableName := config.TableName
+	if tableName == "" {
+		tableName = "users"
+	}
+
+	return migrate.CreateTable("usermgmt", tableName, fmt.Sprintf(`
+		CREATE TABLE %[1]s (
+			id TEXT PRIMARY KEY,
+			email TEXT NOT NULL UNIQUE,
+			password_hash TEXT NOT NULL,
+			created_at %[2]s NOT NULL,
+			updated_at %[2]s NOT NULL
+		)
+	`, tableName, dialect.TimestampType()))
+}
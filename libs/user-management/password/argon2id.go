@@ -0,0 +1,161 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2idParams are the tunable cost parameters for argon2id.
+type Argon2idParams struct {
+	// Memory is the memory cost in KiB.
+	Memory uint32
+
+	// Iterations is the number of passes over the memory.
+	Iterations uint32
+
+	// Parallelism is the number of lanes.
+	Parallelism uint8
+
+	// SaltLength is the length of the random salt in bytes.
+	SaltLength uint32
+
+	// KeyLength is the length of the derived key in bytes.
+	KeyLength uint32
+}
+
+// Upper bounds on the cost parameters. Compare takes its parameters from the
+// stored hash, so without a cap a tampered hash could make a single login
+// allocate arbitrary memory or run for minutes. The memory cap is four times
+// the default, well above what a login should cost.
+const (
+	maxArgon2idMemory     = 256 * 1024 // 256 MiB in KiB
+	maxArgon2idIterations = 64
+)
+
+// DefaultArgon2idParams returns the second recommended option of RFC 9106
+// (64 MiB, 3 iterations, 4 lanes) with a 16-byte salt and 32-byte key.
+func DefaultArgon2idParams() Argon2idParams {
+	return Argon2idParams{
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 4,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+// Argon2id hashes passwords with argon2id and encodes them as PHC strings:
+//
+//	$argon2id$v=19$m=65536,t=3,p=4$<base64 salt>$<base64 key>
+type Argon2id struct {
+	params Argon2idParams
+}
+
+// NewArgon2id creates an argon2id hasher with the given parameters.
+func NewArgon2id(params Argon2idParams) (*Argon2id, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	return &Argon2id{params: params}, nil
+}
+
+// validate checks the parameters are within the range argon2.IDKey accepts
+// and the caps above.
+func (p Argon2idParams) validate() error {
+	if p.Iterations < 1 || p.Parallelism < 1 || p.Memory < 8*uint32(p.Parallelism) {
+		return fmt.Errorf("invalid argon2id parameters: %+v", p)
+	}
+	if p.Memory > maxArgon2idMemory || p.Iterations > maxArgon2idIterations {
+		return fmt.Errorf("argon2id memory must be at most %d KiB and iterations at most %d", maxArgon2idMemory, maxArgon2idIterations)
+	}
+	if p.SaltLength < 8 || p.KeyLength < 16 {
+		return fmt.Errorf("argon2id salt must be at least 8 bytes and key at least 16 bytes")
+	}
+	return nil
+}
+
+// Hash hashes the password with a fresh random salt.
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	p := a.params
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return encodeArgon2id(p, salt, key), nil
+}
+
+// Compare checks the password against an argon2id PHC string, using the
+// parameters recorded in the hash rather than the hasher's current ones.
+func (a *Argon2id) Compare(password, hash string) error {
+	p, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return err
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	if subtle.ConstantTimeCompare(key, candidate) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+// NeedsRehash reports whether hash is not an argon2id hash produced with
+// this hasher's parameters.
+func (a *Argon2id) NeedsRehash(hash string) bool {
+	p, _, _, err := decodeArgon2id(hash)
+	return err != nil || p != a.params
+}
+
+// Recognizes reports whether hash is an argon2id PHC string.
+func (a *Argon2id) Recognizes(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+func encodeArgon2id(p Argon2idParams, salt, key []byte) string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+func decodeArgon2id(hash string) (Argon2idParams, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Argon2idParams{}, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: unsupported version %q", ErrInvalidHash, parts[2])
+	}
+
+	var p Argon2idParams
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+	if err := p.validate(); err != nil {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+	return p, salt, key, nil
+}
@@ -0,0 +1,95 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultBcryptCost is the cost used when NewBcrypt is given zero.
+const DefaultBcryptCost = 12
+
+// MaxBcryptPasswordLength is the longest password, in bytes, bcrypt hashes.
+const MaxBcryptPasswordLength = 72
+
+// maxBcryptCost caps the cost of hashes Compare accepts, and of new hashers.
+// Compare takes the cost from the stored hash, and each step doubles the
+// work, so a tampered hash with cost 31 would tie up a login for days. 16
+// is sixteen times the default's work.
+const maxBcryptCost = 16
+
+// Bcrypt hashes passwords with bcrypt.
+//
+// Hashes use bcrypt's standard "$2a$<cost>$<salt+hash>" string, the
+// predecessor of the PHC string format, which every bcrypt verifier accepts.
+// Passwords longer than MaxBcryptPasswordLength bytes are rejected by Hash;
+// Bcrypt implements usermgmt.PasswordLimiter so Service validates them first.
+type Bcrypt struct {
+	cost int
+}
+
+// NewBcrypt creates a bcrypt hasher with the given cost (4-16).
+// A cost of zero selects DefaultBcryptCost.
+func NewBcrypt(cost int) (*Bcrypt, error) {
+	if cost == 0 {
+		cost = DefaultBcryptCost
+	}
+	if cost < bcrypt.MinCost || cost > maxBcryptCost {
+		return nil, fmt.Errorf("bcrypt cost must be between %d and %d, got %d", bcrypt.MinCost, maxBcryptCost, cost)
+	}
+	return &Bcrypt{cost: cost}, nil
+}
+
+// Hash hashes the password.
+func (b *Bcrypt) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.cost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// Compare checks the password against a bcrypt hash. Hashes with a cost
+// above 16 are rejected as invalid without being computed.
+func (b *Bcrypt) Compare(password, hash string) error {
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+	if cost > maxBcryptCost {
+		return fmt.Errorf("%w: bcrypt cost %d exceeds %d", ErrInvalidHash, cost, maxBcryptCost)
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return ErrMismatch
+	default:
+		return fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+}
+
+// MaxLength returns MaxBcryptPasswordLength.
+func (b *Bcrypt) MaxLength() int {
+	return MaxBcryptPasswordLength
+}
+
+// NeedsRehash reports whether hash is not a bcrypt hash with this hasher's cost.
+func (b *Bcrypt) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != b.cost
+}
+
+// Recognizes reports whether hash is a bcrypt hash.
+func (b *Bcrypt) Recognizes(hash string) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		if strings.HasPrefix(hash, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+// Package password provides production usermgmt.PasswordHasher
+// implementations based on bcrypt and argon2id.
+//
+// Every hasher also implements usermgmt.Rehasher, so Service.Login upgrades
+// stored hashes when cost parameters are raised. To switch algorithms, wrap
+// the new hasher and the old ones in an Upgrader.
+package password
+
+import (
+	"errors"
+
+	usermgmt "github.com/JWindy92/obelisk-platform/libs/user-management"
+)
+
+var (
+	// ErrMismatch is returned by Compare when the password does not match.
+	ErrMismatch = errors.New("password does not match")
+
+	// ErrInvalidHash is returned when a stored hash cannot be parsed.
+	ErrInvalidHash = errors.New("invalid password hash")
+
+	// ErrUnknownAlgorithm is returned by Upgrader when no hasher recognizes a hash.
+	ErrUnknownAlgorithm = errors.New("unknown password hash algorithm")
+)
+
+// Hasher is a PasswordHasher that can recognize its own hashes and tell
+// when they were produced with outdated parameters.
+type Hasher interface {
+	usermgmt.PasswordHasher
+	usermgmt.Rehasher
+
+	// Recognizes reports whether hash was produced by this algorithm.
+	Recognizes(hash string) bool
+}
+
+// Upgrader hashes new passwords with a preferred Hasher while still
+// verifying hashes produced by legacy ones. Its NeedsRehash reports true for
+// any hash the preferred hasher did not produce with its current parameters,
+// so users migrate to the preferred algorithm as they log in.
+type Upgrader struct {
+	preferred Hasher
+	legacy    []Hasher
+}
+
+var (
+	_ Hasher = (*Upgrader)(nil)
+	_ Hasher = (*Bcrypt)(nil)
+	_ Hasher = (*Argon2id)(nil)
+
+	_ usermgmt.PasswordLimiter = (*Bcrypt)(nil)
+	_ usermgmt.PasswordLimiter = (*Upgrader)(nil)
+)
+
+// NewUpgrader creates an Upgrader that hashes with preferred and also
+// verifies hashes recognized by any of the legacy hashers.
+func NewUpgrader(preferred Hasher, legacy ...Hasher) *Upgrader {
+	return &Upgrader{
+		preferred: preferred,
+		legacy:    legacy,
+	}
+}
+
+// Hash hashes the password with the preferred hasher.
+func (u *Upgrader) Hash(password string) (string, error) {
+	return u.preferred.Hash(password)
+}
+
+// Compare verifies the password with whichever hasher recognizes the hash.
+func (u *Upgrader) Compare(password, hash string) error {
+	h, ok := u.hasherFor(hash)
+	if !ok {
+		return ErrUnknownAlgorithm
+	}
+	return h.Compare(password, hash)
+}
+
+// NeedsRehash reports whether hash was not produced by the preferred hasher
+// with its current parameters.
+func (u *Upgrader) NeedsRehash(hash string) bool {
+	return !u.preferred.Recognizes(hash) || u.preferred.NeedsRehash(hash)
+}
+
+// MaxLength returns the preferred hasher's password length limit, or zero
+// if it has none.
+func (u *Upgrader) MaxLength() int {
+	if limiter, ok := u.preferred.(usermgmt.PasswordLimiter); ok {
+		return limiter.MaxLength()
+	}
+	return 0
+}
+
+// Recognizes reports whether any of the Upgrader's hashers recognizes hash.
+func (u *Upgrader) Recognizes(hash string) bool {
+	_, ok := u.hasherFor(hash)
+	return ok
+}
+
+func (u *Upgrader) hasherFor(hash string) (Hasher, bool) {
+	if u.preferred.Recognizes(hash) {
+		return u.preferred, true
+	}
+	for _, h := range u.legacy {
+		if h.Recognizes(hash) {
+			return h, true
+		}
+	}
+	return nil, false
+}
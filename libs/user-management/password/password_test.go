@@ -0,0 +1,217 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/JWindy92/obelisk-platform/libs/internal/storetest"
+	"github.com/JWindy92/obelisk-platform/libs/store"
+	usermgmt "github.com/JWindy92/obelisk-platform/libs/user-management"
+)
+
+// fastArgon2id keeps tests quick; production code should use the defaults.
+var fastArgon2id = Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func mustBcrypt(t *testing.T, cost int) *Bcrypt {
+	t.Helper()
+	b, err := NewBcrypt(cost)
+	if err != nil {
+		t.Fatalf("NewBcrypt() failed: %v", err)
+	}
+	return b
+}
+
+func mustArgon2id(t *testing.T, params Argon2idParams) *Argon2id {
+	t.Helper()
+	a, err := NewArgon2id(params)
+	if err != nil {
+		t.Fatalf("NewArgon2id() failed: %v", err)
+	}
+	return a
+}
+
+func TestHashers(t *testing.T) {
+	tests := []struct {
+		name    string
+		hasher  Hasher
+		prefix  string
+		invalid []string
+	}{
+		{
+			name:   "bcrypt",
+			hasher: mustBcrypt(t, 4),
+			prefix: "$2a$04$",
+			invalid: []string{
+				"garbage",
+				"$2a$31$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy",
+			},
+		},
+		{
+			name:   "argon2id",
+			hasher: mustArgon2id(t, fastArgon2id),
+			prefix: "$argon2id$v=19$m=64,t=1,p=1$",
+			invalid: []string{
+				"garbage",
+				"$argon2id$v=19$m=64,t=0,p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5a2V5aw",
+				"$argon2id$v=19$m=64,t=1,p=0$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5a2V5aw",
+				"$argon2id$v=19$m=4,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5a2V5aw",
+				"$argon2id$v=19$m=4294967295,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5a2V5aw",
+				"$argon2id$v=19$m=4194304,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5a2V5aw",
+				"$argon2id$v=19$m=64,t=4294967295,p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5a2V5aw",
+				"$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$",
+				"$argon2id$v=19$m=64,t=1,p=1$$a2V5a2V5a2V5a2V5a2V5aw",
+				"$argon2id$v=19$m=64,t=1,p=1$c2FsdA$a2V5a2V5a2V5a2V5a2V5aw",
+				"$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := tt.hasher.Hash("correct horse")
+			if err != nil {
+				t.Fatalf("Hash() unexpected error: %v", err)
+			}
+			if !strings.HasPrefix(hash, tt.prefix) {
+				t.Errorf("Hash() = %q, want prefix %q", hash, tt.prefix)
+			}
+			if !tt.hasher.Recognizes(hash) {
+				t.Error("Recognizes() = false for own hash")
+			}
+
+			again, err := tt.hasher.Hash("correct horse")
+			if err != nil {
+				t.Fatalf("Hash() unexpected error: %v", err)
+			}
+			if again == hash {
+				t.Error("Hash() produced identical hashes; salt is not random")
+			}
+
+			if err := tt.hasher.Compare("correct horse", hash); err != nil {
+				t.Errorf("Compare() unexpected error: %v", err)
+			}
+			if err := tt.hasher.Compare("wrong horse", hash); !errors.Is(err, ErrMismatch) {
+				t.Errorf("Compare() wrong password error = %v, want ErrMismatch", err)
+			}
+			for _, invalid := range tt.invalid {
+				if err := tt.hasher.Compare("correct horse", invalid); !errors.Is(err, ErrInvalidHash) {
+					t.Errorf("Compare(%q) error = %v, want ErrInvalidHash", invalid, err)
+				}
+			}
+			if tt.hasher.NeedsRehash(hash) {
+				t.Error("NeedsRehash() = true for hash with current parameters")
+			}
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	oldBcrypt := mustBcrypt(t, 4)
+	newBcrypt := mustBcrypt(t, 5)
+	hash, err := oldBcrypt.Hash("secret")
+	if err != nil {
+		t.Fatalf("Hash() unexpected error: %v", err)
+	}
+	if !newBcrypt.NeedsRehash(hash) {
+		t.Error("bcrypt NeedsRehash() = false after raising cost")
+	}
+
+	oldArgon := mustArgon2id(t, fastArgon2id)
+	stronger := fastArgon2id
+	stronger.Iterations = 2
+	newArgon := mustArgon2id(t, stronger)
+	hash, err = oldArgon.Hash("secret")
+	if err != nil {
+		t.Fatalf("Hash() unexpected error: %v", err)
+	}
+	if !newArgon.NeedsRehash(hash) {
+		t.Error("argon2id NeedsRehash() = false after raising iterations")
+	}
+	if err := newArgon.Compare("secret", hash); err != nil {
+		t.Errorf("Compare() with old parameters unexpected error: %v", err)
+	}
+}
+
+func TestUpgrader(t *testing.T) {
+	legacy := mustBcrypt(t, 4)
+	preferred := mustArgon2id(t, fastArgon2id)
+	u := NewUpgrader(preferred, legacy)
+
+	legacyHash, err := legacy.Hash("secret")
+	if err != nil {
+		t.Fatalf("Hash() unexpected error: %v", err)
+	}
+	if err := u.Compare("secret", legacyHash); err != nil {
+		t.Errorf("Compare() legacy hash unexpected error: %v", err)
+	}
+	if !u.NeedsRehash(legacyHash) {
+		t.Error("NeedsRehash() = false for legacy algorithm")
+	}
+
+	newHash, err := u.Hash("secret")
+	if err != nil {
+		t.Fatalf("Hash() unexpected error: %v", err)
+	}
+	if !preferred.Recognizes(newHash) {
+		t.Errorf("Hash() = %q, want preferred algorithm", newHash)
+	}
+	if u.NeedsRehash(newHash) {
+		t.Error("NeedsRehash() = true for preferred hash")
+	}
+
+	if err := u.Compare("secret", "$unknown$hash"); !errors.Is(err, ErrUnknownAlgorithm) {
+		t.Errorf("Compare() unknown hash error = %v, want ErrUnknownAlgorithm", err)
+	}
+
+	if got := u.MaxLength(); got != 0 {
+		t.Errorf("MaxLength() = %d, want no limit from argon2id", got)
+	}
+	if got := NewUpgrader(legacy, preferred).MaxLength(); got != MaxBcryptPasswordLength {
+		t.Errorf("MaxLength() = %d, want bcrypt limit %d", got, MaxBcryptPasswordLength)
+	}
+}
+
+func TestNewHasherValidation(t *testing.T) {
+	if _, err := NewBcrypt(3); err == nil {
+		t.Error("NewBcrypt(3) expected error but got nil")
+	}
+	if _, err := NewBcrypt(maxBcryptCost + 1); err == nil {
+		t.Errorf("NewBcrypt(%d) expected error but got nil", maxBcryptCost+1)
+	}
+	if b, err := NewBcrypt(0); err != nil || b.cost != DefaultBcryptCost {
+		t.Errorf("NewBcrypt(0) = %+v, %v; want default cost", b, err)
+	}
+	if _, err := NewArgon2id(Argon2idParams{}); err == nil {
+		t.Error("NewArgon2id() with zero params expected error but got nil")
+	}
+	tooMuchMemory := DefaultArgon2idParams()
+	tooMuchMemory.Memory = 512 * 1024
+	if _, err := NewArgon2id(tooMuchMemory); err == nil {
+		t.Error("NewArgon2id() with 512 MiB memory expected error but got nil")
+	}
+}
+
+func TestBcrypt_SignupPasswordTooLong(t *testing.T) {
+	config := usermgmt.DefaultConfig()
+	st := storetest.SQLite(t, usermgmt.Migrations(store.DialectSQLite, config))
+	svc := usermgmt.NewService(usermgmt.NewRepository(st, config), nil, mustBcrypt(t, 4), config)
+	ctx := context.Background()
+
+	_, err := svc.Signup(ctx, usermgmt.CreateUserRequest{
+		Email:    "long@example.com",
+		Password: strings.Repeat("a", MaxBcryptPasswordLength+1),
+	})
+	var verr *usermgmt.ValidationError
+	if !errors.As(err, &verr) || verr.Message("password") == "" {
+		t.Fatalf("Signup() error = %v, want *ValidationError on password", err)
+	}
+
+	if _, err := svc.Signup(ctx, usermgmt.CreateUserRequest{
+		Email:    "max@example.com",
+		Password: strings.Repeat("a", MaxBcryptPasswordLength),
+	}); err != nil {
+		t.Errorf("Signup() at the limit unexpected error: %v", err)
+	}
+}
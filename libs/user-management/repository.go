@@ -2,6 +2,11 @@ package usermgmt
 
 import (
 	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/JWindy92/obelisk-platform/libs/store"
 )
@@ -51,38 +56,201 @@ func NewRepository(st store.Store, config Config) *repository {
 	}
 }
 
-// Create inserts a new user into the database
+// userColumns lists the columns selected for a User, in scan order.
+const userColumns = "id, email, password_hash, created_at, updated_at"
+
+// Create inserts a new user into the database.
+// An ID is generated if the user does not have one yet, and CreatedAt and
+// UpdatedAt are set when zero. Returns ErrEmailTaken if the email is in use.
 func (r *repository) Create(ctx context.Context, user *User) error {
-	// TODO: Implement user creation with SQL INSERT
+	if user.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		user.ID = id
+	}
+
+	now := time.Now().UTC()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = user.CreatedAt
+	}
+
+	query := r.rebind(fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (?, ?, ?, ?, ?)",
+		r.tableName, userColumns,
+	))
+
+	_, err := r.querier(ctx).ExecContext(ctx, query,
+		user.ID, user.Email, user.PasswordHash, user.CreatedAt.UTC(), user.UpdatedAt.UTC(),
+	)
+	if err != nil {
+		if r.store.IsUniqueViolationOn(err, r.tableName, "email") {
+			return ErrEmailTaken
+		}
+		return fmt.Errorf("failed to create user: %w", err)
+	}
 	return nil
 }
 
-// GetByID retrieves a user by their unique identifier
+// GetByID retrieves a user by their unique identifier.
+// Returns ErrUserNotFound if no user has the given ID.
 func (r *repository) GetByID(ctx context.Context, id string) (*User, error) {
-	// TODO: Implement user retrieval with SQL SELECT WHERE id = ?
-	return nil, nil
+	query := r.rebind(fmt.Sprintf(
+		"SELECT %s FROM %s WHERE id = ?",
+		userColumns, r.tableName,
+	))
+	return r.getOne(ctx, query, id)
 }
 
-// GetByEmail retrieves a user by their email address
+// GetByEmail retrieves a user by their email address.
+// Returns ErrUserNotFound if no user has the given email.
 func (r *repository) GetByEmail(ctx context.Context, email string) (*User, error) {
-	// TODO: Implement user retrieval with SQL SELECT WHERE email = ?
-	return nil, nil
+	query := r.rebind(fmt.Sprintf(
+		"SELECT %s FROM %s WHERE email = ?",
+		userColumns, r.tableName,
+	))
+	return r.getOne(ctx, query, email)
 }
 
-// Update modifies an existing user's data
+// Update modifies an existing user's data.
+// UpdatedAt is refreshed to the current time. Returns ErrUserNotFound if the
+// user does not exist and ErrEmailTaken if the new email is in use.
 func (r *repository) Update(ctx context.Context, user *User) error {
-	// TODO: Implement user update with SQL UPDATE
-	return nil
+	user.UpdatedAt = time.Now().UTC()
+
+	query := r.rebind(fmt.Sprintf(
+		"UPDATE %s SET email = ?, password_hash = ?, updated_at = ? WHERE id = ?",
+		r.tableName,
+	))
+
+	result, err := r.querier(ctx).ExecContext(ctx, query,
+		user.Email, user.PasswordHash, user.UpdatedAt, user.ID,
+	)
+	if err != nil {
+		if r.store.IsUniqueViolationOn(err, r.tableName, "email") {
+			return ErrEmailTaken
+		}
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	return requireAffected(result)
 }
 
-// Delete removes a user from the database
+// Delete removes a user from the database.
+// Returns ErrUserNotFound if the user does not exist.
 func (r *repository) Delete(ctx context.Context, id string) error {
-	// TODO: Implement user deletion with SQL DELETE
-	return nil
+	query := r.rebind(fmt.Sprintf("DELETE FROM %s WHERE id = ?", r.tableName))
+
+	result, err := r.querier(ctx).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	return requireAffected(result)
 }
 
-// List retrieves all users with optional pagination
+// List retrieves users ordered by creation time.
+// A limit of zero or less returns all users after the offset.
 func (r *repository) List(ctx context.Context, limit, offset int) ([]*User, error) {
-	// TODO: Implement user listing with SQL SELECT with LIMIT and OFFSET
-	return nil, nil
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s ORDER BY created_at, id",
+		userColumns, r.tableName,
+	)
+
+	var args []any
+	switch {
+	case limit > 0:
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, max(offset, 0))
+	case offset > 0 && r.store.Dialect() == store.DialectSQLite:
+		// SQLite only accepts OFFSET as part of a LIMIT clause.
+		query += " LIMIT -1 OFFSET ?"
+		args = append(args, offset)
+	case offset > 0:
+		query += " OFFSET ?"
+		args = append(args, offset)
+	}
+
+	rows, err := r.querier(ctx).QueryContext(ctx, r.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	return users, nil
+}
+
+// getOne runs a query expected to return at most one user.
+func (r *repository) getOne(ctx context.Context, query string, args ...any) (*User, error) {
+	user, err := scanUser(r.querier(ctx).QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// querier returns the transaction carried by ctx, if any, so repository
+// calls made inside store.WithTx join it. Otherwise it returns the store's DB.
+func (r *repository) querier(ctx context.Context) store.Querier {
+	return store.QuerierFrom(ctx, r.store)
+}
+
+// rebind converts a query written with "?" placeholders to the store's dialect.
+func (r *repository) rebind(query string) string {
+	return r.store.Dialect().Rebind(query)
+}
+
+// scanner is satisfied by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanUser reads the columns listed in userColumns into a User.
+func scanUser(s scanner) (*User, error) {
+	var user User
+	if err := s.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		return nil, err
+	}
+	user.CreatedAt = user.CreatedAt.UTC()
+	user.UpdatedAt = user.UpdatedAt.UTC()
+	return &user, nil
+}
+
+// requireAffected maps an UPDATE or DELETE that touched no rows to ErrUserNotFound.
+func requireAffected(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read affected rows: %w", err)
+	}
+	if n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// newID generates a random RFC 4122 version 4 UUID.
+func newID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate user id: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
 }
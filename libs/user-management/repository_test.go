@@ -0,0 +1,202 @@
+package usermgmt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/JWindy92/obelisk-platform/libs/internal/storetest"
+	"github.com/JWindy92/obelisk-platform/libs/store"
+)
+
+// newTestStore returns a connected SQLite store with the users table migrated.
+func newTestStore(t *testing.T, tableName string) store.Store {
+	t.Helper()
+
+	return storetest.SQLite(t, Migrations(store.DialectSQLite, Config{TableName: tableName}))
+}
+
+func TestRepository_CreateAndGet(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(newTestStore(t, "users"), DefaultConfig())
+
+	user := &User{Email: "user@example.com", PasswordHash: "hash"}
+	if err := repo.Create(ctx, user); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if user.ID == "" {
+		t.Fatal("Create() did not assign an ID")
+	}
+	if user.CreatedAt.IsZero() || user.UpdatedAt.IsZero() {
+		t.Error("Create() did not set timestamps")
+	}
+
+	byID, err := repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID() unexpected error: %v", err)
+	}
+	if byID.Email != user.Email || byID.PasswordHash != user.PasswordHash {
+		t.Errorf("GetByID() = %+v, want %+v", byID, user)
+	}
+	if !byID.CreatedAt.Equal(user.CreatedAt) {
+		t.Errorf("GetByID() CreatedAt = %v, want %v", byID.CreatedAt, user.CreatedAt)
+	}
+
+	byEmail, err := repo.GetByEmail(ctx, user.Email)
+	if err != nil {
+		t.Fatalf("GetByEmail() unexpected error: %v", err)
+	}
+	if byEmail.ID != user.ID {
+		t.Errorf("GetByEmail() ID = %q, want %q", byEmail.ID, user.ID)
+	}
+}
+
+func TestRepository_NotFound(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(newTestStore(t, "users"), DefaultConfig())
+
+	if _, err := repo.GetByID(ctx, "missing"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("GetByID() error = %v, want ErrUserNotFound", err)
+	}
+	if _, err := repo.GetByEmail(ctx, "missing@example.com"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("GetByEmail() error = %v, want ErrUserNotFound", err)
+	}
+	if err := repo.Update(ctx, &User{ID: "missing", Email: "a@example.com"}); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Update() error = %v, want ErrUserNotFound", err)
+	}
+	if err := repo.Delete(ctx, "missing"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Delete() error = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestRepository_EmailTaken(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(newTestStore(t, "users"), DefaultConfig())
+
+	first := &User{Email: "first@example.com", PasswordHash: "hash"}
+	second := &User{Email: "second@example.com", PasswordHash: "hash"}
+	for _, u := range []*User{first, second} {
+		if err := repo.Create(ctx, u); err != nil {
+			t.Fatalf("Create() unexpected error: %v", err)
+		}
+	}
+
+	dup := &User{Email: "first@example.com", PasswordHash: "hash"}
+	if err := repo.Create(ctx, dup); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("Create() duplicate error = %v, want ErrEmailTaken", err)
+	}
+
+	second.Email = first.Email
+	if err := repo.Update(ctx, second); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("Update() duplicate error = %v, want ErrEmailTaken", err)
+	}
+}
+
+func TestRepository_DuplicateID(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(newTestStore(t, "users"), DefaultConfig())
+
+	first := &User{ID: "user-1", Email: "first@example.com", PasswordHash: "hash"}
+	if err := repo.Create(ctx, first); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	dup := &User{ID: "user-1", Email: "other@example.com", PasswordHash: "hash"}
+	err := repo.Create(ctx, dup)
+	if err == nil || errors.Is(err, ErrEmailTaken) {
+		t.Errorf("Create() duplicate ID error = %v, want a non-email error", err)
+	}
+}
+
+func TestRepository_UpdateAndDelete(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(newTestStore(t, "users"), DefaultConfig())
+
+	user := &User{Email: "old@example.com", PasswordHash: "hash"}
+	if err := repo.Create(ctx, user); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	user.Email = "new@example.com"
+	user.PasswordHash = "new-hash"
+	if err := repo.Update(ctx, user); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID() unexpected error: %v", err)
+	}
+	if got.Email != "new@example.com" || got.PasswordHash != "new-hash" {
+		t.Errorf("GetByID() after Update = %+v", got)
+	}
+
+	if err := repo.Delete(ctx, user.ID); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, user.ID); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("GetByID() after Delete error = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestRepository_List(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(newTestStore(t, "accounts"), Config{TableName: "accounts"})
+
+	for i := 0; i < 5; i++ {
+		user := &User{Email: fmt.Sprintf("user%d@example.com", i), PasswordHash: "hash"}
+		if err := repo.Create(ctx, user); err != nil {
+			t.Fatalf("Create() unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		limit  int
+		offset int
+		want   int
+	}{
+		{name: "all users", limit: 0, offset: 0, want: 5},
+		{name: "first page", limit: 2, offset: 0, want: 2},
+		{name: "last partial page", limit: 2, offset: 4, want: 1},
+		{name: "offset without limit", limit: 0, offset: 3, want: 2},
+		{name: "offset past end", limit: 2, offset: 10, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := repo.List(ctx, tt.limit, tt.offset)
+			if err != nil {
+				t.Fatalf("List() unexpected error: %v", err)
+			}
+			if len(users) != tt.want {
+				t.Errorf("List() returned %d users, want %d", len(users), tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_JoinsTransaction(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t, "users")
+	repo := NewRepository(st, DefaultConfig())
+
+	user := &User{Email: "tx@example.com", PasswordHash: "hash"}
+	err := st.WithTx(ctx, func(ctx context.Context) error {
+		if err := repo.Create(ctx, user); err != nil {
+			return err
+		}
+		if _, err := repo.GetByID(ctx, user.ID); err != nil {
+			t.Errorf("GetByID() inside transaction unexpected error: %v", err)
+		}
+		return errors.New("abort")
+	})
+	if err == nil {
+		t.Fatal("WithTx() expected error but got nil")
+	}
+
+	if _, err := repo.GetByID(ctx, user.ID); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("GetByID() after rollback error = %v, want ErrUserNotFound", err)
+	}
+}
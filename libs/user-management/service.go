@@ -1,6 +1,14 @@
 package usermgmt
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
 
 // Service defines the business logic interface for user management.
 // It orchestrates operations between the repository and auth provider.
@@ -33,6 +41,11 @@ type service struct {
 	authProvider   AuthProvider
 	passwordHasher PasswordHasher
 	config         Config
+
+	// dummyHash is compared against on logins for unknown emails, so they
+	// take as long as logins with a wrong password.
+	dummyHashOnce sync.Once
+	dummyHash     string
 }
 
 // NewService creates a new Service instance.
@@ -47,54 +60,214 @@ func NewService(repo Repository, authProvider AuthProvider, passwordHasher Passw
 	}
 }
 
-// Signup creates a new user account
+// Signup creates a new user account.
+// The email is normalized to lower case. Invalid input is reported as a
+// *ValidationError and an email that is already registered as ErrEmailTaken.
 func (s *service) Signup(ctx context.Context, req CreateUserRequest) (*User, error) {
-	// TODO: Validate email format
-	// TODO: Validate password meets minimum requirements
-	// TODO: Check if email already exists
-	// TODO: Hash password
-	// TODO: Create user with repository
-	return nil, nil
+	email := normalizeEmail(req.Email)
+
+	verr := &ValidationError{}
+	s.validateEmail(verr, email)
+	s.validatePassword(verr, req.Password)
+	if err := verr.errOrNil(); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureEmailAvailable(ctx, email, ""); err != nil {
+		return nil, err
+	}
+
+	hash, err := s.passwordHasher.Hash(req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user := &User{
+		Email:        email,
+		PasswordHash: hash,
+	}
+	if err := s.repo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
-// Login authenticates a user and returns a token
+// Login authenticates a user and returns a token.
+// Returns ErrInvalidCredentials if the email is unknown or the password is wrong.
+// If the password hasher implements Rehasher, outdated hashes are upgraded.
 func (s *service) Login(ctx context.Context, req LoginRequest) (*LoginResponse, error) {
-	// TODO: Get user by email
-	// TODO: Compare password hash
-	// TODO: Generate auth token
-	// TODO: Return user and token
-	return nil, nil
+	user, err := s.repo.GetByEmail(ctx, normalizeEmail(req.Email))
+	if errors.Is(err, ErrUserNotFound) {
+		// Hash anyway so response times don't reveal which emails exist.
+		s.passwordHasher.Compare(req.Password, s.dummyPasswordHash())
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.passwordHasher.Compare(req.Password, user.PasswordHash); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	s.upgradeHash(ctx, user, req.Password)
+
+	token, err := s.authProvider.GenerateToken(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return &LoginResponse{
+		User:  user,
+		Token: token,
+	}, nil
+}
+
+// dummyPasswordHash returns a hash of a fixed password made with the
+// configured hasher, computed on first use so it matches the cost of real
+// password hashes.
+func (s *service) dummyPasswordHash() string {
+	s.dummyHashOnce.Do(func() {
+		s.dummyHash, _ = s.passwordHasher.Hash("obelisk-dummy-password")
+	})
+	return s.dummyHash
 }
 
 // Logout invalidates a user's authentication token
 func (s *service) Logout(ctx context.Context, token string) error {
-	// TODO: Revoke token using auth provider
-	return nil
+	return s.authProvider.RevokeToken(ctx, token)
 }
 
 // GetUser retrieves a user by ID
 func (s *service) GetUser(ctx context.Context, id string) (*User, error) {
-	// TODO: Retrieve user from repository
-	return nil, nil
+	return s.repo.GetByID(ctx, id)
 }
 
-// UpdateUser modifies user information
+// UpdateUser modifies user information.
+// Only the fields set in req are changed. Invalid input is reported as a
+// *ValidationError and an email owned by another user as ErrEmailTaken.
 func (s *service) UpdateUser(ctx context.Context, id string, req UpdateUserRequest) (*User, error) {
-	// TODO: Get existing user
-	// TODO: Apply updates (email, password)
-	// TODO: Hash new password if provided
-	// TODO: Update user in repository
-	return nil, nil
+	var email string
+	verr := &ValidationError{}
+	if req.Email != nil {
+		email = normalizeEmail(*req.Email)
+		s.validateEmail(verr, email)
+	}
+	if req.Password != nil {
+		s.validatePassword(verr, *req.Password)
+	}
+	if err := verr.errOrNil(); err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Email != nil && email != user.Email {
+		if err := s.ensureEmailAvailable(ctx, email, user.ID); err != nil {
+			return nil, err
+		}
+		user.Email = email
+	}
+
+	if req.Password != nil {
+		hash, err := s.passwordHasher.Hash(*req.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash password: %w", err)
+		}
+		user.PasswordHash = hash
+	}
+
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 // DeleteUser removes a user account
 func (s *service) DeleteUser(ctx context.Context, id string) error {
-	// TODO: Delete user from repository
-	return nil
+	return s.repo.Delete(ctx, id)
 }
 
-// ValidateToken verifies an auth token and returns the user
+// ValidateToken verifies an auth token and returns the user.
+// The user is reloaded from the repository so that deleted accounts can no
+// longer authenticate and callers always see current data.
 func (s *service) ValidateToken(ctx context.Context, token string) (*User, error) {
-	// TODO: Validate token using auth provider
-	return nil, nil
+	claimed, err := s.authProvider.ValidateToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, claimed.ID)
+}
+
+// validateEmail records a field error if email is not a bare address.
+func (s *service) validateEmail(verr *ValidationError, email string) {
+	if email == "" {
+		verr.Add("email", "is required")
+		return
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		verr.Add("email", "is not a valid email address")
+	}
+}
+
+// validatePassword records a field error if password is shorter than the
+// configured minimum length or longer than the hasher accepts.
+func (s *service) validatePassword(verr *ValidationError, password string) {
+	minLength := s.config.PasswordMinLength
+	if minLength <= 0 {
+		minLength = DefaultConfig().PasswordMinLength
+	}
+	if utf8.RuneCountInString(password) < minLength {
+		verr.Add("password", fmt.Sprintf("must be at least %d characters", minLength))
+		return
+	}
+	if limiter, ok := s.passwordHasher.(PasswordLimiter); ok {
+		if maxLength := limiter.MaxLength(); maxLength > 0 && len(password) > maxLength {
+			verr.Add("password", fmt.Sprintf("must be at most %d bytes", maxLength))
+		}
+	}
+}
+
+// ensureEmailAvailable returns ErrEmailTaken if email belongs to a user
+// other than exceptID.
+func (s *service) ensureEmailAvailable(ctx context.Context, email, exceptID string) error {
+	existing, err := s.repo.GetByEmail(ctx, email)
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		return nil
+	case err != nil:
+		return err
+	case existing.ID != exceptID:
+		return ErrEmailTaken
+	}
+	return nil
+}
+
+// upgradeHash re-hashes the password if the hasher reports that the stored
+// hash is outdated. It is best-effort: on failure the old hash, which just
+// verified successfully, is kept and the login proceeds.
+func (s *service) upgradeHash(ctx context.Context, user *User, password string) {
+	rehasher, ok := s.passwordHasher.(Rehasher)
+	if !ok || !rehasher.NeedsRehash(user.PasswordHash) {
+		return
+	}
+
+	hash, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		return
+	}
+
+	previous := user.PasswordHash
+	user.PasswordHash = hash
+	if err := s.repo.Update(ctx, user); err != nil {
+		user.PasswordHash = previous
+	}
+}
+
+// normalizeEmail trims surrounding whitespace and lower-cases the address.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
@@ -0,0 +1,266 @@
+package usermgmt
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeAuthProvider issues tokens of the form "token-<user id>".
+type fakeAuthProvider struct {
+	revoked map[string]bool
+}
+
+func (f *fakeAuthProvider) GenerateToken(ctx context.Context, user *User) (string, error) {
+	return "token-" + user.ID, nil
+}
+
+func (f *fakeAuthProvider) ValidateToken(ctx context.Context, token string) (*User, error) {
+	if f.revoked[token] || !strings.HasPrefix(token, "token-") {
+		return nil, errors.New("invalid token")
+	}
+	return &User{ID: strings.TrimPrefix(token, "token-")}, nil
+}
+
+func (f *fakeAuthProvider) RevokeToken(ctx context.Context, token string) error {
+	if f.revoked == nil {
+		f.revoked = make(map[string]bool)
+	}
+	f.revoked[token] = true
+	return nil
+}
+
+// fakeHasher prefixes passwords instead of hashing them.
+type fakeHasher struct{}
+
+func (fakeHasher) Hash(password string) (string, error) {
+	return "hashed:" + password, nil
+}
+
+func (fakeHasher) Compare(password, hash string) error {
+	if "hashed:"+password != hash {
+		return errors.New("mismatch")
+	}
+	return nil
+}
+
+// countingHasher counts calls to Compare.
+type countingHasher struct {
+	fakeHasher
+	compares int
+}
+
+func (h *countingHasher) Compare(password, hash string) error {
+	h.compares++
+	return h.fakeHasher.Compare(password, hash)
+}
+
+func newTestService(t *testing.T) *service {
+	t.Helper()
+	repo := NewRepository(newTestStore(t, "users"), DefaultConfig())
+	return NewService(repo, &fakeAuthProvider{}, fakeHasher{}, DefaultConfig())
+}
+
+func TestService_SignupValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        CreateUserRequest
+		wantFields []string
+	}{
+		{
+			name:       "missing email and short password",
+			req:        CreateUserRequest{Email: "", Password: "short"},
+			wantFields: []string{"email", "password"},
+		},
+		{
+			name:       "malformed email",
+			req:        CreateUserRequest{Email: "not-an-email", Password: "longenough"},
+			wantFields: []string{"email"},
+		},
+		{
+			name:       "display name is not a bare address",
+			req:        CreateUserRequest{Email: "Bob <bob@example.com>", Password: "longenough"},
+			wantFields: []string{"email"},
+		},
+		{
+			name:       "password below minimum length",
+			req:        CreateUserRequest{Email: "user@example.com", Password: "1234567"},
+			wantFields: []string{"password"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestService(t)
+
+			_, err := svc.Signup(context.Background(), tt.req)
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Signup() error = %v, want *ValidationError", err)
+			}
+			if len(verr.Fields) != len(tt.wantFields) {
+				t.Fatalf("Signup() fields = %+v, want %v", verr.Fields, tt.wantFields)
+			}
+			for _, field := range tt.wantFields {
+				if verr.Message(field) == "" {
+					t.Errorf("Signup() missing error for field %q", field)
+				}
+			}
+		})
+	}
+}
+
+func TestService_SignupAndLogin(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	user, err := svc.Signup(ctx, CreateUserRequest{Email: " User@Example.com ", Password: "securepassword"})
+	if err != nil {
+		t.Fatalf("Signup() unexpected error: %v", err)
+	}
+	if user.Email != "user@example.com" {
+		t.Errorf("Signup() email = %q, want normalized address", user.Email)
+	}
+	if user.PasswordHash != "hashed:securepassword" {
+		t.Errorf("Signup() did not hash password, got %q", user.PasswordHash)
+	}
+
+	_, err = svc.Signup(ctx, CreateUserRequest{Email: "USER@example.com", Password: "securepassword"})
+	if !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("Signup() duplicate error = %v, want ErrEmailTaken", err)
+	}
+
+	resp, err := svc.Login(ctx, LoginRequest{Email: "user@example.com", Password: "securepassword"})
+	if err != nil {
+		t.Fatalf("Login() unexpected error: %v", err)
+	}
+	if resp.Token != "token-"+user.ID || resp.User.ID != user.ID {
+		t.Errorf("Login() = %+v, want token for user %q", resp, user.ID)
+	}
+
+	if _, err := svc.Login(ctx, LoginRequest{Email: "user@example.com", Password: "wrong"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Login() wrong password error = %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := svc.Login(ctx, LoginRequest{Email: "nobody@example.com", Password: "securepassword"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Login() unknown email error = %v, want ErrInvalidCredentials", err)
+	}
+
+	validated, err := svc.ValidateToken(ctx, resp.Token)
+	if err != nil {
+		t.Fatalf("ValidateToken() unexpected error: %v", err)
+	}
+	if validated.Email != user.Email {
+		t.Errorf("ValidateToken() email = %q, want %q", validated.Email, user.Email)
+	}
+
+	if err := svc.Logout(ctx, resp.Token); err != nil {
+		t.Fatalf("Logout() unexpected error: %v", err)
+	}
+	if _, err := svc.ValidateToken(ctx, resp.Token); err == nil {
+		t.Error("ValidateToken() after Logout expected error but got nil")
+	}
+}
+
+func TestService_LoginUnknownEmailComparesHash(t *testing.T) {
+	ctx := context.Background()
+	hasher := &countingHasher{}
+	repo := NewRepository(newTestStore(t, "users"), DefaultConfig())
+	svc := NewService(repo, &fakeAuthProvider{}, hasher, DefaultConfig())
+
+	for _, password := range []string{"securepassword", "obelisk-dummy-password"} {
+		if _, err := svc.Login(ctx, LoginRequest{Email: "nobody@example.com", Password: password}); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Login() unknown email error = %v, want ErrInvalidCredentials", err)
+		}
+	}
+	if hasher.compares != 2 {
+		t.Errorf("Login() for unknown emails ran Compare %d times, want 2", hasher.compares)
+	}
+}
+
+func TestService_UpdateUser(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	user, err := svc.Signup(ctx, CreateUserRequest{Email: "user@example.com", Password: "securepassword"})
+	if err != nil {
+		t.Fatalf("Signup() unexpected error: %v", err)
+	}
+	if _, err := svc.Signup(ctx, CreateUserRequest{Email: "other@example.com", Password: "securepassword"}); err != nil {
+		t.Fatalf("Signup() unexpected error: %v", err)
+	}
+
+	taken := "other@example.com"
+	if _, err := svc.UpdateUser(ctx, user.ID, UpdateUserRequest{Email: &taken}); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("UpdateUser() taken email error = %v, want ErrEmailTaken", err)
+	}
+
+	short := "short"
+	var verr *ValidationError
+	if _, err := svc.UpdateUser(ctx, user.ID, UpdateUserRequest{Password: &short}); !errors.As(err, &verr) {
+		t.Errorf("UpdateUser() short password error = %v, want *ValidationError", err)
+	}
+
+	email := "New@example.com"
+	password := "anotherpassword"
+	updated, err := svc.UpdateUser(ctx, user.ID, UpdateUserRequest{Email: &email, Password: &password})
+	if err != nil {
+		t.Fatalf("UpdateUser() unexpected error: %v", err)
+	}
+	if updated.Email != "new@example.com" || updated.PasswordHash != "hashed:anotherpassword" {
+		t.Errorf("UpdateUser() = %+v", updated)
+	}
+
+	if _, err := svc.UpdateUser(ctx, "missing", UpdateUserRequest{Email: &email}); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("UpdateUser() missing user error = %v, want ErrUserNotFound", err)
+	}
+
+	if err := svc.DeleteUser(ctx, user.ID); err != nil {
+		t.Fatalf("DeleteUser() unexpected error: %v", err)
+	}
+	if _, err := svc.GetUser(ctx, user.ID); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("GetUser() after delete error = %v, want ErrUserNotFound", err)
+	}
+}
+
+// upgradingHasher reports hashes without the "v2:" prefix as outdated.
+type upgradingHasher struct{}
+
+func (upgradingHasher) Hash(password string) (string, error) {
+	return "v2:" + password, nil
+}
+
+func (upgradingHasher) Compare(password, hash string) error {
+	if hash != "v1:"+password && hash != "v2:"+password {
+		return errors.New("mismatch")
+	}
+	return nil
+}
+
+func (upgradingHasher) NeedsRehash(hash string) bool {
+	return !strings.HasPrefix(hash, "v2:")
+}
+
+func TestService_LoginRehashesOutdatedHash(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(newTestStore(t, "users"), DefaultConfig())
+	svc := NewService(repo, &fakeAuthProvider{}, upgradingHasher{}, DefaultConfig())
+
+	user := &User{Email: "old@example.com", PasswordHash: "v1:securepassword"}
+	if err := repo.Create(ctx, user); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	if _, err := svc.Login(ctx, LoginRequest{Email: user.Email, Password: "securepassword"}); err != nil {
+		t.Fatalf("Login() unexpected error: %v", err)
+	}
+
+	stored, err := repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID() unexpected error: %v", err)
+	}
+	if stored.PasswordHash != "v2:securepassword" {
+		t.Errorf("stored hash = %q, want upgraded hash", stored.PasswordHash)
+	}
+}
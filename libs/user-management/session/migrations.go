@@ -0,0 +1,31 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/JWindy92/obelisk-platform/libs/store"
+	"github.com/JWindy92/obelisk-platform/libs/store/migrate"
+)
+
+// Migrations returns the migration set that creates the sessions table named
+// by config.TableName, for registration with a migrate.Migrator.
+func Migrations(dialect store.Dialect, config Config) migrate.Set {
+	tableName := config.TableName
+	if tableName == "" {
+		tableName = "sessions"
+	}
+
+	return migrate.CreateTable("session", tableName, fmt.Sprintf(`
+		CREATE TABLE %[1]s (
+			id_hash TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			created_at %[2]s NOT NULL,
+			last_seen_at %[2]s NOT NULL,
+			expires_at %[2]s NOT NULL,
+			user_agent TEXT NOT NULL DEFAULT '',
+			ip TEXT NOT NULL DEFAULT ''
+		);
+		CREATE INDEX %[1]s_user_id_idx ON %[1]s (user_id);
+		CREATE INDEX %[1]s_expires_at_idx ON %[1]s (expires_at);
+	`, tableName, dialect.TimestampType()))
+}
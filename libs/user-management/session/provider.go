@@ -0,0 +1,247 @@
+// Package session provides a usermgmt.AuthProvider that issues opaque
+// session tokens backed by a database table.
+//
+// Only a SHA-256 hash of each token is stored, so a leaked table cannot be
+// used to hijack sessions. Sessions can slide their expiry on use, be revoked
+// individually or all at once for a user, and are purged by a sweeper once
+// expired.
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/JWindy92/obelisk-platform/libs/store"
+	usermgmt "github.com/JWindy92/obelisk-platform/libs/user-management"
+)
+
+// ErrInvalidSession is returned when a token does not match a live session.
+var ErrInvalidSession = errors.New("invalid or expired session")
+
+// Config holds configuration options for the session provider.
+type Config struct {
+	// TableName specifies the database table name for sessions.
+	// Defaults to "sessions" if not specified.
+	TableName string
+
+	// TTL is how long a session stays valid after it was created or, with
+	// Sliding enabled, after it was last used.
+	// Defaults to 24 hours if not specified.
+	TTL time.Duration
+
+	// Sliding extends a session's expiry by TTL every time it is validated.
+	Sliding bool
+
+	// MaxLifetime caps how long a sliding session can live in total,
+	// regardless of activity. Zero means no cap.
+	MaxLifetime time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		TableName:   "sessions",
+		TTL:         24 * time.Hour,
+		Sliding:     true,
+		MaxLifetime: 30 * 24 * time.Hour,
+	}
+}
+
+// clientInfoKey is the context key for ClientInfo.
+type clientInfoKey struct{}
+
+// ClientInfo describes the client a session is created for.
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// WithClientInfo returns a context carrying the client's user agent and IP,
+// which GenerateToken records on the new session.
+func WithClientInfo(ctx context.Context, userAgent, ip string) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, ClientInfo{UserAgent: userAgent, IP: ip})
+}
+
+// Provider implements usermgmt.AuthProvider with server-side sessions.
+type Provider struct {
+	store     store.Store
+	tableName string
+	config    Config
+	now       func() time.Time
+}
+
+var _ usermgmt.AuthProvider = (*Provider)(nil)
+
+// NewProvider creates a session provider that persists sessions through st.
+// Create the table with the set returned by Migrations.
+func NewProvider(st store.Store, config Config) *Provider {
+	if config.TableName == "" {
+		config.TableName = "sessions"
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultConfig().TTL
+	}
+
+	return &Provider{
+		store:     st,
+		tableName: config.TableName,
+		config:    config,
+		now:       time.Now,
+	}
+}
+
+// GenerateToken creates a new session for the user and returns its token.
+// Client details attached with WithClientInfo are recorded on the session.
+func (p *Provider) GenerateToken(ctx context.Context, user *usermgmt.User) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	info, _ := ctx.Value(clientInfoKey{}).(ClientInfo)
+	now := p.now().UTC()
+
+	query := p.rebind(fmt.Sprintf(
+		"INSERT INTO %s (id_hash, user_id, created_at, last_seen_at, expires_at, user_agent, ip) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		p.tableName,
+	))
+	_, err = p.querier(ctx).ExecContext(ctx, query,
+		hashToken(token), user.ID, now, now, p.expiry(now, now), info.UserAgent, info.IP,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create session: %w", err)
+	}
+	return token, nil
+}
+
+// ValidateToken looks up the session and returns its user. Only the ID field
+// of the returned user is populated. With sliding expiration enabled, the
+// session's expiry is pushed forward.
+func (p *Provider) ValidateToken(ctx context.Context, token string) (*usermgmt.User, error) {
+	idHash := hashToken(token)
+	now := p.now().UTC()
+
+	query := p.rebind(fmt.Sprintf(
+		"SELECT user_id, created_at, expires_at FROM %s WHERE id_hash = ?", p.tableName,
+	))
+
+	var userID string
+	var createdAt, expiresAt time.Time
+	err := p.querier(ctx).QueryRowContext(ctx, query, idHash).Scan(&userID, &createdAt, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidSession
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+	if !now.Before(expiresAt) {
+		return nil, ErrInvalidSession
+	}
+
+	if p.config.Sliding {
+		touch := p.rebind(fmt.Sprintf(
+			"UPDATE %s SET last_seen_at = ?, expires_at = ? WHERE id_hash = ?", p.tableName,
+		))
+		if _, err := p.querier(ctx).ExecContext(ctx, touch, now, p.expiry(createdAt.UTC(), now), idHash); err != nil {
+			return nil, fmt.Errorf("failed to extend session: %w", err)
+		}
+	}
+
+	return &usermgmt.User{ID: userID}, nil
+}
+
+// RevokeToken deletes the session. Revoking an unknown token is not an error.
+func (p *Provider) RevokeToken(ctx context.Context, token string) error {
+	query := p.rebind(fmt.Sprintf("DELETE FROM %s WHERE id_hash = ?", p.tableName))
+	if _, err := p.querier(ctx).ExecContext(ctx, query, hashToken(token)); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// RevokeAll deletes every session belonging to the user, e.g. after a
+// password change. It returns the number of sessions removed.
+func (p *Provider) RevokeAll(ctx context.Context, userID string) (int64, error) {
+	query := p.rebind(fmt.Sprintf("DELETE FROM %s WHERE user_id = ?", p.tableName))
+	result, err := p.querier(ctx).ExecContext(ctx, query, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// Sweep deletes expired sessions and returns the number removed.
+func (p *Provider) Sweep(ctx context.Context) (int64, error) {
+	query := p.rebind(fmt.Sprintf("DELETE FROM %s WHERE expires_at <= ?", p.tableName))
+	result, err := p.querier(ctx).ExecContext(ctx, query, p.now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to sweep sessions: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// RunSweeper calls Sweep every interval until ctx is cancelled. A
+// non-positive interval defaults to the session TTL. Errors are passed to
+// onError, which may be nil. Run it in its own goroutine:
+//
+//	go sessions.RunSweeper(ctx, time.Hour, func(err error) { log.Print(err) })
+func (p *Provider) RunSweeper(ctx context.Context, interval time.Duration, onError func(error)) {
+	if interval <= 0 {
+		interval = p.config.TTL
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := p.Sweep(ctx); err != nil && onError != nil && ctx.Err() == nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// expiry computes a session's expiry when last used at lastSeen, honoring
+// MaxLifetime for sliding sessions.
+func (p *Provider) expiry(createdAt, lastSeen time.Time) time.Time {
+	expiresAt := lastSeen.Add(p.config.TTL)
+	if p.config.MaxLifetime > 0 {
+		if limit := createdAt.Add(p.config.MaxLifetime); expiresAt.After(limit) {
+			expiresAt = limit
+		}
+	}
+	return expiresAt
+}
+
+func (p *Provider) querier(ctx context.Context) store.Querier {
+	return store.QuerierFrom(ctx, p.store)
+}
+
+func (p *Provider) rebind(query string) string {
+	return p.store.Dialect().Rebind(query)
+}
+
+// newToken returns a random, URL-safe session token.
+func newToken() (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b[:]), nil
+}
+
+// hashToken returns the value stored in place of the raw token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,252 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/JWindy92/obelisk-platform/libs/internal/storetest"
+	"github.com/JWindy92/obelisk-platform/libs/store"
+	usermgmt "github.com/JWindy92/obelisk-platform/libs/user-management"
+)
+
+// fakeClock is a manually advanced clock for expiry tests.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestProvider(t *testing.T, config Config) (*Provider, *fakeClock, store.Store) {
+	t.Helper()
+
+	st := storetest.SQLite(t, Migrations(store.DialectSQLite, config))
+
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	p := NewProvider(st, config)
+	p.now = clock.Now
+	return p, clock, st
+}
+
+func TestProvider_GenerateAndValidate(t *testing.T) {
+	ctx := context.Background()
+	p, _, st := newTestProvider(t, DefaultConfig())
+
+	ctx = WithClientInfo(ctx, "test-agent/1.0", "203.0.113.7")
+	token, err := p.GenerateToken(ctx, &usermgmt.User{ID: "user-1"})
+	if err != nil {
+		t.Fatalf("GenerateToken() unexpected error: %v", err)
+	}
+
+	user, err := p.ValidateToken(ctx, token)
+	if err != nil {
+		t.Fatalf("ValidateToken() unexpected error: %v", err)
+	}
+	if user.ID != "user-1" {
+		t.Errorf("ValidateToken() user ID = %q, want %q", user.ID, "user-1")
+	}
+
+	var idHash, userAgent, ip string
+	err = st.DB().QueryRow("SELECT id_hash, user_agent, ip FROM sessions").Scan(&idHash, &userAgent, &ip)
+	if err != nil {
+		t.Fatalf("Select session failed: %v", err)
+	}
+	if idHash == token {
+		t.Error("session table stores the raw token")
+	}
+	if userAgent != "test-agent/1.0" || ip != "203.0.113.7" {
+		t.Errorf("session client info = (%q, %q)", userAgent, ip)
+	}
+
+	if _, err := p.ValidateToken(ctx, "unknown-token"); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("ValidateToken() unknown token error = %v, want ErrInvalidSession", err)
+	}
+}
+
+func TestProvider_Expiry(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    Config
+		steps     []time.Duration
+		wantValid bool
+	}{
+		{
+			name:      "fixed expiry ignores activity",
+			config:    Config{TTL: time.Hour},
+			steps:     []time.Duration{40 * time.Minute, 40 * time.Minute},
+			wantValid: false,
+		},
+		{
+			name:      "sliding expiry extends on use",
+			config:    Config{TTL: time.Hour, Sliding: true},
+			steps:     []time.Duration{40 * time.Minute, 40 * time.Minute, 40 * time.Minute},
+			wantValid: true,
+		},
+		{
+			name:      "sliding expiry stops at max lifetime",
+			config:    Config{TTL: time.Hour, Sliding: true, MaxLifetime: 90 * time.Minute},
+			steps:     []time.Duration{40 * time.Minute, 40 * time.Minute, 40 * time.Minute},
+			wantValid: false,
+		},
+		{
+			name:      "idle sliding session expires",
+			config:    Config{TTL: time.Hour, Sliding: true},
+			steps:     []time.Duration{61 * time.Minute},
+			wantValid: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			p, clock, _ := newTestProvider(t, tt.config)
+
+			token, err := p.GenerateToken(ctx, &usermgmt.User{ID: "user-1"})
+			if err != nil {
+				t.Fatalf("GenerateToken() unexpected error: %v", err)
+			}
+
+			for _, step := range tt.steps {
+				clock.Advance(step)
+				_, err = p.ValidateToken(ctx, token)
+			}
+
+			if tt.wantValid && err != nil {
+				t.Errorf("ValidateToken() unexpected error: %v", err)
+			}
+			if !tt.wantValid && !errors.Is(err, ErrInvalidSession) {
+				t.Errorf("ValidateToken() error = %v, want ErrInvalidSession", err)
+			}
+		})
+	}
+}
+
+func TestProvider_Revoke(t *testing.T) {
+	ctx := context.Background()
+	p, _, _ := newTestProvider(t, DefaultConfig())
+
+	alice := &usermgmt.User{ID: "alice"}
+	bob := &usermgmt.User{ID: "bob"}
+
+	var aliceTokens []string
+	for i := 0; i < 3; i++ {
+		token, err := p.GenerateToken(ctx, alice)
+		if err != nil {
+			t.Fatalf("GenerateToken() unexpected error: %v", err)
+		}
+		aliceTokens = append(aliceTokens, token)
+	}
+	bobToken, err := p.GenerateToken(ctx, bob)
+	if err != nil {
+		t.Fatalf("GenerateToken() unexpected error: %v", err)
+	}
+
+	if err := p.RevokeToken(ctx, aliceTokens[0]); err != nil {
+		t.Fatalf("RevokeToken() unexpected error: %v", err)
+	}
+	if _, err := p.ValidateToken(ctx, aliceTokens[0]); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("ValidateToken() after revoke error = %v, want ErrInvalidSession", err)
+	}
+	if err := p.RevokeToken(ctx, aliceTokens[0]); err != nil {
+		t.Errorf("second RevokeToken() unexpected error: %v", err)
+	}
+
+	n, err := p.RevokeAll(ctx, alice.ID)
+	if err != nil {
+		t.Fatalf("RevokeAll() unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("RevokeAll() removed %d sessions, want 2", n)
+	}
+	for _, token := range aliceTokens {
+		if _, err := p.ValidateToken(ctx, token); !errors.Is(err, ErrInvalidSession) {
+			t.Errorf("ValidateToken() after RevokeAll error = %v, want ErrInvalidSession", err)
+		}
+	}
+	if _, err := p.ValidateToken(ctx, bobToken); err != nil {
+		t.Errorf("ValidateToken() for other user unexpected error: %v", err)
+	}
+}
+
+func TestProvider_Sweep(t *testing.T) {
+	ctx := context.Background()
+	p, clock, st := newTestProvider(t, Config{TTL: time.Hour})
+
+	if _, err := p.GenerateToken(ctx, &usermgmt.User{ID: "old"}); err != nil {
+		t.Fatalf("GenerateToken() unexpected error: %v", err)
+	}
+	clock.Advance(2 * time.Hour)
+	if _, err := p.GenerateToken(ctx, &usermgmt.User{ID: "new"}); err != nil {
+		t.Fatalf("GenerateToken() unexpected error: %v", err)
+	}
+
+	n, err := p.Sweep(ctx)
+	if err != nil {
+		t.Fatalf("Sweep() unexpected error: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Sweep() removed %d sessions, want 1", n)
+	}
+
+	var remaining string
+	if err := st.DB().QueryRow("SELECT user_id FROM sessions").Scan(&remaining); err != nil {
+		t.Fatalf("Select session failed: %v", err)
+	}
+	if remaining != "new" {
+		t.Errorf("remaining session belongs to %q, want %q", remaining, "new")
+	}
+}
+
+func TestProvider_RunSweeper(t *testing.T) {
+	p, clock, st := newTestProvider(t, Config{TTL: time.Hour})
+
+	if _, err := p.GenerateToken(context.Background(), &usermgmt.User{ID: "old"}); err != nil {
+		t.Fatalf("GenerateToken() unexpected error: %v", err)
+	}
+	clock.Advance(2 * time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.RunSweeper(ctx, 10*time.Millisecond, func(err error) { t.Errorf("sweeper error: %v", err) })
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		var n int
+		if err := st.DB().QueryRow("SELECT COUNT(*) FROM sessions").Scan(&n); err != nil {
+			t.Fatalf("Count failed: %v", err)
+		}
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("RunSweeper() did not purge expired sessions")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	<-done
+}
+
+func TestProvider_RunSweeperDefaultInterval(t *testing.T) {
+	p, _, _ := newTestProvider(t, Config{TTL: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.RunSweeper(ctx, 0, nil)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("RunSweeper() with zero interval did not return after cancel")
+	}
+}